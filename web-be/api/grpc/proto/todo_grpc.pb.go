@@ -19,16 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	TodoService_ListTasks_FullMethodName     = "/todo.v1.TodoService/ListTasks"
-	TodoService_GetTask_FullMethodName       = "/todo.v1.TodoService/GetTask"
-	TodoService_CreateTask_FullMethodName    = "/todo.v1.TodoService/CreateTask"
-	TodoService_UpdateTask_FullMethodName    = "/todo.v1.TodoService/UpdateTask"
-	TodoService_DeleteTask_FullMethodName    = "/todo.v1.TodoService/DeleteTask"
-	TodoService_ListProjects_FullMethodName  = "/todo.v1.TodoService/ListProjects"
-	TodoService_GetProject_FullMethodName    = "/todo.v1.TodoService/GetProject"
-	TodoService_CreateProject_FullMethodName = "/todo.v1.TodoService/CreateProject"
-	TodoService_UpdateProject_FullMethodName = "/todo.v1.TodoService/UpdateProject"
-	TodoService_DeleteProject_FullMethodName = "/todo.v1.TodoService/DeleteProject"
+	TodoService_ListTasks_FullMethodName        = "/todo.v1.TodoService/ListTasks"
+	TodoService_GetTask_FullMethodName          = "/todo.v1.TodoService/GetTask"
+	TodoService_CreateTask_FullMethodName       = "/todo.v1.TodoService/CreateTask"
+	TodoService_UpdateTask_FullMethodName       = "/todo.v1.TodoService/UpdateTask"
+	TodoService_DeleteTask_FullMethodName       = "/todo.v1.TodoService/DeleteTask"
+	TodoService_MoveTask_FullMethodName         = "/todo.v1.TodoService/MoveTask"
+	TodoService_BatchUpdateTasks_FullMethodName = "/todo.v1.TodoService/BatchUpdateTasks"
+	TodoService_ListProjects_FullMethodName     = "/todo.v1.TodoService/ListProjects"
+	TodoService_GetProject_FullMethodName       = "/todo.v1.TodoService/GetProject"
+	TodoService_CreateProject_FullMethodName    = "/todo.v1.TodoService/CreateProject"
+	TodoService_UpdateProject_FullMethodName    = "/todo.v1.TodoService/UpdateProject"
+	TodoService_DeleteProject_FullMethodName    = "/todo.v1.TodoService/DeleteProject"
+	TodoService_ListComments_FullMethodName     = "/todo.v1.TodoService/ListComments"
+	TodoService_CreateComment_FullMethodName    = "/todo.v1.TodoService/CreateComment"
 )
 
 // TodoServiceClient is the client API for TodoService service.
@@ -40,11 +44,15 @@ type TodoServiceClient interface {
 	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*TaskMessage, error)
 	UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*TaskMessage, error)
 	DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error)
+	MoveTask(ctx context.Context, in *MoveTaskRequest, opts ...grpc.CallOption) (*TaskMessage, error)
+	BatchUpdateTasks(ctx context.Context, in *BatchUpdateTasksRequest, opts ...grpc.CallOption) (*BatchUpdateTasksResponse, error)
 	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error)
 	GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*ProjectMessage, error)
 	CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*ProjectMessage, error)
 	UpdateProject(ctx context.Context, in *UpdateProjectRequest, opts ...grpc.CallOption) (*ProjectMessage, error)
 	DeleteProject(ctx context.Context, in *DeleteProjectRequest, opts ...grpc.CallOption) (*DeleteProjectResponse, error)
+	ListComments(ctx context.Context, in *ListCommentsRequest, opts ...grpc.CallOption) (*ListCommentsResponse, error)
+	CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CommentMessage, error)
 }
 
 type todoServiceClient struct {
@@ -100,6 +108,24 @@ func (c *todoServiceClient) DeleteTask(ctx context.Context, in *DeleteTaskReques
 	return out, nil
 }
 
+func (c *todoServiceClient) MoveTask(ctx context.Context, in *MoveTaskRequest, opts ...grpc.CallOption) (*TaskMessage, error) {
+	out := new(TaskMessage)
+	err := c.cc.Invoke(ctx, TodoService_MoveTask_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) BatchUpdateTasks(ctx context.Context, in *BatchUpdateTasksRequest, opts ...grpc.CallOption) (*BatchUpdateTasksResponse, error) {
+	out := new(BatchUpdateTasksResponse)
+	err := c.cc.Invoke(ctx, TodoService_BatchUpdateTasks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *todoServiceClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error) {
 	out := new(ListProjectsResponse)
 	err := c.cc.Invoke(ctx, TodoService_ListProjects_FullMethodName, in, out, opts...)
@@ -145,6 +171,24 @@ func (c *todoServiceClient) DeleteProject(ctx context.Context, in *DeleteProject
 	return out, nil
 }
 
+func (c *todoServiceClient) ListComments(ctx context.Context, in *ListCommentsRequest, opts ...grpc.CallOption) (*ListCommentsResponse, error) {
+	out := new(ListCommentsResponse)
+	err := c.cc.Invoke(ctx, TodoService_ListComments_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *todoServiceClient) CreateComment(ctx context.Context, in *CreateCommentRequest, opts ...grpc.CallOption) (*CommentMessage, error) {
+	out := new(CommentMessage)
+	err := c.cc.Invoke(ctx, TodoService_CreateComment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TodoServiceServer is the server API for TodoService service.
 // All implementations must embed UnimplementedTodoServiceServer
 // for forward compatibility
@@ -154,11 +198,15 @@ type TodoServiceServer interface {
 	CreateTask(context.Context, *CreateTaskRequest) (*TaskMessage, error)
 	UpdateTask(context.Context, *UpdateTaskRequest) (*TaskMessage, error)
 	DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error)
+	MoveTask(context.Context, *MoveTaskRequest) (*TaskMessage, error)
+	BatchUpdateTasks(context.Context, *BatchUpdateTasksRequest) (*BatchUpdateTasksResponse, error)
 	ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error)
 	GetProject(context.Context, *GetProjectRequest) (*ProjectMessage, error)
 	CreateProject(context.Context, *CreateProjectRequest) (*ProjectMessage, error)
 	UpdateProject(context.Context, *UpdateProjectRequest) (*ProjectMessage, error)
 	DeleteProject(context.Context, *DeleteProjectRequest) (*DeleteProjectResponse, error)
+	ListComments(context.Context, *ListCommentsRequest) (*ListCommentsResponse, error)
+	CreateComment(context.Context, *CreateCommentRequest) (*CommentMessage, error)
 	mustEmbedUnimplementedTodoServiceServer()
 }
 
@@ -181,6 +229,12 @@ func (UnimplementedTodoServiceServer) UpdateTask(context.Context, *UpdateTaskReq
 func (UnimplementedTodoServiceServer) DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTask not implemented")
 }
+func (UnimplementedTodoServiceServer) MoveTask(context.Context, *MoveTaskRequest) (*TaskMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveTask not implemented")
+}
+func (UnimplementedTodoServiceServer) BatchUpdateTasks(context.Context, *BatchUpdateTasksRequest) (*BatchUpdateTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdateTasks not implemented")
+}
 func (UnimplementedTodoServiceServer) ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
 }
@@ -196,6 +250,12 @@ func (UnimplementedTodoServiceServer) UpdateProject(context.Context, *UpdateProj
 func (UnimplementedTodoServiceServer) DeleteProject(context.Context, *DeleteProjectRequest) (*DeleteProjectResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProject not implemented")
 }
+func (UnimplementedTodoServiceServer) ListComments(context.Context, *ListCommentsRequest) (*ListCommentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListComments not implemented")
+}
+func (UnimplementedTodoServiceServer) CreateComment(context.Context, *CreateCommentRequest) (*CommentMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateComment not implemented")
+}
 func (UnimplementedTodoServiceServer) mustEmbedUnimplementedTodoServiceServer() {}
 
 // UnsafeTodoServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -299,6 +359,42 @@ func _TodoService_DeleteTask_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoService_MoveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).MoveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_MoveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).MoveTask(ctx, req.(*MoveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_BatchUpdateTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).BatchUpdateTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_BatchUpdateTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).BatchUpdateTasks(ctx, req.(*BatchUpdateTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TodoService_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListProjectsRequest)
 	if err := dec(in); err != nil {
@@ -389,6 +485,42 @@ func _TodoService_DeleteProject_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TodoService_ListComments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCommentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).ListComments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_ListComments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).ListComments(ctx, req.(*ListCommentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TodoService_CreateComment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCommentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TodoServiceServer).CreateComment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TodoService_CreateComment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TodoServiceServer).CreateComment(ctx, req.(*CreateCommentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TodoService_ServiceDesc is the grpc.ServiceDesc for TodoService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -416,6 +548,14 @@ var TodoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTask",
 			Handler:    _TodoService_DeleteTask_Handler,
 		},
+		{
+			MethodName: "MoveTask",
+			Handler:    _TodoService_MoveTask_Handler,
+		},
+		{
+			MethodName: "BatchUpdateTasks",
+			Handler:    _TodoService_BatchUpdateTasks_Handler,
+		},
 		{
 			MethodName: "ListProjects",
 			Handler:    _TodoService_ListProjects_Handler,
@@ -436,6 +576,14 @@ var TodoService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProject",
 			Handler:    _TodoService_DeleteProject_Handler,
 		},
+		{
+			MethodName: "ListComments",
+			Handler:    _TodoService_ListComments_Handler,
+		},
+		{
+			MethodName: "CreateComment",
+			Handler:    _TodoService_CreateComment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "todo.proto",
@@ -2,38 +2,63 @@ package grpc
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"slices"
+	"strconv"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	gproto "google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
 
 	"github.com/todo-tracking-app/web-be/api/grpc/proto"
 	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
 	"github.com/google/uuid"
 )
 
+// maxRequestIDLen is the longest request_id accepted on creates.
+const maxRequestIDLen = 200
+
+// Limits of BatchUpdateTasks, as for POST /tasks/batch.
+const (
+	maxBatchOperations = 50
+	maxBatchItems      = 500
+)
+
+// errBatchRejected aborts an atomic batch when an item failed.
+var errBatchRejected = errors.New("batch rejected")
+
 // Server implements proto.TodoServiceServer.
 type Server struct {
 	proto.UnimplementedTodoServiceServer
-	db *gorm.DB
+	db        *gorm.DB
+	statuses  *service.TaskStatusMachine
+	revisions *service.TaskRevisions
+	keys      *service.IdempotencyKeys
 }
 
 // NewServer creates a new gRPC server.
-func NewServer(db *gorm.DB) *Server {
-	return &Server{db: db}
+func NewServer(db *gorm.DB, statuses *service.TaskStatusMachine, revisions *service.TaskRevisions, keys *service.IdempotencyKeys) *Server {
+	return &Server{db: db, statuses: statuses, revisions: revisions, keys: keys}
 }
 
 // Serve starts the gRPC server on the given address.
-func Serve(db *gorm.DB, addr string) error {
+func Serve(db *gorm.DB, statuses *service.TaskStatusMachine, revisions *service.TaskRevisions, keys *service.IdempotencyKeys, addr string) error {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		return err
 	}
 	s := grpc.NewServer()
-	proto.RegisterTodoServiceServer(s, NewServer(db))
+	proto.RegisterTodoServiceServer(s, NewServer(db, statuses, revisions, keys))
 	reflection.Register(s)
 	log.Printf("gRPC server listening on %s", addr)
 	return s.Serve(lis)
@@ -64,14 +89,34 @@ func (s *Server) GetTask(ctx context.Context, req *proto.GetTaskRequest) (*proto
 }
 
 func (s *Server) CreateTask(ctx context.Context, req *proto.CreateTaskRequest) (*proto.TaskMessage, error) {
+	return idempotent(ctx, s.keys, req.UserId, req.RequestId, req, func() (*proto.TaskMessage, error) {
+		return s.createTask(req)
+	})
+}
+
+func (s *Server) createTask(req *proto.CreateTaskRequest) (*proto.TaskMessage, error) {
+	projectID, err := service.ResolveTaskProject(s.db, req.UserId, req.ProjectId)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	sortKey, err := service.TaskSortKey(s.db, "", projectID, nil, "", "")
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	recurrence, err := service.NormalizeRecurrence(req.Recurrence)
+	if err != nil {
+		return nil, serviceError(err)
+	}
 	task := model.Task{
 		ID:        uuid.New().String(),
 		Title:     req.Title,
 		Description: req.Description,
-		ProjectID:  req.ProjectId,
+		ProjectID:  projectID,
+		SortKey:    sortKey,
 		UserID:     req.UserId,
 		Priority:   int(req.Priority),
 		Status:     model.TaskStatusPending,
+		Recurrence: recurrence,
 	}
 	if req.DueDate != "" {
 		if t, err := time.Parse(time.RFC3339, req.DueDate); err == nil {
@@ -83,9 +128,19 @@ func (s *Server) CreateTask(ctx context.Context, req *proto.CreateTaskRequest) (
 			task.ReminderAt = &t
 		}
 	}
-	if err := s.db.Create(&task).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if err := service.SyncTaskReminder(tx, nil, &task); err != nil {
+			return err
+		}
+		return service.RecordCreate(tx, req.UserId, &task)
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.db.First(&task, "id = ?", task.ID)
 	return taskToProto(&task), nil
 }
 
@@ -94,21 +149,186 @@ func (s *Server) UpdateTask(ctx context.Context, req *proto.UpdateTaskRequest) (
 	if err := s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).First(&task).Error; err != nil {
 		return nil, err
 	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.update(tx, req.UserId, &task, req, nil)
+	})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	s.db.First(&task, "id = ?", task.ID)
+	return taskToProto(&task), nil
+}
+
+func (s *Server) MoveTask(ctx context.Context, req *proto.MoveTaskRequest) (*proto.TaskMessage, error) {
+	var task model.Task
+	if err := s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).First(&task).Error; err != nil {
+		return nil, status.Error(codes.NotFound, "task not found")
+	}
+	before := task
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.revisions.Record(tx, req.UserId, &before); err != nil {
+			return err
+		}
+		if err := service.MoveTask(tx, &task, req.UserId, req.ProjectId); err != nil {
+			return err
+		}
+		return service.RecordUpdate(tx, req.UserId, &before, &task)
+	})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return taskToProto(&task), nil
+}
+
+// BatchUpdateTasks applies operations to many tasks in one transaction, like
+// POST /tasks/batch. Unless per_item is set nothing is applied when any item
+// fails.
+func (s *Server) BatchUpdateTasks(ctx context.Context, req *proto.BatchUpdateTasksRequest) (*proto.BatchUpdateTasksResponse, error) {
+	if len(req.Operations) == 0 || len(req.Operations) > maxBatchOperations {
+		return nil, status.Errorf(codes.InvalidArgument, "a batch needs 1 to %d operations", maxBatchOperations)
+	}
+	items := 0
+	for i, op := range req.Operations {
+		if err := validateBatchOperation(op); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "operations[%d]: %v", i, err)
+		}
+		items += len(op.TaskIds)
+	}
+	if items > maxBatchItems {
+		return nil, status.Errorf(codes.InvalidArgument, "a batch may touch at most %d tasks", maxBatchItems)
+	}
+
+	results := make([]*proto.BatchTaskResult, 0, items)
+	failed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, op := range req.Operations {
+			for _, id := range op.TaskIds {
+				res := &proto.BatchTaskResult{Operation: int32(i), TaskId: id, Status: "applied"}
+				if err := tx.Transaction(func(tx *gorm.DB) error { return s.applyBatch(tx, req.UserId, op, id) }); err != nil {
+					if _, ok := status.FromError(serviceError(err)); !ok {
+						return err
+					}
+					failed = true
+					res.Status = "failed"
+					res.Error = err.Error()
+				}
+				results = append(results, res)
+			}
+		}
+		if failed && !req.PerItem {
+			return errBatchRejected
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRejected) {
+		return nil, err
+	}
+	if failed && !req.PerItem {
+		for _, r := range results {
+			if r.Status == "applied" {
+				r.Status = "rolled_back"
+			}
+		}
+		return &proto.BatchUpdateTasksResponse{Results: results}, nil
+	}
+
+	var ids []string
+	for _, r := range results {
+		if r.Status == "applied" && !slices.Contains(ids, r.TaskId) {
+			ids = append(ids, r.TaskId)
+		}
+	}
+	var tasks []model.Task
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ? AND user_id = ?", ids, req.UserId).Find(&tasks).Error; err != nil {
+			return nil, err
+		}
+	}
+	out := make([]*proto.TaskMessage, len(tasks))
+	for i := range tasks {
+		out[i] = taskToProto(&tasks[i])
+	}
+	return &proto.BatchUpdateTasksResponse{Applied: !failed, Results: results, Tasks: out}, nil
+}
+
+// validateBatchOperation checks that op is known and carries what it needs.
+func validateBatchOperation(op *proto.BatchTaskOperation) error {
+	if len(op.TaskIds) == 0 {
+		return errors.New("task_ids is required")
+	}
+	switch op.Op {
+	case "update":
+		if op.Data == nil {
+			return errors.New("update needs data")
+		}
+	case "add_labels", "remove_labels":
+		if len(op.LabelIds) == 0 {
+			return fmt.Errorf("%s needs label_ids", op.Op)
+		}
+	case "complete", "delete", "move":
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return nil
+}
+
+// applyBatch applies op to task id in tx with the same rules as the single
+// task RPCs.
+func (s *Server) applyBatch(tx *gorm.DB, userID string, op *proto.BatchTaskOperation, id string) error {
+	var task model.Task
+	if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return service.ErrTaskNotFound
+		}
+		return err
+	}
+	req := &proto.UpdateTaskRequest{Force: op.Force}
+	var labelIDs []string
+	switch op.Op {
+	case "delete":
+		return service.DeleteLogged(tx, userID, &task)
+	case "update":
+		req = gproto.Clone(op.Data).(*proto.UpdateTaskRequest)
+		req.Force = req.Force || op.Force
+	case "complete":
+		completed := model.TaskStatusCompleted
+		req.Status = &completed
+	case "move":
+		req.ProjectId = &op.ProjectId
+	case "add_labels", "remove_labels":
+		current, err := service.TaskLabelIDs(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if op.Op == "add_labels" {
+			for _, l := range op.LabelIds {
+				if !slices.Contains(current, l) {
+					current = append(current, l)
+				}
+			}
+		} else {
+			current = slices.DeleteFunc(current, func(l string) bool { return slices.Contains(op.LabelIds, l) })
+		}
+		labelIDs = append([]string{}, current...)
+	}
+	return s.update(tx, userID, &task, req, labelIDs)
+}
+
+// update applies req, and labelIDs unless nil, to task in tx.
+func (s *Server) update(tx *gorm.DB, userID string, task *model.Task, req *proto.UpdateTaskRequest, labelIDs []string) error {
+	if err := service.CheckVersion(tx, task, expectedVersion(req.ExpectedVersion)); err != nil {
+		return err
+	}
+	before := *task
 	if req.Title != nil {
 		task.Title = *req.Title
 	}
 	if req.Description != nil {
 		task.Description = *req.Description
 	}
-	if req.ProjectId != nil {
-		task.ProjectID = *req.ProjectId
-	}
 	if req.Priority != nil {
 		task.Priority = int(*req.Priority)
 	}
-	if req.Status != nil {
-		task.Status = *req.Status
-	}
 	if req.Progress != nil {
 		task.Progress = int(*req.Progress)
 	}
@@ -122,20 +342,65 @@ func (s *Server) UpdateTask(ctx context.Context, req *proto.UpdateTaskRequest) (
 			task.ReminderAt = &t
 		}
 	}
-	if err := s.db.Save(&task).Error; err != nil {
-		return nil, err
+	if req.Recurrence != nil {
+		recurrence, err := service.NormalizeRecurrence(*req.Recurrence)
+		if err != nil {
+			return err
+		}
+		task.Recurrence = recurrence
 	}
-	return taskToProto(&task), nil
+	var labels []model.Label
+	if labelIDs != nil {
+		var err error
+		if labels, err = service.ResolveLabels(tx, task.UserID, labelIDs); err != nil {
+			return err
+		}
+	}
+	if err := s.revisions.Record(tx, userID, &before); err != nil {
+		return err
+	}
+	if req.ProjectId != nil && *req.ProjectId != task.ProjectID {
+		if err := service.MoveTask(tx, task, userID, *req.ProjectId); err != nil {
+			return err
+		}
+	}
+	to := task.Status
+	if req.Status != nil {
+		to = *req.Status
+	}
+	if err := s.statuses.Apply(tx, task, to, req.Force, time.Now()); err != nil {
+		return err
+	}
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+	if labelIDs != nil {
+		if err := service.SetTaskLabels(tx, task.ID, labels); err != nil {
+			return err
+		}
+	}
+	if err := service.SyncTaskReminder(tx, &before, task); err != nil {
+		return err
+	}
+	return service.RecordUpdate(tx, userID, &before, task)
 }
 
 func (s *Server) DeleteTask(ctx context.Context, req *proto.DeleteTaskRequest) (*proto.DeleteTaskResponse, error) {
-	s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).Delete(&model.Task{})
+	var task model.Task
+	if err := s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).First(&task).Error; err == nil {
+		if err := service.DeleteLogged(s.db, req.UserId, &task); err != nil {
+			return nil, err
+		}
+	}
 	return &proto.DeleteTaskResponse{}, nil
 }
 
 func (s *Server) ListProjects(ctx context.Context, req *proto.ListProjectsRequest) (*proto.ListProjectsResponse, error) {
+	if _, err := service.EnsureInbox(s.db, req.UserId); err != nil {
+		return nil, err
+	}
 	var projects []model.Project
-	if err := s.db.Where("user_id = ?", req.UserId).Find(&projects).Error; err != nil {
+	if err := s.db.Where("user_id = ?", req.UserId).Order("is_inbox DESC, created_at").Find(&projects).Error; err != nil {
 		return nil, err
 	}
 	out := make([]*proto.ProjectMessage, len(projects))
@@ -154,15 +419,22 @@ func (s *Server) GetProject(ctx context.Context, req *proto.GetProjectRequest) (
 }
 
 func (s *Server) CreateProject(ctx context.Context, req *proto.CreateProjectRequest) (*proto.ProjectMessage, error) {
+	return idempotent(ctx, s.keys, req.UserId, req.RequestId, req, func() (*proto.ProjectMessage, error) {
+		return s.createProject(req)
+	})
+}
+
+func (s *Server) createProject(req *proto.CreateProjectRequest) (*proto.ProjectMessage, error) {
 	proj := model.Project{
 		ID:     uuid.New().String(),
 		Name:   req.Name,
 		Color:  req.Color,
 		UserID: req.UserId,
 	}
-	if err := s.db.Create(&proj).Error; err != nil {
+	if err := service.CreateLogged(s.db, req.UserId, &proj); err != nil {
 		return nil, err
 	}
+	s.db.First(&proj, "id = ?", proj.ID)
 	return projectToProto(&proj), nil
 }
 
@@ -171,30 +443,170 @@ func (s *Server) UpdateProject(ctx context.Context, req *proto.UpdateProjectRequ
 	if err := s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).First(&proj).Error; err != nil {
 		return nil, err
 	}
-	if req.Name != nil {
-		proj.Name = *req.Name
-	}
-	if req.Color != nil {
-		proj.Color = *req.Color
-	}
-	if err := s.db.Save(&proj).Error; err != nil {
-		return nil, err
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &proj, expectedVersion(req.ExpectedVersion)); err != nil {
+			return err
+		}
+		before := proj
+		if req.Name != nil {
+			proj.Name = *req.Name
+		}
+		if req.Color != nil {
+			proj.Color = *req.Color
+		}
+		return service.SaveLogged(tx, req.UserId, &before, &proj)
+	})
+	if err != nil {
+		return nil, serviceError(err)
 	}
+	s.db.First(&proj, "id = ?", proj.ID)
 	return projectToProto(&proj), nil
 }
 
 func (s *Server) DeleteProject(ctx context.Context, req *proto.DeleteProjectRequest) (*proto.DeleteProjectResponse, error) {
-	s.db.Where("id = ? AND user_id = ?", req.Id, req.UserId).Delete(&model.Project{})
+	var proj model.Project
+	if err := s.db.Where("id = ? AND user_id = ? AND is_inbox = ?", req.Id, req.UserId, false).First(&proj).Error; err == nil {
+		if err := service.DeleteLogged(s.db, req.UserId, &proj); err != nil {
+			return nil, err
+		}
+	}
 	return &proto.DeleteProjectResponse{}, nil
 }
 
+func (s *Server) ListComments(ctx context.Context, req *proto.ListCommentsRequest) (*proto.ListCommentsResponse, error) {
+	q, err := s.commentScope(req.UserId, req.TaskId, req.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, err := pageBounds(req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	q = q.Session(&gorm.Session{})
+	var total int64
+	if err := q.Model(&model.Comment{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	var comments []model.Comment
+	if err := q.Preload("Mentions").Order("created_at, id").Limit(limit).Offset(offset).
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*proto.CommentMessage, len(comments))
+	for i, c := range comments {
+		out[i] = commentToProto(&c)
+	}
+	resp := &proto.ListCommentsResponse{Comments: out, TotalCount: total}
+	if next := offset + len(comments); int64(next) < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+	return resp, nil
+}
+
+func (s *Server) CreateComment(ctx context.Context, req *proto.CreateCommentRequest) (*proto.CommentMessage, error) {
+	return idempotent(ctx, s.keys, req.UserId, req.RequestId, req, func() (*proto.CommentMessage, error) {
+		return s.createComment(req)
+	})
+}
+
+func (s *Server) createComment(req *proto.CreateCommentRequest) (*proto.CommentMessage, error) {
+	if _, err := s.commentScope(req.UserId, req.TaskId, req.ProjectId); err != nil {
+		return nil, err
+	}
+	if req.Body == "" {
+		return nil, status.Error(codes.InvalidArgument, "body is required")
+	}
+	comment := model.Comment{ID: uuid.New().String(), UserID: req.UserId, Body: req.Body}
+	if req.TaskId != "" {
+		comment.TaskID = &req.TaskId
+	} else {
+		comment.ProjectID = &req.ProjectId
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.SaveComment(tx, &comment, true); err != nil {
+			return err
+		}
+		return service.RecordCreate(tx, req.UserId, &comment)
+	})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return commentToProto(&comment), nil
+}
+
+// commentScope checks access to the task or project a comment request targets
+// and returns a query selecting its comments.
+func (s *Server) commentScope(userID, taskID, projectID string) (*gorm.DB, error) {
+	switch {
+	case taskID != "" && projectID != "":
+		return nil, status.Error(codes.InvalidArgument, "set only one of task_id and project_id")
+	case taskID != "":
+		task, err := service.CheckTaskAccess(s.db, userID, taskID)
+		if err != nil {
+			return nil, serviceError(err)
+		}
+		return s.db.Where("task_id = ?", task.ID), nil
+	case projectID != "":
+		if err := service.CheckProjectAccess(s.db, userID, projectID); err != nil {
+			return nil, serviceError(err)
+		}
+		return s.db.Where("project_id = ? AND task_id IS NULL", projectID), nil
+	}
+	return nil, status.Error(codes.InvalidArgument, "task_id or project_id is required")
+}
+
+// pageBounds turns a page size and token into a limit and offset. The token
+// is the offset of the next page as returned in next_page_token.
+func pageBounds(size int32, token string) (limit, offset int, err error) {
+	limit = 50
+	if size > 0 {
+		limit = int(size)
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	if token != "" {
+		offset, err = strconv.Atoi(token)
+		if err != nil || offset < 0 {
+			return 0, 0, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+	return limit, offset, nil
+}
+
+func commentToProto(c *model.Comment) *proto.CommentMessage {
+	m := &proto.CommentMessage{
+		Id:        c.ID,
+		UserId:    c.UserID,
+		Body:      c.Body,
+		Mentions:  make([]string, 0, len(c.Mentions)),
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+	}
+	if c.TaskID != nil {
+		m.TaskId = *c.TaskID
+	}
+	if c.ProjectID != nil {
+		m.ProjectId = *c.ProjectID
+	}
+	if c.EditedAt != nil {
+		m.EditedAt = c.EditedAt.Format(time.RFC3339)
+	}
+	for _, mention := range c.Mentions {
+		m.Mentions = append(m.Mentions, mention.UserID)
+	}
+	return m
+}
+
 func taskToProto(t *model.Task) *proto.TaskMessage {
 	m := &proto.TaskMessage{
 		Id:          t.ID,
 		Title:       t.Title,
 		Description: t.Description,
 		ProjectId:   t.ProjectID,
+		SortKey:     t.SortKey,
 		UserId:      t.UserID,
+		Version:     int32(t.Version),
+		Recurrence:  t.Recurrence,
 		Priority:     int32(t.Priority),
 		Status:      t.Status,
 		Progress:    int32(t.Progress),
@@ -207,6 +619,15 @@ func taskToProto(t *model.Task) *proto.TaskMessage {
 	if t.ReminderAt != nil {
 		m.ReminderAt = t.ReminderAt.Format(time.RFC3339)
 	}
+	if t.SectionID != nil {
+		m.SectionId = *t.SectionID
+	}
+	if t.StartedAt != nil {
+		m.StartedAt = t.StartedAt.Format(time.RFC3339)
+	}
+	if t.CompletedAt != nil {
+		m.CompletedAt = t.CompletedAt.Format(time.RFC3339)
+	}
 	return m
 }
 
@@ -217,5 +638,101 @@ func projectToProto(p *model.Project) *proto.ProjectMessage {
 		Color:     p.Color,
 		UserId:    p.UserID,
 		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		IsInbox:   p.IsInbox,
+		Version:   int32(p.Version),
 	}
 }
+
+// idempotent runs create once per user and request ID. A retry of the same
+// request gets the stored response; reusing the ID for a different request
+// fails. Without a request ID, create just runs. Failures are not stored.
+func idempotent[T gproto.Message](ctx context.Context, keys *service.IdempotencyKeys, userID, requestID string,
+	req gproto.Message, create func() (T, error)) (T, error) {
+	var zero T
+	if requestID == "" {
+		return create()
+	}
+	if len(requestID) > maxRequestIDLen {
+		return zero, status.Error(codes.InvalidArgument, "request_id is too long")
+	}
+	raw, err := gproto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return zero, err
+	}
+	h := sha256.New()
+	h.Write([]byte(req.ProtoReflect().Descriptor().FullName() + "\n"))
+	h.Write(raw)
+	key := "grpc:" + requestID
+	ctx = context.WithoutCancel(ctx)
+	rec, err := keys.Begin(ctx, userID, key, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return zero, serviceError(err)
+	}
+	if rec != nil {
+		resp := zero.ProtoReflect().New().Interface().(T)
+		if err := gproto.Unmarshal(rec.Body, resp); err != nil {
+			return zero, err
+		}
+		return resp, nil
+	}
+	resp, err := create()
+	if err != nil {
+		if err := keys.Release(ctx, userID, key); err != nil {
+			log.Printf("grpc: release request_id %s: %v", requestID, err)
+		}
+		return zero, err
+	}
+	body, err := gproto.Marshal(resp)
+	if err == nil {
+		err = keys.Complete(ctx, userID, key, int(codes.OK), nil, body)
+	}
+	if err != nil {
+		log.Printf("grpc: store request_id %s: %v", requestID, err)
+	}
+	return resp, nil
+}
+
+// expectedVersion returns the versions an update may apply to, for
+// service.CheckVersion; 0 skips the check.
+func expectedVersion(v int32) []int {
+	if v == 0 {
+		return nil
+	}
+	return []int{int(v)}
+}
+
+// serviceError maps service-layer errors onto gRPC status codes,
+// mirroring writeServiceError in the REST package.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrLabelNotFound),
+		errors.Is(err, service.ErrSectionNotFound), errors.Is(err, service.ErrTaskNotFound),
+		errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrRevisionNotFound), errors.Is(err, service.ErrReminderNotFound),
+		errors.Is(err, service.ErrNotificationNotFound), errors.Is(err, service.ErrAssignmentNotFound),
+		errors.Is(err, service.ErrDeviceNotFound), errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrWebhookDeliveryNotFound), errors.Is(err, service.ErrSubtaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrProjectForbidden), errors.Is(err, service.ErrTaskForbidden),
+		errors.Is(err, service.ErrNotCommentAuthor):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrInvalidPosition),
+		errors.Is(err, service.ErrInvalidStatusKey), errors.Is(err, service.ErrInvalidNotificationType),
+		errors.Is(err, service.ErrInvalidAssignee), errors.Is(err, service.ErrInvalidPlatform),
+		errors.Is(err, service.ErrInvalidWebhook), errors.Is(err, service.ErrInvalidSyncToken),
+		errors.Is(err, service.ErrInvalidMutation), errors.Is(err, service.ErrIdempotencyKeyReused),
+		errors.Is(err, service.ErrInvalidSearch), errors.Is(err, service.ErrInvalidRecurrence),
+		errors.Is(err, service.ErrInvalidQuickAdd):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrStatusTransition), errors.Is(err, service.ErrWIPLimit),
+		errors.Is(err, service.ErrDependencyCycle), errors.Is(err, service.ErrTaskBlocked),
+		errors.Is(err, service.ErrReminderFromTask), errors.Is(err, service.ErrWebhookDisabled),
+		errors.Is(err, service.ErrIDConflict), errors.Is(err, service.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrMergeConflict), errors.Is(err, service.ErrIdempotencyKeyInFlight):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrAttachmentTooLarge), errors.Is(err, service.ErrAttachmentQuota):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterActivityRoutes registers the activity feeds of tasks and projects.
+func RegisterActivityRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &activityHandler{db: db}
+	r.GET("/tasks/:id/activity", h.ListTask)
+	r.GET("/projects/:id/activity", h.ListProject)
+}
+
+type activityHandler struct {
+	db *gorm.DB
+}
+
+func (h *activityHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// ListTask returns the activity of a task, including its comments,
+// attachments and dependencies, newest first.
+// @Summary List task activity
+// @Tags activity
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.ActivityVO
+// @Header 200 {integer} X-Total-Count "Total number of entries"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/activity [get]
+func (h *activityHandler) ListTask(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.list(c, h.db.Where("task_id = ?", task.ID))
+}
+
+// ListProject returns the activity of a project and everything that was in
+// it at the time, newest first.
+// @Summary List project activity
+// @Tags activity
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.ActivityVO
+// @Header 200 {integer} X-Total-Count "Total number of entries"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/activity [get]
+func (h *activityHandler) ListProject(c *gin.Context) {
+	projectID := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), projectID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.list(c, h.db.Where("project_id = ?", projectID))
+}
+
+func (h *activityHandler) list(c *gin.Context, q *gorm.DB) {
+	q = q.Session(&gorm.Session{})
+	limit, offset := parsePage(c)
+	var total int64
+	if err := q.Model(&model.Activity{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var entries []model.Activity
+	if err := q.Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.ActivityVO, 0, len(entries))
+	for _, a := range entries {
+		vos = append(vos, dto.ActivityVO{
+			ID:         a.ID,
+			ActorID:    a.ActorID,
+			EntityType: a.EntityType,
+			EntityID:   a.EntityID,
+			TaskID:     a.TaskID,
+			ProjectID:  a.ProjectID,
+			Action:     a.Action,
+			Changes:    json.RawMessage(a.Changes),
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, vos)
+}
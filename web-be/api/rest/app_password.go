@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterAppPasswordRoutes registers the caller's app password routes.
+func RegisterAppPasswordRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &appPasswordHandler{db: db}
+	group := r.Group("/me/app-passwords")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Create)
+		group.DELETE("/:id", h.Revoke)
+	}
+}
+
+type appPasswordHandler struct {
+	db *gorm.DB
+}
+
+func (h *appPasswordHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns the caller's app passwords, without the passwords.
+// @Summary List app passwords
+// @Tags app-passwords
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.AppPasswordVO
+// @Router /me/app-passwords [get]
+func (h *appPasswordHandler) List(c *gin.Context) {
+	var passwords []model.AppPassword
+	if err := h.db.Where("user_id = ?", h.getUserID(c)).Order("created_at").Find(&passwords).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.AppPasswordVO, 0, len(passwords))
+	_ = copier.Copy(&vos, &passwords)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Create generates an app password for signing in to the CalDAV server at
+// /dav with HTTP Basic auth, and returns it with the username to use. The
+// password is only shown in this response.
+// @Summary Create app password
+// @Tags app-passwords
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.AppPasswordCreateRequest true "App password"
+// @Success 201 {object} dto.AppPasswordCreatedVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "Too many app passwords"
+// @Router /me/app-passwords [post]
+func (h *appPasswordHandler) Create(c *gin.Context) {
+	var req dto.AppPasswordCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	password, ap, err := service.CreateAppPassword(h.db, userID, req.Name)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	vo := dto.AppPasswordCreatedVO{Username: service.AppPasswordUsername(h.db, userID), Password: password}
+	_ = copier.Copy(&vo.AppPasswordVO, ap)
+	c.JSON(http.StatusCreated, vo)
+}
+
+// Revoke deletes an app password of the caller; apps using it are signed out.
+// @Summary Revoke app password
+// @Tags app-passwords
+// @Security BearerAuth
+// @Param id path string true "App password ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /me/app-passwords/{id} [delete]
+func (h *appPasswordHandler) Revoke(c *gin.Context) {
+	if err := service.RevokeAppPassword(h.db, h.getUserID(c), c.Param("id")); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterAssignmentRoutes registers task assignee routes.
+func RegisterAssignmentRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &assignmentHandler{db: db}
+	group := r.Group("/tasks/:id/assignees")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Assign)
+		group.DELETE("/:userId", h.Unassign)
+	}
+}
+
+type assignmentHandler struct {
+	db *gorm.DB
+}
+
+func (h *assignmentHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns who a task is assigned to, earliest first.
+// @Summary List task assignees
+// @Tags assignments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {array} dto.TaskAssignmentVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/assignees [get]
+func (h *assignmentHandler) List(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var assignments []model.TaskAssignment
+	if err := h.db.Where("task_id = ?", task.ID).Order("created_at").Find(&assignments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.TaskAssignmentVO, 0, len(assignments))
+	_ = copier.Copy(&vos, &assignments)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Assign assigns a task to its owner or a member of its project, who is notified.
+// @Summary Assign task
+// @Tags assignments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.TaskAssignRequest true "Assignee"
+// @Success 201 {object} dto.TaskAssignmentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/assignees [post]
+func (h *assignmentHandler) Assign(c *gin.Context) {
+	var req dto.TaskAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	a, err := service.AssignTask(h.db, userID, task, req.UserID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var vo dto.TaskAssignmentVO
+	_ = copier.Copy(&vo, a)
+	c.JSON(http.StatusCreated, vo)
+}
+
+// Unassign removes a user from a task.
+// @Summary Unassign task
+// @Tags assignments
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param userId path string true "Assignee user ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/assignees/{userId} [delete]
+func (h *assignmentHandler) Unassign(c *gin.Context) {
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.UnassignTask(h.db, userID, task, c.Param("userId")); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// multipartOverhead allows for multipart boundaries and headers on top of
+// the file itself when capping the request body.
+const multipartOverhead = 1 << 20
+
+// RegisterAttachmentRoutes registers attachment routes nested under a task.
+func RegisterAttachmentRoutes(r *gin.RouterGroup, db *gorm.DB, attachments *service.Attachments) {
+	h := &attachmentHandler{db: db, attachments: attachments}
+	r.GET("/attachments/usage", h.Usage)
+	group := r.Group("/tasks/:id/attachments")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Upload)
+		group.GET("/:attachmentId", h.Download)
+		group.DELETE("/:attachmentId", h.Delete)
+	}
+}
+
+type attachmentHandler struct {
+	db          *gorm.DB
+	attachments *service.Attachments
+}
+
+func (h *attachmentHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns the attachments of a task, oldest first.
+// @Summary List attachments
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param comment_id query string false "Only attachments of this comment"
+// @Success 200 {array} dto.AttachmentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments [get]
+func (h *attachmentHandler) List(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	q := h.db.Where("task_id = ?", task.ID)
+	if commentID := c.Query("comment_id"); commentID != "" {
+		if _, err := uuid.Parse(commentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment_id"})
+			return
+		}
+		q = q.Where("comment_id = ?", commentID)
+	}
+	var atts []model.Attachment
+	if err := q.Order("created_at, id").Find(&atts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.AttachmentVO, 0, len(atts))
+	_ = copier.Copy(&vos, &atts)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Upload attaches a file to a task. The content type is sniffed from the file.
+// @Summary Upload attachment
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param file formData file true "File to attach"
+// @Param comment_id formData string false "Comment on the task to attach the file to"
+// @Success 201 {object} dto.AttachmentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string "No access to the task, or storage quota exceeded"
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /tasks/{id}/attachments [post]
+func (h *attachmentHandler) Upload(c *gin.Context) {
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachments.MaxFileBytes()+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeServiceError(c, service.ErrAttachmentTooLarge)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var commentID *string
+	if id := c.PostForm("comment_id"); id != "" {
+		commentID = &id
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	att, err := h.attachments.Upload(c.Request.Context(), h.db, userID, task, commentID, fh.Filename, f, fh.Size)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var vo dto.AttachmentVO
+	_ = copier.Copy(&vo, att)
+	c.JSON(http.StatusCreated, vo)
+}
+
+// Download streams an attachment. Images and PDFs are served inline, anything
+// else as a download.
+// @Summary Download attachment
+// @Tags attachments
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments/{attachmentId} [get]
+func (h *attachmentHandler) Download(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	att, err := service.FindAttachment(h.db, task.ID, c.Param("attachmentId"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	rc, err := h.attachments.Open(c.Request.Context(), att)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	defer rc.Close()
+	disposition := "attachment"
+	if strings.HasPrefix(att.ContentType, "image/") || att.ContentType == "application/pdf" {
+		disposition = "inline"
+	}
+	c.Header("Content-Type", att.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(att.Size, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": att.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, rc)
+}
+
+// Delete removes an attachment. The uploader and the task owner may delete.
+// @Summary Delete attachment
+// @Tags attachments
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/attachments/{attachmentId} [delete]
+func (h *attachmentHandler) Delete(c *gin.Context) {
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	att, err := service.FindAttachment(h.db, task.ID, c.Param("attachmentId"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if att.UserID != userID && task.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the uploader or task owner can delete an attachment"})
+		return
+	}
+	if err := h.attachments.Delete(h.db, userID, att); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Usage returns the caller's attachment storage use and limits.
+// @Summary Attachment storage usage
+// @Tags attachments
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AttachmentUsageVO
+// @Router /attachments/usage [get]
+func (h *attachmentHandler) Usage(c *gin.Context) {
+	userID := h.getUserID(c)
+	var user model.User
+	if err := h.db.Where("id = ?", userID).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	used, err := service.Usage(h.db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.AttachmentUsageVO{
+		UsedBytes:    used,
+		QuotaBytes:   h.attachments.Quota(user),
+		MaxFileBytes: h.attachments.MaxFileBytes(),
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/todo-tracking-app/web-be/internal/dto"
 	"github.com/todo-tracking-app/web-be/internal/model"
 	"github.com/todo-tracking-app/web-be/internal/middleware"
+	"github.com/todo-tracking-app/web-be/internal/service"
 )
 
 // RegisterAuthRoutes registers auth routes.
@@ -57,7 +58,14 @@ func (h *authHandler) Register(c *gin.Context) {
 		Email:    req.Email,
 		Password: hashPassword(req.Password), // TODO: use bcrypt
 	}
-	if err := h.db.Create(&user).Error; err != nil {
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		_, err := service.EnsureInbox(tx, user.ID)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
 		return
 	}
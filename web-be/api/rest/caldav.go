@@ -0,0 +1,733 @@
+package rest
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/ical"
+	"github.com/todo-tracking-app/web-be/internal/middleware"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+const (
+	// calDAVRealm is the Basic auth realm clients show when asking for an
+	// app password.
+	calDAVRealm = "Tasks"
+	// calDAVMethods are the methods allowed on DAV resources.
+	calDAVMethods = "OPTIONS, PROPFIND, PROPPATCH, REPORT, GET, HEAD, PUT, DELETE"
+	// calDAVSyncTokenPrefix starts sync tokens, which must be URIs.
+	calDAVSyncTokenPrefix = "http://todo-tracking-app/ns/sync/"
+	// calDAVContentType is the type of calendar object resources.
+	calDAVContentType = "text/calendar; charset=utf-8; component=VTODO"
+	// maxDAVRequest caps request bodies: XML requests and calendar objects.
+	maxDAVRequest = 1 << 20
+)
+
+// RegisterCalDAVRoutes registers the CalDAV (RFC 4791) server under /dav,
+// authenticated with app passwords, and the /.well-known/caldav redirect
+// clients discover it by. Each project the caller can see is a calendar
+// collection of their tasks in it, as VTODOs; subtasks are VTODOs related to
+// their task's.
+func RegisterCalDAVRoutes(r *gin.RouterGroup, db *gorm.DB, statuses *service.TaskStatusMachine, revisions *service.TaskRevisions) {
+	h := &calDAVHandler{
+		taskHandler: taskHandler{db: db, statuses: statuses, revisions: revisions},
+		root:        path.Join(r.BasePath(), "/dav") + "/",
+	}
+	r.GET("/.well-known/caldav", h.WellKnown)
+	r.Handle("PROPFIND", "/.well-known/caldav", h.WellKnown)
+	dav := r.Group("/dav", middleware.AppPasswordAuth(db, calDAVRealm))
+	{
+		dav.OPTIONS("/*path", h.Options)
+		dav.Handle("PROPFIND", "/*path", h.Propfind)
+		dav.Handle("PROPPATCH", "/*path", h.Proppatch)
+		dav.Handle("REPORT", "/*path", h.Report)
+		dav.GET("/*path", h.Get)
+		dav.HEAD("/*path", h.Get)
+		dav.PUT("/*path", h.Put)
+		dav.DELETE("/*path", h.Delete)
+	}
+}
+
+type calDAVHandler struct {
+	taskHandler
+	root string // path of the DAV root, ending in a slash
+}
+
+// davKind is the kind of a DAV resource.
+type davKind int
+
+const (
+	davRoot       davKind = iota // the DAV root
+	davPrincipal                 // the caller
+	davHome                      // the caller's calendar home, holding the collections
+	davCollection                // a project
+	davObject                    // a task or subtask in a project
+)
+
+// davResource is the resource a request path names. Objects may not exist.
+type davResource struct {
+	kind    davKind
+	href    string
+	project *model.Project // of a collection or object
+	name    string         // of an object
+}
+
+func (h *calDAVHandler) principalHref() string {
+	return h.root + "principal/"
+}
+
+func (h *calDAVHandler) homeHref() string {
+	return h.root + "calendars/"
+}
+
+func (h *calDAVHandler) collectionHref(projectID string) string {
+	return h.homeHref() + projectID + "/"
+}
+
+func (h *calDAVHandler) objectHref(projectID, name string) string {
+	return h.collectionHref(projectID) + url.PathEscape(name)
+}
+
+// resolve returns the resource at p, relative to the DAV root.
+func (h *calDAVHandler) resolve(userID, p string) (*davResource, error) {
+	parts := strings.FieldsFunc(p, func(r rune) bool { return r == '/' })
+	switch {
+	case len(parts) == 0:
+		return &davResource{kind: davRoot, href: h.root}, nil
+	case len(parts) == 1 && parts[0] == "principal":
+		return &davResource{kind: davPrincipal, href: h.principalHref()}, nil
+	case len(parts) == 1 && parts[0] == "calendars":
+		return &davResource{kind: davHome, href: h.homeHref()}, nil
+	case len(parts) <= 3 && parts[0] == "calendars":
+		if _, err := uuid.Parse(parts[1]); err != nil {
+			return nil, service.ErrProjectNotFound
+		}
+		proj, err := service.CalDAVCollection(h.db, userID, parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == 2 {
+			return &davResource{kind: davCollection, href: h.collectionHref(proj.ID), project: proj}, nil
+		}
+		return &davResource{kind: davObject, href: h.objectHref(proj.ID, parts[2]), project: proj, name: parts[2]}, nil
+	default:
+		return nil, service.ErrCalendarObjectNotFound
+	}
+}
+
+// writeError writes a service error as plain text; DAV clients show it
+// rather than parse it.
+func (h *calDAVHandler) writeError(c *gin.Context, err error) {
+	c.String(serviceStatus(err), err.Error())
+}
+
+// WellKnown redirects CalDAV service discovery to the DAV root.
+func (h *calDAVHandler) WellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, h.root)
+}
+
+// Options advertises the DAV compliance classes.
+func (h *calDAVHandler) Options(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", calDAVMethods)
+	c.Status(http.StatusOK)
+}
+
+// Propfind returns the properties asked for of a resource and, unless Depth
+// is 0, of its members.
+func (h *calDAVHandler) Propfind(c *gin.Context) {
+	userID := h.getUserID(c)
+	res, err := h.resolve(userID, c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	var req propfindRequest
+	if err := decodeDAV(c.Request.Body, &req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	var names []xml.Name
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+	members := c.GetHeader("Depth") != "0"
+	ms := newMultistatus()
+	add := func(href string, props []davProp) {
+		found, missing := selectProps(props, names)
+		if req.PropName != nil {
+			for i := range found {
+				found[i].Inner = ""
+			}
+		}
+		ms.response(href, found, missing)
+	}
+
+	switch res.kind {
+	case davRoot:
+		add(res.href, h.rootProps(davRoot))
+		if members {
+			add(h.principalHref(), h.principalProps(userID))
+			add(h.homeHref(), h.rootProps(davHome))
+		}
+	case davPrincipal:
+		add(res.href, h.principalProps(userID))
+	case davHome:
+		add(res.href, h.rootProps(davHome))
+		if members {
+			projects, err := service.CalDAVCollections(h.db, userID)
+			if err != nil {
+				h.writeError(c, err)
+				return
+			}
+			for i := range projects {
+				changes, err := service.CalDAVChangesSince(h.db, userID, projects[i].ID, 0)
+				if err != nil {
+					h.writeError(c, err)
+					return
+				}
+				add(h.collectionHref(projects[i].ID), h.collectionProps(&projects[i], changes.Token))
+			}
+		}
+	case davCollection:
+		changes, err := service.CalDAVChangesSince(h.db, userID, res.project.ID, 0)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		add(res.href, h.collectionProps(res.project, changes.Token))
+		if members {
+			for i := range changes.Objects {
+				obj := &changes.Objects[i]
+				add(h.objectHref(res.project.ID, obj.Name()), objectProps(obj))
+			}
+		}
+	case davObject:
+		obj, err := service.FindCalDAVObject(h.db, userID, res.project.ID, res.name)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		add(res.href, objectProps(obj))
+	}
+	ms.write(c)
+}
+
+// Proppatch refuses to change properties: they all follow from projects
+// and tasks.
+func (h *calDAVHandler) Proppatch(c *gin.Context) {
+	res, err := h.resolve(h.getUserID(c), c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	var req proppatchRequest
+	if err := decodeDAV(c.Request.Body, &req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	var names []xml.Name
+	for _, s := range req.Set {
+		names = append(names, s.Prop...)
+	}
+	for _, r := range req.Remove {
+		names = append(names, r.Prop...)
+	}
+	ms := newMultistatus()
+	ms.refused(res.href, names)
+	ms.write(c)
+}
+
+// Report runs a calendar-multiget, calendar-query or sync-collection report
+// on a collection.
+func (h *calDAVHandler) Report(c *gin.Context) {
+	userID := h.getUserID(c)
+	res, err := h.resolve(userID, c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	var req reportRequest
+	if err := decodeDAV(c.Request.Body, &req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if res.kind != davCollection {
+		davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+	var names []xml.Name
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+	ms := newMultistatus()
+	add := func(href string, obj *service.CalDAVObject) {
+		found, missing := selectProps(objectProps(obj), names)
+		ms.response(href, found, missing)
+	}
+
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			name, ok := h.objectName(href, res.project.ID)
+			if !ok {
+				ms.status(href, http.StatusNotFound)
+				continue
+			}
+			obj, err := service.FindCalDAVObject(h.db, userID, res.project.ID, name)
+			if errors.Is(err, service.ErrCalendarObjectNotFound) {
+				ms.status(href, http.StatusNotFound)
+				continue
+			}
+			if err != nil {
+				h.writeError(c, err)
+				return
+			}
+			add(href, obj)
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		changes, err := service.CalDAVChangesSince(h.db, userID, res.project.ID, 0)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		for i := range changes.Objects {
+			obj := &changes.Objects[i]
+			if req.Filter != nil {
+				cal, err := ical.Decode(obj.Data)
+				if err != nil || !matchComps([]*ical.Component{cal}, *req.Filter) {
+					continue
+				}
+			}
+			add(h.objectHref(res.project.ID, obj.Name()), obj)
+		}
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		since, err := parseCalDAVSyncToken(req.SyncToken)
+		if err != nil {
+			davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+			return
+		}
+		changes, err := service.CalDAVChangesSince(h.db, userID, res.project.ID, since)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		for i := range changes.Objects {
+			obj := &changes.Objects[i]
+			add(h.objectHref(res.project.ID, obj.Name()), obj)
+		}
+		for _, name := range changes.Removed {
+			ms.status(h.objectHref(res.project.ID, name), http.StatusNotFound)
+		}
+		ms.syncToken(formatCalDAVSyncToken(changes.Token))
+	default:
+		davError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+	ms.write(c)
+}
+
+// objectName returns the name of the object href refers to, when it is in
+// the collection of projectID.
+func (h *calDAVHandler) objectName(href, projectID string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(u.Path, h.collectionHref(projectID))
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// Get returns a calendar object resource.
+func (h *calDAVHandler) Get(c *gin.Context) {
+	userID := h.getUserID(c)
+	res, err := h.resolve(userID, c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if res.kind != davObject {
+		h.methodNotAllowed(c)
+		return
+	}
+	obj, err := service.FindCalDAVObject(h.db, userID, res.project.ID, res.name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Header("ETag", obj.ETag)
+	c.Header("Last-Modified", obj.UpdatedAt().UTC().Format(http.TimeFormat))
+	if m := c.GetHeader("If-None-Match"); m != "" && etagListed(m, obj.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, calDAVContentType, obj.Data)
+}
+
+// Put creates or replaces a task, or a subtask when the VTODO is RELATED-TO
+// a task of the collection. A new resource must be named after the UID of
+// its VTODO. If-Match and If-None-Match apply to the object's ETag.
+func (h *calDAVHandler) Put(c *gin.Context) {
+	userID := h.getUserID(c)
+	res, err := h.resolve(userID, c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if res.kind != davObject {
+		h.methodNotAllowed(c)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxDAVRequest+1))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(data) > maxDAVRequest {
+		c.String(http.StatusRequestEntityTooLarge, "calendar object too large")
+		return
+	}
+	todo, err := service.ParseVTodo(data)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	existing, err := service.FindCalDAVObject(h.db, userID, res.project.ID, res.name)
+	if errors.Is(err, service.ErrCalendarObjectNotFound) {
+		existing, err = nil, nil
+	}
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if !davPreconditions(c, existing) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+	switch {
+	case existing == nil && todo.UID+".ics" != res.name:
+		h.writeError(c, fmt.Errorf("%w: the resource name must be the UID followed by .ics", service.ErrInvalidCalendarObject))
+		return
+	case existing != nil && todo.UID != existing.UID():
+		h.writeError(c, fmt.Errorf("%w: the UID cannot change", service.ErrInvalidCalendarObject))
+		return
+	case existing != nil && (existing.Subtask != nil) != (todo.ParentUID != ""):
+		h.writeError(c, fmt.Errorf("%w: a task cannot become a subtask or the other way around", service.ErrInvalidCalendarObject))
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if todo.ParentUID != "" {
+			return h.putSubtask(tx, userID, res.project.ID, existing, todo)
+		}
+		return h.putTask(tx, userID, res.project.ID, existing, todo)
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if existing == nil {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// putTask creates or updates the task of a VTODO in projectID. A custom
+// status is kept while the VTODO's STATUS is of its category.
+func (h *calDAVHandler) putTask(tx *gorm.DB, userID, projectID string, existing *service.CalDAVObject, todo *service.VTodo) error {
+	labelIDs, err := service.EnsureLabels(tx, userID, todo.Categories)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		task, err := h.create(tx, userID, uuid.New().String(), dto.TaskCreateRequest{
+			Title:       todo.Summary,
+			Description: todo.Description,
+			ProjectID:   projectID,
+			Priority:    todo.Priority,
+			DueDate:     todo.DueDate,
+			ReminderAt:  todo.ReminderAt,
+			Recurrence:  todo.Recurrence,
+			LabelIDs:    labelIDs,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(task).UpdateColumn("ical_uid", todo.UID).Error; err != nil {
+			return err
+		}
+		task.ICalUID = todo.UID
+		if todo.Status == model.TaskStatusPending && todo.Progress == 0 {
+			return nil
+		}
+		before := *task
+		task.Progress = todo.Progress
+		return h.save(tx, userID, task, &before, nil, &todo.Status, nil, false)
+	}
+
+	task := existing.Task
+	before := *task
+	statuses, err := service.LoadStatusSet(tx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	status := todo.Status
+	if statuses[task.Status] == todo.Status {
+		status = task.Status
+	}
+	task.Title, task.Description, task.Priority, task.Progress = todo.Summary, todo.Description, todo.Priority, todo.Progress
+	task.DueDate, task.ReminderAt, task.Recurrence = todo.DueDate, todo.ReminderAt, todo.Recurrence
+	if sameLabels(task.Labels, labelIDs) {
+		labelIDs = nil
+	}
+	return h.save(tx, userID, task, &before, nil, &status, labelIDs, false)
+}
+
+// putSubtask creates or updates the subtask of a VTODO, under the task of
+// projectID it is RELATED-TO.
+func (h *calDAVHandler) putSubtask(tx *gorm.DB, userID, projectID string, existing *service.CalDAVObject, todo *service.VTodo) error {
+	parent, err := service.FindCalDAVParent(tx, userID, projectID, todo.ParentUID)
+	if errors.Is(err, service.ErrCalendarObjectNotFound) {
+		return fmt.Errorf("%w: RELATED-TO %s is not a task of this calendar", service.ErrInvalidCalendarObject, todo.ParentUID)
+	}
+	if err != nil {
+		return err
+	}
+	completed := todo.Status == model.TaskStatusCompleted
+	if existing == nil {
+		sub := model.Subtask{ID: uuid.New().String(), TaskID: parent.ID, Title: todo.Summary, Completed: completed, ICalUID: todo.UID}
+		return service.CreateLogged(tx, userID, &sub)
+	}
+	sub := *existing.Subtask
+	before := sub
+	sub.TaskID, sub.Title, sub.Completed = parent.ID, todo.Summary, completed
+	return service.SaveLogged(tx, userID, &before, &sub)
+}
+
+// sameLabels reports whether labels are those of ids.
+func sameLabels(labels []model.Label, ids []string) bool {
+	if len(labels) != len(ids) {
+		return false
+	}
+	for _, l := range labels {
+		found := false
+		for _, id := range ids {
+			found = found || id == l.ID
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete deletes a task or subtask. If-Match applies to its ETag.
+func (h *calDAVHandler) Delete(c *gin.Context) {
+	userID := h.getUserID(c)
+	res, err := h.resolve(userID, c.Param("path"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	switch res.kind {
+	case davObject:
+	case davCollection:
+		c.String(http.StatusForbidden, "projects cannot be deleted over CalDAV")
+		return
+	default:
+		h.methodNotAllowed(c)
+		return
+	}
+	obj, err := service.FindCalDAVObject(h.db, userID, res.project.ID, res.name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if !davPreconditions(c, obj) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if obj.Subtask != nil {
+			return service.DeleteLogged(tx, userID, obj.Subtask)
+		}
+		if err := tx.Delete(obj.Task).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, userID, obj.Task)
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *calDAVHandler) methodNotAllowed(c *gin.Context) {
+	c.Header("Allow", calDAVMethods)
+	c.Status(http.StatusMethodNotAllowed)
+}
+
+// davPreconditions reports whether the If-Match and If-None-Match headers
+// hold for obj, nil when the resource does not exist.
+func davPreconditions(c *gin.Context, obj *service.CalDAVObject) bool {
+	if m := c.GetHeader("If-Match"); m != "" && (obj == nil || !etagListed(m, obj.ETag)) {
+		return false
+	}
+	if m := c.GetHeader("If-None-Match"); m != "" && obj != nil && etagListed(m, obj.ETag) {
+		return false
+	}
+	return true
+}
+
+// etagListed reports whether an If-Match or If-None-Match header lists etag,
+// or is "*".
+func etagListed(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCalDAVSyncToken(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	seq, ok := strings.CutPrefix(s, calDAVSyncTokenPrefix)
+	if !ok {
+		return 0, service.ErrInvalidSyncToken
+	}
+	n, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil || n < 0 {
+		return 0, service.ErrInvalidSyncToken
+	}
+	return n, nil
+}
+
+func formatCalDAVSyncToken(seq int64) string {
+	return calDAVSyncTokenPrefix + strconv.FormatInt(seq, 10)
+}
+
+// selectProps returns the props named and the names missing from props.
+// No names selects all props but calendar-data, which is only sent when
+// asked for.
+func selectProps(props []davProp, names []xml.Name) ([]davProp, []xml.Name) {
+	if names == nil {
+		var found []davProp
+		for _, p := range props {
+			if p.Name != (xml.Name{Space: nsCalDAV, Local: "calendar-data"}) {
+				found = append(found, p)
+			}
+		}
+		return found, nil
+	}
+	var found []davProp
+	var missing []xml.Name
+	for _, n := range names {
+		i := 0
+		for i < len(props) && props[i].Name != n {
+			i++
+		}
+		if i < len(props) {
+			found = append(found, props[i])
+		} else {
+			missing = append(missing, n)
+		}
+	}
+	return found, missing
+}
+
+func davName(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
+
+// rootProps returns the properties of the DAV root or the calendar home.
+func (h *calDAVHandler) rootProps(kind davKind) []davProp {
+	name := "Tasks"
+	if kind == davHome {
+		name = "Calendars"
+	}
+	return []davProp{
+		{davName(nsDAV, "resourcetype"), "<d:collection/>"},
+		{davName(nsDAV, "displayname"), name},
+		{davName(nsDAV, "current-user-principal"), hrefXML(h.principalHref())},
+		{davName(nsDAV, "owner"), hrefXML(h.principalHref())},
+	}
+}
+
+// principalProps returns the properties of the caller's principal.
+func (h *calDAVHandler) principalProps(userID string) []davProp {
+	username := service.AppPasswordUsername(h.db, userID)
+	props := []davProp{
+		{davName(nsDAV, "resourcetype"), "<d:collection/><d:principal/>"},
+		{davName(nsDAV, "displayname"), escapeXML(username)},
+		{davName(nsDAV, "current-user-principal"), hrefXML(h.principalHref())},
+		{davName(nsDAV, "principal-URL"), hrefXML(h.principalHref())},
+		{davName(nsCalDAV, "calendar-home-set"), hrefXML(h.homeHref())},
+	}
+	if strings.Contains(username, "@") {
+		props = append(props, davProp{davName(nsCalDAV, "calendar-user-address-set"), hrefXML("mailto:" + username)})
+	}
+	return props
+}
+
+// collectionProps returns the properties of the collection of proj, at
+// sync position token.
+func (h *calDAVHandler) collectionProps(proj *model.Project, token int64) []davProp {
+	sync := escapeXML(formatCalDAVSyncToken(token))
+	var reports strings.Builder
+	for _, r := range []string{"<cal:calendar-multiget/>", "<cal:calendar-query/>", "<d:sync-collection/>"} {
+		reports.WriteString("<d:supported-report><d:report>" + r + "</d:report></d:supported-report>")
+	}
+	var privileges strings.Builder
+	for _, p := range []string{"read", "write", "write-content", "bind", "unbind", "read-current-user-privilege-set"} {
+		privileges.WriteString("<d:privilege><d:" + p + "/></d:privilege>")
+	}
+	props := []davProp{
+		{davName(nsDAV, "resourcetype"), "<d:collection/><cal:calendar/>"},
+		{davName(nsDAV, "displayname"), escapeXML(proj.Name)},
+		{davName(nsDAV, "current-user-principal"), hrefXML(h.principalHref())},
+		{davName(nsDAV, "sync-token"), sync},
+		{davName(nsCalServer, "getctag"), sync},
+		{davName(nsDAV, "supported-report-set"), reports.String()},
+		{davName(nsDAV, "current-user-privilege-set"), privileges.String()},
+		{davName(nsCalDAV, "supported-calendar-component-set"), `<cal:comp name="VTODO"/>`},
+		{davName(nsCalDAV, "supported-calendar-data"), `<cal:calendar-data content-type="text/calendar" version="2.0"/>`},
+	}
+	if proj.Color != "" {
+		props = append(props, davProp{davName(nsAppleICal, "calendar-color"), escapeXML(proj.Color)})
+	}
+	return props
+}
+
+// objectProps returns the properties of a calendar object resource.
+func objectProps(obj *service.CalDAVObject) []davProp {
+	return []davProp{
+		{davName(nsDAV, "resourcetype"), ""},
+		{davName(nsDAV, "getetag"), escapeXML(obj.ETag)},
+		{davName(nsDAV, "getcontenttype"), calDAVContentType},
+		{davName(nsDAV, "getcontentlength"), strconv.Itoa(len(obj.Data))},
+		{davName(nsDAV, "getlastmodified"), obj.UpdatedAt().UTC().Format(http.TimeFormat)},
+		{davName(nsCalDAV, "calendar-data"), escapeXML(string(obj.Data))},
+	}
+}
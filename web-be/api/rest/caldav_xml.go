@@ -0,0 +1,352 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/todo-tracking-app/web-be/internal/ical"
+)
+
+// XML namespaces of the WebDAV properties served.
+const (
+	nsDAV       = "DAV:"
+	nsCalDAV    = "urn:ietf:params:xml:ns:caldav"
+	nsCalServer = "http://calendarserver.org/ns/"
+	nsAppleICal = "http://apple.com/ns/ical/"
+)
+
+// davPrefixes are the prefixes multistatus responses declare.
+var davPrefixes = []struct{ ns, prefix string }{
+	{nsDAV, "d"}, {nsCalDAV, "cal"}, {nsCalServer, "cs"}, {nsAppleICal, "ical"},
+}
+
+// davProp is a property with its value as XML.
+type davProp struct {
+	Name  xml.Name
+	Inner string
+}
+
+// davPropNames collects the names of the elements in a DAV:prop.
+type davPropNames []xml.Name
+
+func (p *davPropNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// propfindRequest is a PROPFIND body. An empty body asks for all properties.
+type propfindRequest struct {
+	XMLName  xml.Name      `xml:"DAV: propfind"`
+	AllProp  *struct{}     `xml:"DAV: allprop"`
+	PropName *struct{}     `xml:"DAV: propname"`
+	Prop     *davPropNames `xml:"DAV: prop"`
+}
+
+// proppatchRequest is a PROPPATCH body.
+type proppatchRequest struct {
+	XMLName xml.Name `xml:"DAV: propertyupdate"`
+	Set     []struct {
+		Prop davPropNames `xml:"DAV: prop"`
+	} `xml:"DAV: set"`
+	Remove []struct {
+		Prop davPropNames `xml:"DAV: prop"`
+	} `xml:"DAV: remove"`
+}
+
+// reportRequest is a REPORT body; XMLName tells which report.
+type reportRequest struct {
+	XMLName   xml.Name
+	AllProp   *struct{}      `xml:"DAV: allprop"`
+	Prop      *davPropNames  `xml:"DAV: prop"`
+	Hrefs     []string       `xml:"DAV: href"`
+	Filter    *calCompFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+	SyncToken string         `xml:"DAV: sync-token"`
+}
+
+// calCompFilter is a CalDAV comp-filter: the component matches when it has
+// a time in TimeRange and matches all nested filters, or, with IsNotDefined,
+// when there is no such component.
+type calCompFilter struct {
+	Name         string          `xml:"name,attr"`
+	IsNotDefined *struct{}       `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *calTimeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []calPropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []calCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// calPropFilter is a CalDAV prop-filter. Parameter filters are not
+// supported and match.
+type calPropFilter struct {
+	Name         string        `xml:"name,attr"`
+	IsNotDefined *struct{}     `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *calTimeRange `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *struct {
+		Value  string `xml:",chardata"`
+		Negate string `xml:"negate-condition,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+// calTimeRange is a CalDAV time-range; either end may be open.
+type calTimeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// decodeDAV decodes a request body into v; an empty body leaves v as is.
+func decodeDAV(body io.Reader, v any) error {
+	data, err := io.ReadAll(io.LimitReader(body, maxDAVRequest))
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// matchComps reports whether one of comps matches f, or, with
+// f.IsNotDefined, that none is called f.Name.
+func matchComps(comps []*ical.Component, f calCompFilter) bool {
+	name := strings.ToUpper(f.Name)
+	for _, c := range comps {
+		if c.Name != name {
+			continue
+		}
+		if f.IsNotDefined != nil {
+			return false
+		}
+		if matchComp(c, f) {
+			return true
+		}
+	}
+	return f.IsNotDefined != nil
+}
+
+func matchComp(c *ical.Component, f calCompFilter) bool {
+	if f.TimeRange != nil && !todoInRange(c, f.TimeRange) {
+		return false
+	}
+	for _, pf := range f.PropFilters {
+		if !matchProp(c.All(strings.ToUpper(pf.Name)), pf) {
+			return false
+		}
+	}
+	for _, cf := range f.CompFilters {
+		if !matchComps(c.Components, cf) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchProp(props []*ical.Prop, f calPropFilter) bool {
+	if f.IsNotDefined != nil {
+		return len(props) == 0
+	}
+	for _, p := range props {
+		if f.TimeRange != nil {
+			t, err := p.Time(time.UTC)
+			if err != nil || !overlaps(f.TimeRange, t, t) {
+				continue
+			}
+		}
+		if m := f.TextMatch; m != nil {
+			// i;ascii-casemap, the default collation.
+			found := strings.Contains(strings.ToLower(p.Text()), strings.ToLower(m.Value))
+			if found == (m.Negate == "yes") {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// todoInRange reports whether a VTODO may fall in r, from its DTSTART and
+// DUE, else COMPLETED and CREATED. Recurring to-dos are taken to go on
+// forever; to-dos without any of these times are always in range.
+func todoInRange(c *ical.Component, r *calTimeRange) bool {
+	var times []time.Time
+	for _, names := range [][]string{{"DTSTART", "DUE"}, {"COMPLETED", "CREATED"}} {
+		for _, name := range names {
+			if p := c.Prop(name); p != nil {
+				if t, err := p.Time(time.UTC); err == nil {
+					times = append(times, t)
+				}
+			}
+		}
+		if len(times) > 0 {
+			break
+		}
+	}
+	if len(times) == 0 {
+		return true
+	}
+	from, to := times[0], times[0]
+	for _, t := range times[1:] {
+		from, to = minTime(from, t), maxTime(to, t)
+	}
+	if c.Prop("RRULE") != nil {
+		to = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+	return overlaps(r, from, to)
+}
+
+// overlaps reports whether [from, to] overlaps r.
+func overlaps(r *calTimeRange, from, to time.Time) bool {
+	if start, err := time.Parse("20060102T150405Z", r.Start); err == nil && to.Before(start) {
+		return false
+	}
+	if end, err := time.Parse("20060102T150405Z", r.End); err == nil && !from.Before(end) {
+		return false
+	}
+	return true
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// multistatus builds a DAV:multistatus response.
+type multistatus struct {
+	b strings.Builder
+}
+
+func newMultistatus() *multistatus {
+	m := &multistatus{}
+	m.b.WriteString(xml.Header + "<d:multistatus")
+	for _, p := range davPrefixes {
+		m.b.WriteString(` xmlns:` + p.prefix + `="` + p.ns + `"`)
+	}
+	m.b.WriteString(">")
+	return m
+}
+
+// response adds a response for href with the found properties, and the
+// missing ones as not found.
+func (m *multistatus) response(href string, found []davProp, missing []xml.Name) {
+	m.b.WriteString("<d:response>" + hrefXML(href))
+	if len(found) > 0 || len(missing) == 0 {
+		m.propstat(found, nil, http.StatusOK)
+	}
+	if len(missing) > 0 {
+		m.propstat(nil, missing, http.StatusNotFound)
+	}
+	m.b.WriteString("</d:response>")
+}
+
+// propstat adds the props, or the empty named ones, with status.
+func (m *multistatus) propstat(props []davProp, names []xml.Name, status int) {
+	m.b.WriteString("<d:propstat><d:prop>")
+	for _, p := range props {
+		m.element(p.Name, p.Inner)
+	}
+	for _, n := range names {
+		m.element(n, "")
+	}
+	m.b.WriteString("</d:prop>" + statusLine(status) + "</d:propstat>")
+}
+
+// refused adds a response for href refusing to change the named
+// properties.
+func (m *multistatus) refused(href string, names []xml.Name) {
+	m.b.WriteString("<d:response>" + hrefXML(href))
+	m.propstat(nil, names, http.StatusForbidden)
+	m.b.WriteString("</d:response>")
+}
+
+// status adds a response for href with just a status, e.g. for a removed
+// resource.
+func (m *multistatus) status(href string, status int) {
+	m.b.WriteString("<d:response>" + hrefXML(href) + statusLine(status) + "</d:response>")
+}
+
+// syncToken adds the sync-token of a sync-collection report.
+func (m *multistatus) syncToken(token string) {
+	m.b.WriteString("<d:sync-token>" + escapeXML(token) + "</d:sync-token>")
+}
+
+func (m *multistatus) element(n xml.Name, inner string) {
+	tag, decl := n.Local, ""
+	if prefix := davPrefix(n.Space); prefix != "" {
+		tag = prefix + ":" + n.Local
+	} else if n.Space != "" {
+		decl = ` xmlns="` + escapeXML(n.Space) + `"`
+	}
+	if inner == "" {
+		m.b.WriteString("<" + tag + decl + "/>")
+		return
+	}
+	m.b.WriteString("<" + tag + decl + ">" + inner + "</" + tag + ">")
+}
+
+func (m *multistatus) write(c *gin.Context) {
+	m.b.WriteString("</d:multistatus>")
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", []byte(m.b.String()))
+}
+
+// davError writes a DAV:error body naming the failed precondition, e.g.
+// valid-sync-token.
+func davError(c *gin.Context, status int, precondition xml.Name) {
+	var m multistatus
+	m.b.WriteString(xml.Header + `<d:error xmlns:d="DAV:" xmlns:cal="` + nsCalDAV + `">`)
+	m.element(precondition, "")
+	m.b.WriteString("</d:error>")
+	c.Data(status, "application/xml; charset=utf-8", []byte(m.b.String()))
+}
+
+func davPrefix(ns string) string {
+	for _, p := range davPrefixes {
+		if p.ns == ns {
+			return p.prefix
+		}
+	}
+	return ""
+}
+
+func statusLine(status int) string {
+	return "<d:status>HTTP/1.1 " + strconv.Itoa(status) + " " + http.StatusText(status) + "</d:status>"
+}
+
+// hrefXML is a DAV:href element.
+func hrefXML(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	if err := xml.EscapeText(&b, []byte(s)); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// calendarFeedPath is where feeds are served, relative to the API root.
+const calendarFeedPath = "/calendar/"
+
+// RegisterCalendarRoutes registers the iCalendar feed route. It must NOT use
+// auth middleware: calendar apps subscribe to the URL alone, the secret token
+// in it standing in for credentials.
+func RegisterCalendarRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &calendarHandler{db: db}
+	r.GET(calendarFeedPath+":file", h.Feed)
+}
+
+// RegisterCalendarProtectedRoutes registers the caller's feed token routes.
+func RegisterCalendarProtectedRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &calendarHandler{db: db}
+	group := r.Group("/me/calendar-feed")
+	{
+		group.GET("", h.Get)
+		group.POST("", h.Rotate)
+		group.DELETE("", h.Revoke)
+	}
+}
+
+type calendarHandler struct {
+	db *gorm.DB
+}
+
+func (h *calendarHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// Feed serves a user's tasks with a due date as an iCalendar (RFC 5545)
+// calendar, for calendar apps to subscribe to. Tasks are VEVENTs at their due
+// date by default, or VTODOs due then, with their reminder as a VALARM and
+// their recurrence as an RRULE.
+// @Summary iCalendar feed of tasks
+// @Tags calendar
+// @Produce text/calendar
+// @Param file path string true "Feed token followed by .ics"
+// @Param project_id query string false "Only tasks in this project"
+// @Param label_id query string false "Only tasks with this label"
+// @Param component query string false "VEVENT (default) or VTODO"
+// @Success 200 {string} string "VCALENDAR"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendar/{file} [get]
+func (h *calendarHandler) Feed(c *gin.Context) {
+	userID, err := service.CalendarFeedUser(h.db, strings.TrimSuffix(c.Param("file"), ".ics"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	q := service.CalendarQuery{
+		ProjectID: c.Query("project_id"),
+		LabelID:   c.Query("label_id"),
+		Component: c.Query("component"),
+	}
+	var buf bytes.Buffer
+	if err := service.WriteCalendarFeed(h.db, &buf, userID, q, time.Now()); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// Get returns whether the caller has a feed and since when. The token is
+// not kept and cannot be shown again; rotate to get a new URL.
+// @Summary Get calendar feed
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CalendarFeedVO
+// @Failure 404 {object} map[string]string
+// @Router /me/calendar-feed [get]
+func (h *calendarHandler) Get(c *gin.Context) {
+	feed, err := service.GetCalendarFeed(h.db, h.getUserID(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.CalendarFeedVO{CreatedAt: feed.CreatedAt})
+}
+
+// Rotate creates the caller's feed, or replaces its token so that the old
+// URL stops working, and returns the new URL.
+// @Summary Create or rotate calendar feed
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} dto.CalendarFeedTokenVO
+// @Router /me/calendar-feed [post]
+func (h *calendarHandler) Rotate(c *gin.Context) {
+	token, feed, err := service.RotateCalendarFeed(h.db, h.getUserID(c))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.CalendarFeedTokenVO{
+		Token:     token,
+		URL:       feedURL(c, token),
+		CreatedAt: feed.CreatedAt,
+	})
+}
+
+// Revoke removes the caller's feed; its URL stops working.
+// @Summary Revoke calendar feed
+// @Tags calendar
+// @Security BearerAuth
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /me/calendar-feed [delete]
+func (h *calendarHandler) Revoke(c *gin.Context) {
+	if err := service.RevokeCalendarFeed(h.db, h.getUserID(c)); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// feedURL returns the absolute URL of the feed with token, as seen by the
+// client making the current request under the same API root.
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	root := strings.TrimSuffix(c.FullPath(), "/me/calendar-feed")
+	return scheme + "://" + c.Request.Host + root + calendarFeedPath + token + ".ics"
+}
@@ -0,0 +1,246 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterCommentRoutes registers comment routes on tasks and projects.
+func RegisterCommentRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &commentHandler{db: db}
+	r.GET("/tasks/:id/comments", h.ListTask)
+	r.POST("/tasks/:id/comments", h.CreateTask)
+	r.GET("/projects/:id/comments", h.ListProject)
+	r.POST("/projects/:id/comments", h.CreateProject)
+	comments := r.Group("/comments")
+	{
+		comments.PUT("/:id", h.Update)
+		comments.DELETE("/:id", h.Delete)
+	}
+}
+
+type commentHandler struct {
+	db *gorm.DB
+}
+
+func (h *commentHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// ListTask returns the comments on a task, oldest first.
+// @Summary List task comments
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.CommentVO
+// @Header 200 {integer} X-Total-Count "Total number of comments"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments [get]
+func (h *commentHandler) ListTask(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.list(c, h.db.Where("task_id = ?", task.ID))
+}
+
+// ListProject returns the project-level comments of a project, oldest first.
+// @Summary List project comments
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.CommentVO
+// @Header 200 {integer} X-Total-Count "Total number of comments"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/comments [get]
+func (h *commentHandler) ListProject(c *gin.Context) {
+	projectID := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), projectID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.list(c, h.db.Where("project_id = ? AND task_id IS NULL", projectID))
+}
+
+func (h *commentHandler) list(c *gin.Context, q *gorm.DB) {
+	q = q.Session(&gorm.Session{})
+	limit, offset := parsePage(c)
+	var total int64
+	if err := q.Model(&model.Comment{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var comments []model.Comment
+	if err := q.Preload("Mentions").Order("created_at, id").Limit(limit).Offset(offset).
+		Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.CommentVO, 0, len(comments))
+	for _, cm := range comments {
+		vos = append(vos, commentToVO(cm))
+	}
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, vos)
+}
+
+// CreateTask adds a comment to a task.
+// @Summary Create task comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.CommentCreateRequest true "Comment create request"
+// @Success 201 {object} dto.CommentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/comments [post]
+func (h *commentHandler) CreateTask(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.create(c, model.Comment{TaskID: &task.ID})
+}
+
+// CreateProject adds a project-level comment.
+// @Summary Create project comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param body body dto.CommentCreateRequest true "Comment create request"
+// @Success 201 {object} dto.CommentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/comments [post]
+func (h *commentHandler) CreateProject(c *gin.Context) {
+	projectID := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), projectID); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.create(c, model.Comment{ProjectID: &projectID})
+}
+
+func (h *commentHandler) create(c *gin.Context, comment model.Comment) {
+	var req dto.CommentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	comment.ID = uuid.New().String()
+	comment.UserID = h.getUserID(c)
+	comment.Body = req.Body
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.SaveComment(tx, &comment, true); err != nil {
+			return err
+		}
+		return service.RecordCreate(tx, comment.UserID, &comment)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, commentToVO(comment))
+}
+
+// Update edits a comment. Only the author may edit.
+// @Summary Update comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param body body dto.CommentUpdateRequest true "Comment update request"
+// @Success 200 {object} dto.CommentVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /comments/{id} [put]
+func (h *commentHandler) Update(c *gin.Context) {
+	var req dto.CommentUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	comment, err := service.AuthoredComment(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	before := *comment
+	comment.Body = req.Body
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.SaveComment(tx, comment, false); err != nil {
+			return err
+		}
+		return service.RecordUpdate(tx, comment.UserID, &before, comment)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, commentToVO(*comment))
+}
+
+// Delete soft-deletes a comment. Only the author may delete.
+// @Summary Delete comment
+// @Tags comments
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /comments/{id} [delete]
+func (h *commentHandler) Delete(c *gin.Context) {
+	comment, err := service.AuthoredComment(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.DeleteLogged(h.db, comment.UserID, comment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func commentToVO(cm model.Comment) dto.CommentVO {
+	vo := dto.CommentVO{
+		ID:        cm.ID,
+		TaskID:    cm.TaskID,
+		ProjectID: cm.ProjectID,
+		UserID:    cm.UserID,
+		Body:      cm.Body,
+		Mentions:  []string{},
+		EditedAt:  cm.EditedAt,
+		CreatedAt: cm.CreatedAt,
+	}
+	for _, m := range cm.Mentions {
+		vo.Mentions = append(vo.Mentions, m.UserID)
+	}
+	return vo
+}
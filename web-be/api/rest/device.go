@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterDeviceRoutes registers push device routes for the current user.
+func RegisterDeviceRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &deviceHandler{db: db}
+	group := r.Group("/me/devices")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Register)
+		group.DELETE("", h.Unregister)
+	}
+}
+
+type deviceHandler struct {
+	db *gorm.DB
+}
+
+func (h *deviceHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns the caller's registered push devices.
+// @Summary List push devices
+// @Tags devices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.DeviceVO
+// @Router /me/devices [get]
+func (h *deviceHandler) List(c *gin.Context) {
+	var devices []model.Device
+	if err := h.db.Where("user_id = ?", h.getUserID(c)).Order("created_at").Find(&devices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.DeviceVO, 0, len(devices))
+	_ = copier.Copy(&vos, &devices)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Register records an APNs or FCM token for the caller. Apps should call it
+// on every launch; a known token is refreshed and moved to the caller.
+// @Summary Register push device
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.DeviceRegisterRequest true "Device"
+// @Success 200 {object} dto.DeviceVO
+// @Failure 400 {object} map[string]string
+// @Router /me/devices [post]
+func (h *deviceHandler) Register(c *gin.Context) {
+	var req dto.DeviceRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := service.RegisterDevice(h.db, h.getUserID(c), req.Platform, req.Token, req.AppVersion)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var vo dto.DeviceVO
+	_ = copier.Copy(&vo, d)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Unregister removes a push token of the caller, e.g. on sign-out.
+// @Summary Unregister push device
+// @Tags devices
+// @Accept json
+// @Security BearerAuth
+// @Param body body dto.DeviceUnregisterRequest true "Device token"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /me/devices [delete]
+func (h *deviceHandler) Unregister(c *gin.Context) {
+	var req dto.DeviceUnregisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := service.UnregisterDevice(h.db, h.getUserID(c), req.Token); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
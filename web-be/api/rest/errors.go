@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// writeServiceError maps service-layer errors onto HTTP status codes.
+// The gRPC server applies the same mapping in serviceError.
+func writeServiceError(c *gin.Context, err error) {
+	var wip *service.WIPLimitError
+	if errors.As(err, &wip) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"status":    wip.Status,
+			"wip_limit": wip.Limit,
+			"current":   wip.Current,
+		})
+		return
+	}
+	var conflict *service.MergeConflictError
+	if errors.As(err, &conflict) {
+		vo := dto.TaskConflictVO{Error: err.Error(), Version: conflict.Version}
+		for _, f := range conflict.Conflicts {
+			vo.Conflicts = append(vo.Conflicts, dto.FieldConflictVO{Field: f.Field, Base: f.Base, Server: f.Server, Client: f.Client})
+		}
+		c.JSON(http.StatusConflict, vo)
+		return
+	}
+	c.JSON(serviceStatus(err), gin.H{"error": err.Error()})
+}
+
+// serviceStatus returns the HTTP status code for a service-layer error.
+func serviceStatus(err error) int {
+	var wip *service.WIPLimitError
+	switch {
+	case errors.As(err, &wip):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrLabelNotFound),
+		errors.Is(err, service.ErrSectionNotFound), errors.Is(err, service.ErrTaskNotFound),
+		errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrAttachmentNotFound),
+		errors.Is(err, service.ErrRevisionNotFound), errors.Is(err, service.ErrReminderNotFound),
+		errors.Is(err, service.ErrNotificationNotFound), errors.Is(err, service.ErrAssignmentNotFound),
+		errors.Is(err, service.ErrDeviceNotFound), errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrWebhookDeliveryNotFound), errors.Is(err, service.ErrSubtaskNotFound),
+		errors.Is(err, service.ErrCalendarFeedNotFound), errors.Is(err, service.ErrAppPasswordNotFound),
+		errors.Is(err, service.ErrCalendarObjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrProjectForbidden), errors.Is(err, service.ErrTaskForbidden),
+		errors.Is(err, service.ErrNotCommentAuthor), errors.Is(err, service.ErrAttachmentQuota),
+		errors.Is(err, service.ErrAppPasswordLimit), errors.Is(err, service.ErrUnsupportedComponent):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidStatus), errors.Is(err, service.ErrInvalidPosition),
+		errors.Is(err, service.ErrInvalidStatusKey), errors.Is(err, service.ErrInvalidNotificationType),
+		errors.Is(err, service.ErrInvalidAssignee), errors.Is(err, service.ErrInvalidPlatform),
+		errors.Is(err, service.ErrInvalidWebhook), errors.Is(err, service.ErrInvalidSyncToken),
+		errors.Is(err, service.ErrInvalidMutation), errors.Is(err, service.ErrInvalidSearch),
+		errors.Is(err, service.ErrInvalidRecurrence), errors.Is(err, service.ErrInvalidQuickAdd),
+		errors.Is(err, service.ErrInvalidCalendar), errors.Is(err, service.ErrInvalidCalendarObject):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrStatusTransition), errors.Is(err, service.ErrDependencyCycle),
+		errors.Is(err, service.ErrTaskBlocked), errors.Is(err, service.ErrReminderFromTask),
+		errors.Is(err, service.ErrWebhookDisabled), errors.Is(err, service.ErrIDConflict),
+		errors.Is(err, service.ErrMergeConflict), errors.Is(err, service.ErrIdempotencyKeyInFlight):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sets the ETag of a versioned resource: its quoted version.
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch returns the versions listed in the If-Match header, for
+// service.CheckVersion. It returns nil, skipping the check, when the header
+// is absent or "*". Tags that are not versions never match.
+func ifMatch(c *gin.Context) []int {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		s, err := strconv.Unquote(tag)
+		if err != nil {
+			continue
+		}
+		if v, err := strconv.Atoi(s); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// eventRetry is the reconnect delay suggested to EventSource clients.
+const eventRetry = 3 * time.Second
+
+// RegisterEventRoutes registers the live update stream. heartbeat is how
+// often an idle stream gets a comment line so proxies keep it open.
+func RegisterEventRoutes(r *gin.RouterGroup, broker *service.EventBroker, heartbeat time.Duration) {
+	h := &eventHandler{broker: broker, heartbeat: heartbeat}
+	r.GET("/events", h.Stream)
+}
+
+type eventHandler struct {
+	broker    *service.EventBroker
+	heartbeat time.Duration
+}
+
+func (h *eventHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// Stream pushes task, project, label and comment changes visible to the
+// caller as Server-Sent Events.
+// @Summary Live updates
+// @Description Server-Sent Events stream. Each change is an event named after its type (task.created, comment.deleted, ...) with a dto.EventVO as data and an id to resume from. A "ready" event is sent once the stream is live. Reconnecting with Last-Event-ID (or ?last_event_id=) replays missed events; when they can no longer be replayed a "reset" event tells the client to refetch. Comment lines are sent as heartbeats. Browsers' EventSource cannot set headers, so the token may be passed as ?access_token=.
+// @Tags events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "Same as Last-Event-ID, for the first connection"
+// @Param access_token query string false "Bearer token, for clients that cannot set headers"
+// @Success 200 {object} dto.EventVO
+// @Failure 400 {object} map[string]string
+// @Router /events [get]
+func (h *eventHandler) Stream(c *gin.Context) {
+	userID := h.getUserID(c)
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var after int64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastID, 10, 64); err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing recorded in between is missed.
+	sub := h.broker.Subscribe(userID)
+	defer h.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry.Milliseconds())
+
+	// Seqs are assigned at insert, not commit, so an event below the last one
+	// replayed can still arrive live; only those replayed are skipped.
+	var last int64
+	replayed := map[int64]bool{}
+	if lastID != "" {
+		events, err := h.broker.Replay(c.Request.Context(), userID, after)
+		switch {
+		case errors.Is(err, service.ErrEventLogExpired):
+			writeSSE(w, h.broker.Head(), "reset", dto.EventResetVO{Reason: err.Error()})
+		case err != nil:
+			log.Printf("events: replay for %s: %v", userID, err)
+			writeSSE(w, h.broker.Head(), "reset", dto.EventResetVO{Reason: "replay failed"})
+		}
+		for _, ev := range events {
+			writeSSE(w, ev.Seq, ev.Type, eventToVO(ev))
+			replayed[ev.Seq] = true
+			last = ev.Seq
+		}
+	}
+	writeSSE(w, max(h.broker.Head(), last), "ready", struct{}{})
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		case ev, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes.
+				return
+			}
+			if replayed[ev.Seq] {
+				continue
+			}
+			writeSSE(w, ev.Seq, ev.Type, eventToVO(ev))
+			w.Flush()
+		}
+	}
+}
+
+// writeSSE writes one Server-Sent Event with data encoded as JSON.
+func writeSSE(w io.Writer, id int64, event string, data any) {
+	raw, _ := json.Marshal(data)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, raw)
+}
+
+func eventToVO(ev service.LiveEvent) dto.EventVO {
+	a := ev.Activity
+	return dto.EventVO{
+		ID:         a.ID,
+		Type:       ev.Type,
+		ActorID:    a.ActorID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		TaskID:     a.TaskID,
+		ProjectID:  a.ProjectID,
+		Changes:    json.RawMessage(a.Changes),
+		CreatedAt:  a.CreatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/todo-tracking-app/web-be/internal/dto"
 	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
 	"github.com/jinzhu/copier"
 )
 
@@ -21,6 +23,7 @@ func RegisterLabelRoutes(r *gin.RouterGroup, db *gorm.DB) {
 		labels.POST("", h.Create)
 		labels.GET("/:id", h.Get)
 		labels.PUT("/:id", h.Update)
+		labels.PATCH("/:id", h.Patch)
 		labels.DELETE("/:id", h.Delete)
 	}
 }
@@ -78,10 +81,11 @@ func (h *labelHandler) Create(c *gin.Context) {
 		Color:  req.Color,
 		UserID: h.getUserID(c),
 	}
-	if err := h.db.Create(&label).Error; err != nil {
+	if err := service.CreateLogged(h.db, label.UserID, &label); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.db.First(&label, "id = ?", label.ID)
 	var vo dto.LabelVO
 	_ = copier.Copy(&vo, &label)
 	c.JSON(http.StatusCreated, vo)
@@ -95,6 +99,7 @@ func (h *labelHandler) Create(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Label ID"
 // @Success 200 {object} dto.LabelVO
+// @Header 200 {string} ETag "Label version, for If-Match"
 // @Failure 404 {object} map[string]string
 // @Router /labels/{id} [get]
 func (h *labelHandler) Get(c *gin.Context) {
@@ -106,10 +111,12 @@ func (h *labelHandler) Get(c *gin.Context) {
 	}
 	var vo dto.LabelVO
 	_ = copier.Copy(&vo, &label)
+	setETag(c, label.Version)
 	c.JSON(http.StatusOK, vo)
 }
 
-// Update updates a label.
+// Update updates a label. With If-Match, the update only applies if the
+// label is still at one of the given versions.
 // @Summary Update label
 // @Tags labels
 // @Accept json
@@ -117,9 +124,12 @@ func (h *labelHandler) Get(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Label ID"
 // @Param body body dto.LabelUpdateRequest true "Label update request"
+// @Param If-Match header string false "ETag of the version the update is based on"
 // @Success 200 {object} dto.LabelVO
+// @Header 200 {string} ETag "New label version"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The label changed since that version"
 // @Router /labels/{id} [put]
 func (h *labelHandler) Update(c *gin.Context) {
 	id := c.Param("id")
@@ -133,35 +143,106 @@ func (h *labelHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "label not found"})
 		return
 	}
-	if req.Name != nil {
-		label.Name = *req.Name
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &label, ifMatch(c)); err != nil {
+			return err
+		}
+		before := label
+		if req.Name != nil {
+			label.Name = *req.Name
+		}
+		if req.Color != nil {
+			label.Color = *req.Color
+		}
+		return service.SaveLogged(tx, label.UserID, &before, &label)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.First(&label, "id = ?", label.ID)
+	setETag(c, label.Version)
+	var vo dto.LabelVO
+	_ = copier.Copy(&vo, &label)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Patch applies a JSON merge patch to a label.
+// @Summary Patch label
+// @Description Applies an RFC 7396 JSON merge patch (application/merge-patch+json) to the label's name and color. Members set to null are cleared; the patched label must still have a name.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Label ID"
+// @Param body body dto.LabelPatch true "Merge patch"
+// @Param If-Match header string false "ETag of the version the patch is based on"
+// @Success 200 {object} dto.LabelVO
+// @Header 200 {string} ETag "New label version"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The label changed since that version"
+// @Failure 415 {object} map[string]string
+// @Router /labels/{id} [patch]
+func (h *labelHandler) Patch(c *gin.Context) {
+	var label model.Label
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), h.getUserID(c)).First(&label).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "label not found"})
+		return
 	}
-	if req.Color != nil {
-		label.Color = *req.Color
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &label, ifMatch(c)); err != nil {
+			return err
+		}
+		doc := dto.LabelPatch{Name: label.Name, Color: label.Color}
+		if !bindMergePatch(c, &doc) {
+			return errPatchRejected
+		}
+		before := label
+		label.Name, label.Color = doc.Name, doc.Color
+		return service.SaveLogged(tx, label.UserID, &before, &label)
+	})
+	if errors.Is(err, errPatchRejected) {
+		return
 	}
-	if err := h.db.Save(&label).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err != nil {
+		writeServiceError(c, err)
 		return
 	}
+	h.db.First(&label, "id = ?", label.ID)
+	setETag(c, label.Version)
 	var vo dto.LabelVO
 	_ = copier.Copy(&vo, &label)
 	c.JSON(http.StatusOK, vo)
 }
 
-// Delete deletes a label.
+// Delete deletes a label. With If-Match, only if it is still at one of the
+// given versions.
 // @Summary Delete label
 // @Tags labels
 // @Security BearerAuth
 // @Param id path string true "Label ID"
+// @Param If-Match header string false "ETag of the version the delete is based on"
 // @Success 204
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The label changed since that version"
 // @Router /labels/{id} [delete]
 func (h *labelHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
-	result := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).Delete(&model.Label{})
-	if result.RowsAffected == 0 {
+	var label model.Label
+	if err := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).First(&label).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "label not found"})
 		return
 	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &label, ifMatch(c)); err != nil {
+			return err
+		}
+		return service.DeleteLogged(tx, label.UserID, &label)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// mergePatchType is the media type of RFC 7396 JSON Merge Patch documents.
+const mergePatchType = "application/merge-patch+json"
+
+// errPatchRejected aborts a PATCH transaction whose patch bindMergePatch
+// rejected, the response having been written.
+var errPatchRejected = errors.New("patch rejected")
+
+// bindMergePatch applies the RFC 7396 merge patch in the request body to
+// doc, which holds the resource's current editable fields: members set to
+// null are removed, and so come back as their zero value, objects merge
+// recursively and anything else replaces. The merged doc is then validated
+// like a bound request. It answers the request itself and returns false on
+// failure.
+func bindMergePatch(c *gin.Context, doc any) bool {
+	if ct := c.GetHeader("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != mergePatchType && mt != binding.MIMEJSON {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + mergePatchType})
+			return false
+		}
+	}
+	raw, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	var patch any
+	if err := json.Unmarshal(raw, &patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if _, ok := patch.(map[string]any); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be a JSON merge patch object"})
+		return false
+	}
+	if err := applyMergePatch(doc, patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := binding.Validator.ValidateStruct(doc); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// applyMergePatch merges patch into the JSON form of doc and decodes the
+// result back into doc, replacing its contents.
+func applyMergePatch(doc any, patch any) error {
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	var target any
+	if err := json.Unmarshal(current, &target); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+	// Decode into a zeroed doc so that removed members end up zero.
+	reflect.ValueOf(doc).Elem().SetZero()
+	return json.Unmarshal(merged, doc)
+}
+
+// mergePatch implements the MergePatch function of RFC 7396.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for name, v := range p {
+		if v == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], v)
+		}
+	}
+	return t
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterNotificationRoutes registers the notification inbox and preferences.
+func RegisterNotificationRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &notificationHandler{db: db}
+	group := r.Group("/notifications")
+	{
+		group.GET("", h.List)
+		group.GET("/unread-count", h.UnreadCount)
+		group.POST("/read-all", h.MarkAllRead)
+		group.POST("/:id/read", h.MarkRead)
+		group.GET("/preferences", h.ListPreferences)
+		group.PUT("/preferences/:type", h.UpdatePreference)
+	}
+}
+
+type notificationHandler struct {
+	db *gorm.DB
+}
+
+func (h *notificationHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// inbox scopes a query to the in-app notifications of userID.
+func (h *notificationHandler) inbox(userID string) *gorm.DB {
+	return h.db.Model(&model.Notification{}).Where("user_id = ? AND in_app", userID)
+}
+
+// List returns the caller's notifications, newest first.
+// @Summary List notifications
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param type query string false "Only this type (assignment, mention, comment, reminder)"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.NotificationVO
+// @Header 200 {integer} X-Total-Count "Total number of matching notifications"
+// @Header 200 {integer} X-Unread-Count "Unread notifications in the inbox"
+// @Failure 400 {object} map[string]string
+// @Router /notifications [get]
+func (h *notificationHandler) List(c *gin.Context) {
+	userID := h.getUserID(c)
+	q := h.inbox(userID)
+	if c.Query("unread") == "true" {
+		q = q.Where("read_at IS NULL")
+	}
+	if typ := c.Query("type"); typ != "" {
+		if !slices.Contains(model.NotificationTypes, typ) {
+			writeServiceError(c, service.ErrInvalidNotificationType)
+			return
+		}
+		q = q.Where("type = ?", typ)
+	}
+	q = q.Session(&gorm.Session{})
+	limit, offset := parsePage(c)
+	var total, unread int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.inbox(userID).Where("read_at IS NULL").Count(&unread).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var notifications []model.Notification
+	if err := q.Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.NotificationVO, 0, len(notifications))
+	_ = copier.Copy(&vos, &notifications)
+	setTotalCount(c, total)
+	c.Header("X-Unread-Count", strconv.FormatInt(unread, 10))
+	c.JSON(http.StatusOK, vos)
+}
+
+// UnreadCount returns how many of the caller's notifications are unread.
+// @Summary Unread notification count
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.NotificationUnreadVO
+// @Router /notifications/unread-count [get]
+func (h *notificationHandler) UnreadCount(c *gin.Context) {
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	if err := h.inbox(h.getUserID(c)).Where("read_at IS NULL").
+		Select("type, COUNT(*) AS count").Group("type").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vo := dto.NotificationUnreadVO{ByType: make(map[string]int64, len(model.NotificationTypes))}
+	for _, typ := range model.NotificationTypes {
+		vo.ByType[typ] = 0
+	}
+	for _, r := range rows {
+		vo.ByType[r.Type] = r.Count
+		vo.Total += r.Count
+	}
+	c.JSON(http.StatusOK, vo)
+}
+
+// MarkRead marks one notification read.
+// @Summary Mark notification read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} dto.NotificationVO
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/read [post]
+func (h *notificationHandler) MarkRead(c *gin.Context) {
+	n, err := service.FindNotification(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		n.ReadAt = &now
+		if err := h.db.Model(n).Update("read_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	var vo dto.NotificationVO
+	_ = copier.Copy(&vo, n)
+	c.JSON(http.StatusOK, vo)
+}
+
+// MarkAllRead marks every unread notification of the caller read, optionally
+// only those of one type.
+// @Summary Mark all notifications read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Only this type"
+// @Success 200 {object} dto.NotificationReadAllResponse
+// @Failure 400 {object} map[string]string
+// @Router /notifications/read-all [post]
+func (h *notificationHandler) MarkAllRead(c *gin.Context) {
+	q := h.inbox(h.getUserID(c)).Where("read_at IS NULL")
+	if typ := c.Query("type"); typ != "" {
+		if !slices.Contains(model.NotificationTypes, typ) {
+			writeServiceError(c, service.ErrInvalidNotificationType)
+			return
+		}
+		q = q.Where("type = ?", typ)
+	}
+	res := q.Update("read_at", time.Now())
+	if res.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": res.Error.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dto.NotificationReadAllResponse{Updated: res.RowsAffected})
+}
+
+// ListPreferences returns the channels each notification type is delivered on.
+// @Summary List notification preferences
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.NotificationPreferenceVO
+// @Router /notifications/preferences [get]
+func (h *notificationHandler) ListPreferences(c *gin.Context) {
+	prefs, err := service.NotificationPreferences(h.db, h.getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.NotificationPreferenceVO, 0, len(prefs))
+	_ = copier.Copy(&vos, &prefs)
+	c.JSON(http.StatusOK, vos)
+}
+
+// UpdatePreference sets the channels one notification type is delivered on.
+// @Summary Update notification preference
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param type path string true "Notification type (assignment, mention, comment, reminder)"
+// @Param body body dto.NotificationPreferenceUpdateRequest true "Channels"
+// @Success 200 {object} dto.NotificationPreferenceVO
+// @Failure 400 {object} map[string]string
+// @Router /notifications/preferences/{type} [put]
+func (h *notificationHandler) UpdatePreference(c *gin.Context) {
+	var req dto.NotificationPreferenceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pref, err := service.NotificationPreference(h.db, h.getUserID(c), c.Param("type"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if req.InApp != nil {
+		pref.InApp = *req.InApp
+	}
+	if req.Email != nil {
+		pref.Email = *req.Email
+	}
+	if req.Push != nil {
+		pref.Push = *req.Push
+	}
+	if err := service.SaveNotificationPreference(h.db, &pref); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var vo dto.NotificationPreferenceVO
+	_ = copier.Copy(&vo, &pref)
+	c.JSON(http.StatusOK, vo)
+}
@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// parsePage reads the limit/offset query parameters, clamped to sane values.
+func parsePage(c *gin.Context) (limit, offset int) {
+	limit = defaultPageSize
+	if n, err := strconv.Atoi(c.Query("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if n, err := strconv.Atoi(c.Query("offset")); err == nil && n > 0 {
+		offset = n
+	}
+	return limit, offset
+}
+
+// setTotalCount exposes the unpaginated result size to clients.
+func setTotalCount(c *gin.Context, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/todo-tracking-app/web-be/internal/dto"
 	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
 	"github.com/jinzhu/copier"
 )
 
@@ -20,7 +22,10 @@ func RegisterProjectRoutes(r *gin.RouterGroup, db *gorm.DB) {
 		projects.GET("", h.List)
 		projects.POST("", h.Create)
 		projects.GET("/:id", h.Get)
+		projects.GET("/:id/board", h.Board)
+		projects.GET("/:id/board/status", h.StatusBoard)
 		projects.PUT("/:id", h.Update)
+		projects.PATCH("/:id", h.Patch)
 		projects.DELETE("/:id", h.Delete)
 	}
 }
@@ -34,7 +39,7 @@ func (h *projectHandler) getUserID(c *gin.Context) string {
 	return uid.(string)
 }
 
-// List returns all projects for the user.
+// List returns all projects for the user, Inbox first.
 // @Summary List projects
 // @Tags projects
 // @Accept json
@@ -45,8 +50,12 @@ func (h *projectHandler) getUserID(c *gin.Context) string {
 // @Router /projects [get]
 func (h *projectHandler) List(c *gin.Context) {
 	userID := h.getUserID(c)
+	if _, err := service.EnsureInbox(h.db, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	var projects []model.Project
-	if err := h.db.Where("user_id = ?", userID).Find(&projects).Error; err != nil {
+	if err := h.db.Where("user_id = ?", userID).Order("is_inbox DESC, created_at").Find(&projects).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -78,10 +87,11 @@ func (h *projectHandler) Create(c *gin.Context) {
 		Color:  req.Color,
 		UserID: h.getUserID(c),
 	}
-	if err := h.db.Create(&proj).Error; err != nil {
+	if err := service.CreateLogged(h.db, proj.UserID, &proj); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.db.First(&proj, "id = ?", proj.ID)
 	var vo dto.ProjectVO
 	_ = copier.Copy(&vo, &proj)
 	c.JSON(http.StatusCreated, vo)
@@ -95,6 +105,7 @@ func (h *projectHandler) Create(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Project ID"
 // @Success 200 {object} dto.ProjectVO
+// @Header 200 {string} ETag "Project version, for If-Match"
 // @Failure 404 {object} map[string]string
 // @Router /projects/{id} [get]
 func (h *projectHandler) Get(c *gin.Context) {
@@ -106,10 +117,126 @@ func (h *projectHandler) Get(c *gin.Context) {
 	}
 	var vo dto.ProjectVO
 	_ = copier.Copy(&vo, &proj)
+	setETag(c, proj.Version)
 	c.JSON(http.StatusOK, vo)
 }
 
-// Update updates a project.
+// Board returns a project's tasks grouped by section, in board order.
+// @Summary Get project board
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.BoardVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/board [get]
+func (h *projectHandler) Board(c *gin.Context) {
+	id := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var proj model.Project
+	if err := h.db.Where("id = ?", id).First(&proj).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+	var sections []model.Section
+	if err := h.db.Where("project_id = ?", id).Order("sort_key").Find(&sections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var tasks []model.Task
+	if err := h.db.Where("project_id = ?", id).Order("sort_key, created_at").
+		Preload("Labels").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	board := dto.BoardVO{Unsectioned: []dto.TaskVO{}, Sections: []dto.BoardSectionVO{}}
+	_ = copier.Copy(&board.Project, &proj)
+	index := make(map[string]int, len(sections))
+	for i, sec := range sections {
+		bs := dto.BoardSectionVO{Tasks: []dto.TaskVO{}}
+		_ = copier.Copy(&bs.SectionVO, &sec)
+		board.Sections = append(board.Sections, bs)
+		index[sec.ID] = i
+	}
+	for _, t := range tasks {
+		if t.SectionID != nil {
+			if i, ok := index[*t.SectionID]; ok {
+				board.Sections[i].Tasks = append(board.Sections[i].Tasks, taskToVO(t))
+				continue
+			}
+		}
+		board.Unsectioned = append(board.Unsectioned, taskToVO(t))
+	}
+	attachBlockers(h.db, board.Unsectioned)
+	for i := range board.Sections {
+		attachBlockers(h.db, board.Sections[i].Tasks)
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+// StatusBoard returns a project's tasks grouped into status columns, including
+// the project's custom statuses and their WIP limits.
+// @Summary Get project status board
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} dto.StatusBoardVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/board/status [get]
+func (h *projectHandler) StatusBoard(c *gin.Context) {
+	id := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), id); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var proj model.Project
+	if err := h.db.Where("id = ?", id).First(&proj).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+	columns, err := service.StatusColumns(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var tasks []model.Task
+	if err := h.db.Where("project_id = ?", id).Order("sort_key, created_at").
+		Preload("Labels").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	board := dto.StatusBoardVO{Columns: []dto.StatusColumnVO{}}
+	_ = copier.Copy(&board.Project, &proj)
+	index := make(map[string]int, len(columns))
+	for i, col := range columns {
+		sc := dto.StatusColumnVO{Tasks: []dto.TaskVO{}}
+		_ = copier.Copy(&sc.ProjectStatusVO, &col)
+		board.Columns = append(board.Columns, sc)
+		index[col.Key] = i
+	}
+	for _, t := range tasks {
+		i, ok := index[t.Status]
+		if !ok {
+			i = index[model.TaskStatusPending]
+		}
+		board.Columns[i].Tasks = append(board.Columns[i].Tasks, taskToVO(t))
+	}
+	for i := range board.Columns {
+		attachBlockers(h.db, board.Columns[i].Tasks)
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+// Update updates a project. With If-Match, the update only applies if the
+// project is still at one of the given versions.
 // @Summary Update project
 // @Tags projects
 // @Accept json
@@ -117,9 +244,12 @@ func (h *projectHandler) Get(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Project ID"
 // @Param body body dto.ProjectUpdateRequest true "Project update request"
+// @Param If-Match header string false "ETag of the version the update is based on"
 // @Success 200 {object} dto.ProjectVO
+// @Header 200 {string} ETag "New project version"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The project changed since that version"
 // @Router /projects/{id} [put]
 func (h *projectHandler) Update(c *gin.Context) {
 	id := c.Param("id")
@@ -128,40 +258,118 @@ func (h *projectHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	userID := h.getUserID(c)
 	var proj model.Project
-	if err := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).First(&proj).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&proj).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
 		return
 	}
-	if req.Name != nil {
-		proj.Name = *req.Name
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &proj, ifMatch(c)); err != nil {
+			return err
+		}
+		before := proj
+		if req.Name != nil {
+			proj.Name = *req.Name
+		}
+		if req.Color != nil {
+			proj.Color = *req.Color
+		}
+		return service.SaveLogged(tx, userID, &before, &proj)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.First(&proj, "id = ?", proj.ID)
+	setETag(c, proj.Version)
+	var vo dto.ProjectVO
+	_ = copier.Copy(&vo, &proj)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Patch applies a JSON merge patch to a project.
+// @Summary Patch project
+// @Description Applies an RFC 7396 JSON merge patch (application/merge-patch+json) to the project's name and color. Members set to null are cleared; the patched project must still have a name.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param body body dto.ProjectPatch true "Merge patch"
+// @Param If-Match header string false "ETag of the version the patch is based on"
+// @Success 200 {object} dto.ProjectVO
+// @Header 200 {string} ETag "New project version"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The project changed since that version"
+// @Failure 415 {object} map[string]string
+// @Router /projects/{id} [patch]
+func (h *projectHandler) Patch(c *gin.Context) {
+	userID := h.getUserID(c)
+	var proj model.Project
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&proj).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
 	}
-	if req.Color != nil {
-		proj.Color = *req.Color
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &proj, ifMatch(c)); err != nil {
+			return err
+		}
+		doc := dto.ProjectPatch{Name: proj.Name, Color: proj.Color}
+		if !bindMergePatch(c, &doc) {
+			return errPatchRejected
+		}
+		before := proj
+		proj.Name, proj.Color = doc.Name, doc.Color
+		return service.SaveLogged(tx, userID, &before, &proj)
+	})
+	if errors.Is(err, errPatchRejected) {
+		return
 	}
-	if err := h.db.Save(&proj).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err != nil {
+		writeServiceError(c, err)
 		return
 	}
+	h.db.First(&proj, "id = ?", proj.ID)
+	setETag(c, proj.Version)
 	var vo dto.ProjectVO
 	_ = copier.Copy(&vo, &proj)
 	c.JSON(http.StatusOK, vo)
 }
 
-// Delete deletes a project.
+// Delete deletes a project. The Inbox project cannot be deleted. With
+// If-Match, only if it is still at one of the given versions.
 // @Summary Delete project
 // @Tags projects
 // @Security BearerAuth
 // @Param id path string true "Project ID"
+// @Param If-Match header string false "ETag of the version the delete is based on"
 // @Success 204
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The project changed since that version"
 // @Router /projects/{id} [delete]
 func (h *projectHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
-	result := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).Delete(&model.Project{})
-	if result.RowsAffected == 0 {
+	var proj model.Project
+	if err := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).First(&proj).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
 		return
 	}
+	if proj.IsInbox {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "inbox project cannot be deleted"})
+		return
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &proj, ifMatch(c)); err != nil {
+			return err
+		}
+		return service.DeleteLogged(tx, proj.UserID, &proj)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
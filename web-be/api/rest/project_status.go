@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterProjectStatusRoutes registers status column routes nested under a project.
+func RegisterProjectStatusRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &projectStatusHandler{db: db}
+	statuses := r.Group("/projects/:id/statuses")
+	{
+		statuses.GET("", h.List)
+		statuses.POST("", h.Create)
+		statuses.PUT("/:statusId", h.Update)
+		statuses.DELETE("/:statusId", h.Delete)
+	}
+}
+
+type projectStatusHandler struct {
+	db *gorm.DB
+}
+
+func (h *projectStatusHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+func (h *projectStatusHandler) checkProject(c *gin.Context) (string, bool) {
+	projectID := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), projectID); err != nil {
+		writeServiceError(c, err)
+		return "", false
+	}
+	return projectID, true
+}
+
+func (h *projectStatusHandler) findStatus(c *gin.Context, projectID string) (*model.ProjectStatus, bool) {
+	var ps model.ProjectStatus
+	if err := h.db.Where("id = ? AND project_id = ?", c.Param("statusId"), projectID).First(&ps).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "status not found"})
+		return nil, false
+	}
+	return &ps, true
+}
+
+// List returns the status columns of a project, built-ins included.
+// @Summary List project statuses
+// @Tags statuses
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {array} dto.ProjectStatusVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/statuses [get]
+func (h *projectStatusHandler) List(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	columns, err := service.StatusColumns(h.db, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vos []dto.ProjectStatusVO
+	_ = copier.Copy(&vos, &columns)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Create adds a custom status column, or sets the name/WIP limit of a built-in one.
+// @Summary Create project status
+// @Tags statuses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param body body dto.ProjectStatusCreateRequest true "Project status create request"
+// @Success 201 {object} dto.ProjectStatusVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /projects/{id}/statuses [post]
+func (h *projectStatusHandler) Create(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var req dto.ProjectStatusCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ps := model.ProjectStatus{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		Key:       req.Key,
+		Name:      req.Name,
+		Category:  req.Category,
+		WIPLimit:  req.WIPLimit,
+	}
+	if ps.Name == "" {
+		ps.Name = ps.Key
+	}
+	if err := service.ValidateProjectStatus(&ps); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var count int64
+	h.db.Model(&model.ProjectStatus{}).Where("project_id = ? AND key = ?", projectID, ps.Key).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "status key already exists"})
+		return
+	}
+	if err := service.CreateLogged(h.db, h.getUserID(c), &ps); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vo dto.ProjectStatusVO
+	_ = copier.Copy(&vo, &ps)
+	c.JSON(http.StatusCreated, vo)
+}
+
+// Update changes the name or WIP limit of a status column.
+// @Summary Update project status
+// @Tags statuses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param statusId path string true "Status ID"
+// @Param body body dto.ProjectStatusUpdateRequest true "Project status update request"
+// @Success 200 {object} dto.ProjectStatusVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/statuses/{statusId} [put]
+func (h *projectStatusHandler) Update(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var req dto.ProjectStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ps, ok := h.findStatus(c, projectID)
+	if !ok {
+		return
+	}
+	before := *ps
+	if req.Name != nil {
+		ps.Name = *req.Name
+	}
+	if req.WIPLimit != nil {
+		ps.WIPLimit = *req.WIPLimit
+	}
+	if err := service.ValidateProjectStatus(ps); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.SaveLogged(h.db, h.getUserID(c), &before, ps); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vo dto.ProjectStatusVO
+	_ = copier.Copy(&vo, ps)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Delete removes a status column. Tasks in a removed custom status fall back
+// to its category.
+// @Summary Delete project status
+// @Tags statuses
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param statusId path string true "Status ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/statuses/{statusId} [delete]
+func (h *projectStatusHandler) Delete(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	ps, ok := h.findStatus(c, projectID)
+	if !ok {
+		return
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if ps.Key != ps.Category {
+			if err := tx.Model(&model.Task{}).Where("project_id = ? AND status = ?", projectID, ps.Key).
+				Update("status", ps.Category).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(ps).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, h.getUserID(c), ps)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
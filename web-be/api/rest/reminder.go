@@ -0,0 +1,194 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterReminderRoutes registers reminder routes nested under a task.
+func RegisterReminderRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &reminderHandler{db: db}
+	group := r.Group("/tasks/:id/reminders")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Create)
+		group.DELETE("/:reminderId", h.Delete)
+		group.POST("/:reminderId/snooze", h.Snooze)
+	}
+}
+
+type reminderHandler struct {
+	db *gorm.DB
+}
+
+func (h *reminderHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns the caller's reminders on a task with their delivery history,
+// soonest first.
+// @Summary List reminders
+// @Tags reminders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {array} dto.ReminderVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/reminders [get]
+func (h *reminderHandler) List(c *gin.Context) {
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	var reminders []model.Reminder
+	if err := h.db.Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).Where("task_id = ? AND user_id = ?", task.ID, userID).Order("remind_at, id").Find(&reminders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.ReminderVO, 0, len(reminders))
+	for i := range reminders {
+		vos = append(vos, reminderToVO(&reminders[i]))
+	}
+	c.JSON(http.StatusOK, vos)
+}
+
+// Create schedules an additional reminder on a task for the caller.
+// @Summary Add reminder
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.ReminderCreateRequest true "Reminder"
+// @Success 201 {object} dto.ReminderVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/reminders [post]
+func (h *reminderHandler) Create(c *gin.Context) {
+	var req dto.ReminderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	r, err := service.AddReminder(h.db, userID, task, req.RemindAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, reminderToVO(r))
+}
+
+// Delete removes one of the caller's reminders. The reminder mirroring the
+// task's reminder_at is removed by clearing reminder_at on the task.
+// @Summary Delete reminder
+// @Tags reminders
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param reminderId path string true "Reminder ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "The reminder mirrors the task's reminder_at"
+// @Router /tasks/{id}/reminders/{reminderId} [delete]
+func (h *reminderHandler) Delete(c *gin.Context) {
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	r, err := service.FindReminder(h.db, task.ID, userID, c.Param("reminderId"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.DeleteReminder(h.db, r); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Snooze re-arms a reminder, whether pending or already sent, to fire again
+// after the given number of minutes or at the given time.
+// @Summary Snooze reminder
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param reminderId path string true "Reminder ID"
+// @Param body body dto.ReminderSnoozeRequest true "Snooze duration or time"
+// @Success 200 {object} dto.ReminderVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/reminders/{reminderId}/snooze [post]
+func (h *reminderHandler) Snooze(c *gin.Context) {
+	var req dto.ReminderSnoozeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	var until time.Time
+	switch {
+	case req.Until != nil && req.Minutes == 0:
+		if !req.Until.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be in the future"})
+			return
+		}
+		until = *req.Until
+	case req.Until == nil && req.Minutes > 0:
+		until = now.Add(time.Duration(req.Minutes) * time.Minute)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set exactly one of minutes or until"})
+		return
+	}
+	userID := h.getUserID(c)
+	task, err := service.CheckTaskAccess(h.db, userID, c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	r, err := service.FindReminder(h.db, task.ID, userID, c.Param("reminderId"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.SnoozeReminder(h.db, r, until); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reminderToVO(r))
+}
+
+func reminderToVO(r *model.Reminder) dto.ReminderVO {
+	var vo dto.ReminderVO
+	_ = copier.Copy(&vo, r)
+	if vo.Deliveries == nil {
+		vo.Deliveries = []dto.ReminderDeliveryVO{}
+	}
+	return vo
+}
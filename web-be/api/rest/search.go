@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterSearchRoutes registers full-text search.
+func RegisterSearchRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &searchHandler{db: db}
+	r.GET("/search", h.Search)
+}
+
+type searchHandler struct {
+	db *gorm.DB
+}
+
+func (h *searchHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// Search finds tasks, subtasks and comments the caller can see.
+// @Summary Search
+// @Description Searches the titles and descriptions of tasks, the titles of subtasks and the bodies of comments, in the caller's tasks and in projects they own or are a member of. Every word of q must match, by prefix, as written or in stemmed English form. Text in Chinese (or Japanese or Korean), and queries nothing matches that way, are matched as substrings or by trigram similarity instead. Results are ranked best first, with a snippet of the matching text in which matches are wrapped in <mark></mark>; the text is HTML-escaped. project_id, label_id and status keep only tasks, and the subtasks and comments of tasks, that match; project_id also keeps comments on that project.
+// @Tags search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Text to look for"
+// @Param type query string false "Comma-separated kinds to return: task, subtask, comment (default all)"
+// @Param project_id query string false "Only in this project"
+// @Param label_id query string false "Only tasks with this label"
+// @Param status query string false "Only tasks with this status"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.SearchResultVO
+// @Header 200 {integer} X-Total-Count "Total number of results"
+// @Failure 400 {object} map[string]string
+// @Router /search [get]
+func (h *searchHandler) Search(c *gin.Context) {
+	limit, offset := parsePage(c)
+	q := service.SearchQuery{
+		Text:      c.Query("q"),
+		ProjectID: c.Query("project_id"),
+		LabelID:   c.Query("label_id"),
+		Status:    c.Query("status"),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	if kinds := c.Query("type"); kinds != "" {
+		q.Kinds = strings.Split(kinds, ",")
+	}
+	hits, total, err := service.Search(h.db, h.getUserID(c), q)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	vos := make([]dto.SearchResultVO, len(hits))
+	for i, hit := range hits {
+		vos[i] = dto.SearchResultVO{
+			Type:      hit.Kind,
+			ID:        hit.ID,
+			TaskID:    hit.TaskID,
+			ProjectID: hit.ProjectID,
+			Title:     hit.Title,
+			Snippet:   hit.Snippet,
+			Rank:      hit.Rank,
+			UpdatedAt: hit.UpdatedAt,
+		}
+	}
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, vos)
+}
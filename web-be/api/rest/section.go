@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterSectionRoutes registers section routes nested under a project.
+func RegisterSectionRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &sectionHandler{db: db}
+	sections := r.Group("/projects/:id/sections")
+	{
+		sections.GET("", h.List)
+		sections.POST("", h.Create)
+		sections.PUT("/:sectionId", h.Update)
+		sections.DELETE("/:sectionId", h.Delete)
+		sections.POST("/:sectionId/move", h.Move)
+	}
+}
+
+type sectionHandler struct {
+	db *gorm.DB
+}
+
+func (h *sectionHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// checkProject verifies the caller can access the project in the URL and
+// writes the error response when not.
+func (h *sectionHandler) checkProject(c *gin.Context) (string, bool) {
+	projectID := c.Param("id")
+	if err := service.CheckProjectAccess(h.db, h.getUserID(c), projectID); err != nil {
+		writeServiceError(c, err)
+		return "", false
+	}
+	return projectID, true
+}
+
+func (h *sectionHandler) findSection(c *gin.Context, projectID string) (*model.Section, bool) {
+	var section model.Section
+	if err := h.db.Where("id = ? AND project_id = ?", c.Param("sectionId"), projectID).First(&section).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "section not found"})
+		return nil, false
+	}
+	return &section, true
+}
+
+// List returns the sections of a project in board order.
+// @Summary List sections
+// @Tags sections
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {array} dto.SectionVO
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/sections [get]
+func (h *sectionHandler) List(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var sections []model.Section
+	if err := h.db.Where("project_id = ?", projectID).Order("sort_key").Find(&sections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vos []dto.SectionVO
+	_ = copier.Copy(&vos, &sections)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Create appends a new section to a project.
+// @Summary Create section
+// @Tags sections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param body body dto.SectionCreateRequest true "Section create request"
+// @Success 201 {object} dto.SectionVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/sections [post]
+func (h *sectionHandler) Create(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var req dto.SectionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	key, err := service.SectionSortKey(h.db, "", projectID, "", "")
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	section := model.Section{
+		ID:        uuid.New().String(),
+		ProjectID: projectID,
+		Name:      req.Name,
+		SortKey:   key,
+	}
+	if err := service.CreateLogged(h.db, h.getUserID(c), &section); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vo dto.SectionVO
+	_ = copier.Copy(&vo, &section)
+	c.JSON(http.StatusCreated, vo)
+}
+
+// Update renames a section.
+// @Summary Update section
+// @Tags sections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param sectionId path string true "Section ID"
+// @Param body body dto.SectionUpdateRequest true "Section update request"
+// @Success 200 {object} dto.SectionVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/sections/{sectionId} [put]
+func (h *sectionHandler) Update(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var req dto.SectionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	section, ok := h.findSection(c, projectID)
+	if !ok {
+		return
+	}
+	before := *section
+	if req.Name != nil {
+		section.Name = *req.Name
+	}
+	if err := service.SaveLogged(h.db, h.getUserID(c), &before, section); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vo dto.SectionVO
+	_ = copier.Copy(&vo, section)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Move places a section between two sibling sections.
+// @Summary Move section
+// @Tags sections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param sectionId path string true "Section ID"
+// @Param body body dto.SectionMoveRequest true "Section move request"
+// @Success 200 {object} dto.SectionVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/sections/{sectionId}/move [post]
+func (h *sectionHandler) Move(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	var req dto.SectionMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	section, ok := h.findSection(c, projectID)
+	if !ok {
+		return
+	}
+	key, err := service.SectionSortKey(h.db, section.ID, projectID, req.AfterID, req.BeforeID)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	before := *section
+	section.SortKey = key
+	if err := service.SaveLogged(h.db, h.getUserID(c), &before, section); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var vo dto.SectionVO
+	_ = copier.Copy(&vo, section)
+	c.JSON(http.StatusOK, vo)
+}
+
+// Delete deletes a section. Its tasks stay in the project without a section.
+// @Summary Delete section
+// @Tags sections
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param sectionId path string true "Section ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /projects/{id}/sections/{sectionId} [delete]
+func (h *sectionHandler) Delete(c *gin.Context) {
+	projectID, ok := h.checkProject(c)
+	if !ok {
+		return
+	}
+	section, ok := h.findSection(c, projectID)
+	if !ok {
+		return
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Task{}).Where("section_id = ?", section.ID).
+			Update("section_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(section).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, h.getUserID(c), section)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
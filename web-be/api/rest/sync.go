@@ -0,0 +1,388 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// Sync page sizes, in rows over all entity kinds.
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 2000
+)
+
+// Outcomes of a sync mutation or a task batch item.
+const (
+	syncApplied    = "applied"
+	syncFailed     = "failed"
+	syncRolledBack = "rolled_back"
+)
+
+// errSyncRejected aborts the POST /sync transaction when a mutation failed.
+var errSyncRejected = errors.New("sync batch rejected")
+
+// RegisterSyncRoutes registers the delta sync API for offline clients.
+func RegisterSyncRoutes(r *gin.RouterGroup, db *gorm.DB, statuses *service.TaskStatusMachine, revisions *service.TaskRevisions) {
+	h := &syncHandler{db: db, tasks: &taskHandler{db: db, statuses: statuses, revisions: revisions}}
+	r.GET("/sync", h.Pull)
+	r.POST("/sync", h.Push)
+}
+
+type syncHandler struct {
+	db    *gorm.DB
+	tasks *taskHandler
+}
+
+func (h *syncHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// Pull returns the caller's tasks, projects, labels and subtasks changed
+// since a sync token.
+// @Summary Pull changes
+// @Description Without since, returns everything (deleted rows left out) as a full sync. With since, returns the rows created, updated or deleted after that token; deleted rows carry deleted_at. Store the returned token and pass it as since next time; while has_more is set, fetch again right away. Rows may be sent more than once, so apply them as upserts.
+// @Tags sync
+// @Produce json
+// @Security BearerAuth
+// @Param since query string false "Token from the previous sync"
+// @Param limit query int false "Maximum rows per page" default(500)
+// @Success 200 {object} dto.SyncChangesVO
+// @Failure 400 {object} map[string]string
+// @Router /sync [get]
+func (h *syncHandler) Pull(c *gin.Context) {
+	since, full := int64(0), true
+	if token, ok := c.GetQuery("since"); ok {
+		var err error
+		if since, err = service.ParseSyncToken(token); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		full = false
+	}
+	limit := defaultSyncLimit
+	if v := c.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = min(n, maxSyncLimit)
+		}
+	}
+	changes, err := service.ChangesSince(h.db, h.getUserID(c), since, full, limit)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	vo := dto.SyncChangesVO{
+		Token:    service.FormatSyncToken(changes.Token),
+		HasMore:  changes.HasMore,
+		Tasks:    make([]dto.SyncTaskVO, 0, len(changes.Tasks)),
+		Projects: make([]dto.SyncProjectVO, 0, len(changes.Projects)),
+		Labels:   make([]dto.SyncLabelVO, 0, len(changes.Labels)),
+		Subtasks: make([]dto.SyncSubtaskVO, 0, len(changes.Subtasks)),
+	}
+	for _, t := range changes.Tasks {
+		vo.Tasks = append(vo.Tasks, dto.SyncTaskVO{TaskVO: taskToVO(t), DeletedAt: deletedAt(t.DeletedAt)})
+	}
+	for _, p := range changes.Projects {
+		item := dto.SyncProjectVO{UpdatedAt: p.UpdatedAt, DeletedAt: deletedAt(p.DeletedAt)}
+		_ = copier.Copy(&item.ProjectVO, &p)
+		vo.Projects = append(vo.Projects, item)
+	}
+	for _, l := range changes.Labels {
+		item := dto.SyncLabelVO{UpdatedAt: l.UpdatedAt, DeletedAt: deletedAt(l.DeletedAt)}
+		_ = copier.Copy(&item.LabelVO, &l)
+		vo.Labels = append(vo.Labels, item)
+	}
+	for _, s := range changes.Subtasks {
+		item := dto.SyncSubtaskVO{DeletedAt: deletedAt(s.DeletedAt)}
+		_ = copier.Copy(&item.SubtaskVO, &s)
+		vo.Subtasks = append(vo.Subtasks, item)
+	}
+	c.JSON(http.StatusOK, vo)
+}
+
+// Push applies a batch of client mutations in one transaction.
+// @Summary Push changes
+// @Description Applies the mutations in order, all or none. Creates use the client-generated id (a UUID); repeating a create or delete that was already applied is a no-op, so a batch can be retried safely. When any mutation fails nothing is applied and the response is 422 with the failing mutations marked failed and the others rolled_back.
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.SyncPushRequest true "Mutations"
+// @Success 200 {object} dto.SyncPushResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} dto.SyncPushResponse
+// @Router /sync [post]
+func (h *syncHandler) Push(c *gin.Context) {
+	var req dto.SyncPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	results := make([]dto.SyncResultVO, len(req.Mutations))
+	failed := false
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for i, m := range req.Mutations {
+			results[i] = dto.SyncResultVO{ID: m.ID, Op: m.Op, Entity: m.Entity, Status: syncApplied}
+			// Each mutation runs in a savepoint so the rest can still be
+			// checked after one fails.
+			if err := tx.Transaction(func(tx *gorm.DB) error { return h.apply(tx, userID, m) }); err != nil {
+				if serviceStatus(err) == http.StatusInternalServerError {
+					return err
+				}
+				failed = true
+				results[i].Status = syncFailed
+				results[i].Code = serviceStatus(err)
+				results[i].Error = err.Error()
+			}
+		}
+		if failed {
+			return errSyncRejected
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSyncRejected) {
+		writeServiceError(c, err)
+		return
+	}
+	if failed {
+		for i := range results {
+			if results[i].Status == syncApplied {
+				results[i].Status = syncRolledBack
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, dto.SyncPushResponse{Applied: false, Results: results})
+		return
+	}
+	c.JSON(http.StatusOK, dto.SyncPushResponse{Applied: true, Results: results})
+}
+
+// apply performs one mutation in tx with the same rules as the entity's
+// REST endpoint.
+func (h *syncHandler) apply(tx *gorm.DB, userID string, m dto.SyncMutation) error {
+	switch m.Entity + "." + m.Op {
+	case "task.create":
+		var req dto.TaskCreateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		if exists, err := service.ClaimID(tx, &model.Task{}, m.ID, "user_id = ?", userID); err != nil || exists {
+			return err
+		}
+		_, err := h.tasks.create(tx, userID, m.ID, req)
+		return err
+	case "task.update":
+		var req dto.TaskUpdateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		var task model.Task
+		if err := loadOwned(tx, &task, m.ID, false, service.ErrTaskNotFound, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := service.CheckVersion(tx, &task, expectedVersion(m)); err != nil {
+			return err
+		}
+		return h.tasks.update(tx, userID, &task, req, false)
+	case "task.delete":
+		var task model.Task
+		if err := loadOwned(tx, &task, m.ID, true, service.ErrTaskNotFound, "user_id = ?", userID); err != nil || task.DeletedAt.Valid {
+			return err
+		}
+		if err := service.CheckVersion(tx, &task, expectedVersion(m)); err != nil {
+			return err
+		}
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, userID, &task)
+
+	case "project.create":
+		var req dto.ProjectCreateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		if exists, err := service.ClaimID(tx, &model.Project{}, m.ID, "user_id = ?", userID); err != nil || exists {
+			return err
+		}
+		return service.CreateLogged(tx, userID, &model.Project{ID: m.ID, Name: req.Name, Color: req.Color, UserID: userID})
+	case "project.update":
+		var req dto.ProjectUpdateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		var proj model.Project
+		if err := loadOwned(tx, &proj, m.ID, false, service.ErrProjectNotFound, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := service.CheckVersion(tx, &proj, expectedVersion(m)); err != nil {
+			return err
+		}
+		before := proj
+		if req.Name != nil {
+			proj.Name = *req.Name
+		}
+		if req.Color != nil {
+			proj.Color = *req.Color
+		}
+		return service.SaveLogged(tx, userID, &before, &proj)
+	case "project.delete":
+		var proj model.Project
+		if err := loadOwned(tx, &proj, m.ID, true, service.ErrProjectNotFound, "user_id = ?", userID); err != nil || proj.DeletedAt.Valid {
+			return err
+		}
+		if err := service.CheckVersion(tx, &proj, expectedVersion(m)); err != nil {
+			return err
+		}
+		if proj.IsInbox {
+			return fmt.Errorf("%w: inbox project cannot be deleted", service.ErrInvalidMutation)
+		}
+		return service.DeleteLogged(tx, userID, &proj)
+
+	case "label.create":
+		var req dto.LabelCreateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		if exists, err := service.ClaimID(tx, &model.Label{}, m.ID, "user_id = ?", userID); err != nil || exists {
+			return err
+		}
+		return service.CreateLogged(tx, userID, &model.Label{ID: m.ID, Name: req.Name, Color: req.Color, UserID: userID})
+	case "label.update":
+		var req dto.LabelUpdateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		var label model.Label
+		if err := loadOwned(tx, &label, m.ID, false, service.ErrLabelNotFound, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := service.CheckVersion(tx, &label, expectedVersion(m)); err != nil {
+			return err
+		}
+		before := label
+		if req.Name != nil {
+			label.Name = *req.Name
+		}
+		if req.Color != nil {
+			label.Color = *req.Color
+		}
+		return service.SaveLogged(tx, userID, &before, &label)
+	case "label.delete":
+		var label model.Label
+		if err := loadOwned(tx, &label, m.ID, true, service.ErrLabelNotFound, "user_id = ?", userID); err != nil || label.DeletedAt.Valid {
+			return err
+		}
+		if err := service.CheckVersion(tx, &label, expectedVersion(m)); err != nil {
+			return err
+		}
+		return service.DeleteLogged(tx, userID, &label)
+
+	case "subtask.create":
+		var req dto.SubtaskCreateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		if exists, err := service.ClaimID(tx, &model.Subtask{}, m.ID, "task_id IN (SELECT id FROM tasks WHERE user_id = ?)", userID); err != nil || exists {
+			return err
+		}
+		var task model.Task
+		if err := loadOwned(tx, &task, req.TaskID, false, service.ErrTaskNotFound, "user_id = ?", userID); err != nil {
+			return err
+		}
+		return service.CreateLogged(tx, userID, &model.Subtask{ID: m.ID, TaskID: task.ID, Title: req.Title, Completed: req.Completed})
+	case "subtask.update":
+		var req dto.SubtaskUpdateRequest
+		if err := decodeMutation(m, &req); err != nil {
+			return err
+		}
+		sub, err := service.FindSubtask(tx, userID, m.ID)
+		if err != nil {
+			return err
+		}
+		if err := service.CheckVersion(tx, sub, expectedVersion(m)); err != nil {
+			return err
+		}
+		before := *sub
+		if req.Title != nil {
+			sub.Title = *req.Title
+		}
+		if req.Completed != nil {
+			sub.Completed = *req.Completed
+		}
+		return service.SaveLogged(tx, userID, &before, sub)
+	case "subtask.delete":
+		var sub model.Subtask
+		if err := loadOwned(tx, &sub, m.ID, true, service.ErrSubtaskNotFound,
+			"task_id IN (SELECT id FROM tasks WHERE user_id = ?)", userID); err != nil || sub.DeletedAt.Valid {
+			return err
+		}
+		if err := service.CheckVersion(tx, &sub, expectedVersion(m)); err != nil {
+			return err
+		}
+		return service.DeleteLogged(tx, userID, &sub)
+	}
+	return fmt.Errorf("%w: unsupported %s on %s", service.ErrInvalidMutation, m.Op, m.Entity)
+}
+
+// decodeMutation decodes and validates the data of m into req.
+func decodeMutation(m dto.SyncMutation, req any) error {
+	data := m.Data
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(data, req); err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidMutation, err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidMutation, err)
+	}
+	return nil
+}
+
+// expectedVersion returns the versions an update or delete mutation may
+// apply to, for service.CheckVersion.
+func expectedVersion(m dto.SyncMutation) []int {
+	if m.ExpectedVersion == nil {
+		return nil
+	}
+	return []int{*m.ExpectedVersion}
+}
+
+// loadOwned loads row id into dst if it matches the owner condition. With
+// withDeleted, soft-deleted rows are found too.
+func loadOwned(tx *gorm.DB, dst any, id string, withDeleted bool, notFound error, owner string, args ...any) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return notFound
+	}
+	q := tx
+	if withDeleted {
+		q = q.Unscoped()
+	}
+	err := q.Where("id = ?", id).Where(owner, args...).First(dst).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
+
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
@@ -1,7 +1,9 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
@@ -11,26 +13,40 @@ import (
 
 	"github.com/todo-tracking-app/web-be/internal/dto"
 	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
 	"github.com/jinzhu/copier"
 )
 
 // RegisterTaskRoutes registers task routes.
-func RegisterTaskRoutes(r *gin.RouterGroup, db *gorm.DB) {
-	h := &taskHandler{db: db}
+func RegisterTaskRoutes(r *gin.RouterGroup, db *gorm.DB, statuses *service.TaskStatusMachine, revisions *service.TaskRevisions) {
+	h := &taskHandler{db: db, statuses: statuses, revisions: revisions}
 	tasks := r.Group("/tasks")
 	{
 		tasks.GET("", h.List)
 		tasks.GET("/today", h.Today)
 		tasks.GET("/upcoming", h.Upcoming)
 		tasks.POST("", h.Create)
+		tasks.POST("/batch", h.Batch)
+		tasks.POST("/quick", h.QuickAdd)
 		tasks.GET("/:id", h.Get)
 		tasks.PUT("/:id", h.Update)
+		tasks.PATCH("/:id", h.Patch)
+		tasks.POST("/:id/move", h.Move)
+		tasks.POST("/:id/reorder", h.Reorder)
+		tasks.POST("/:id/complete", h.Complete)
+		tasks.POST("/:id/reopen", h.Reopen)
+		tasks.POST("/:id/blockers", h.AddBlocker)
+		tasks.DELETE("/:id/blockers/:blockerId", h.RemoveBlocker)
+		tasks.GET("/:id/revisions", h.Revisions)
+		tasks.POST("/:id/revisions/:rev/restore", h.RestoreRevision)
 		tasks.DELETE("/:id", h.Delete)
 	}
 }
 
 type taskHandler struct {
-	db *gorm.DB
+	db        *gorm.DB
+	statuses  *service.TaskStatusMachine
+	revisions *service.TaskRevisions
 }
 
 func (h *taskHandler) getUserID(c *gin.Context) string {
@@ -66,6 +82,7 @@ func (h *taskHandler) List(c *gin.Context) {
 		vo := taskToVO(t)
 		vos = append(vos, vo)
 	}
+	attachBlockers(h.db, vos)
 	c.JSON(http.StatusOK, vos)
 }
 
@@ -85,8 +102,8 @@ func (h *taskHandler) Today(c *gin.Context) {
 	end := start.Add(24 * time.Hour)
 
 	var tasks []model.Task
-	if err := h.db.Where("user_id = ? AND due_date >= ? AND due_date < ? AND status != ?",
-		userID, start, end, model.TaskStatusCompleted).Preload("Labels").Find(&tasks).Error; err != nil {
+	if err := h.db.Where("user_id = ? AND due_date >= ? AND due_date < ? AND completed_at IS NULL",
+		userID, start, end).Preload("Labels").Find(&tasks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -94,6 +111,7 @@ func (h *taskHandler) Today(c *gin.Context) {
 	for _, t := range tasks {
 		vos = append(vos, taskToVO(t))
 	}
+	attachBlockers(h.db, vos)
 	c.JSON(http.StatusOK, vos)
 }
 
@@ -119,8 +137,8 @@ func (h *taskHandler) Upcoming(c *gin.Context) {
 	end := now.AddDate(0, 0, days)
 
 	var tasks []model.Task
-	if err := h.db.Where("user_id = ? AND due_date > ? AND due_date <= ? AND status != ?",
-		userID, now, end, model.TaskStatusCompleted).Preload("Labels").Find(&tasks).Error; err != nil {
+	if err := h.db.Where("user_id = ? AND due_date > ? AND due_date <= ? AND completed_at IS NULL",
+		userID, now, end).Preload("Labels").Find(&tasks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -128,10 +146,11 @@ func (h *taskHandler) Upcoming(c *gin.Context) {
 	for _, t := range tasks {
 		vos = append(vos, taskToVO(t))
 	}
+	attachBlockers(h.db, vos)
 	c.JSON(http.StatusOK, vos)
 }
 
-// Create creates a new task.
+// Create creates a new task. Tasks without a project_id go to the user's Inbox.
 // @Summary Create task
 // @Tags tasks
 // @Accept json
@@ -140,6 +159,8 @@ func (h *taskHandler) Upcoming(c *gin.Context) {
 // @Param body body dto.TaskCreateRequest true "Task create request"
 // @Success 201 {object} dto.TaskVO
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /tasks [post]
 func (h *taskHandler) Create(c *gin.Context) {
@@ -148,14 +169,55 @@ func (h *taskHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	task := model.Task{
-		ID:          uuid.New().String(),
+	userID := h.getUserID(c)
+	var task *model.Task
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, err = h.create(tx, userID, uuid.New().String(), req)
+		return err
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").First(task, "id = ?", task.ID)
+	c.JSON(http.StatusCreated, taskToVO(*task))
+}
+
+// create files a new task with the given ID for userID in tx.
+func (h *taskHandler) create(tx *gorm.DB, userID, id string, req dto.TaskCreateRequest) (*model.Task, error) {
+	projectID, err := service.ResolveTaskProject(tx, userID, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	sectionID, err := service.ResolveSection(tx, projectID, req.SectionID)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := service.ResolveLabels(tx, userID, req.LabelIDs)
+	if err != nil {
+		return nil, err
+	}
+	recurrence, err := service.NormalizeRecurrence(req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	sortKey, err := service.TaskSortKey(tx, "", projectID, sectionID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	task := &model.Task{
+		ID:          id,
 		Title:       req.Title,
 		Description: req.Description,
-		ProjectID:   req.ProjectID,
-		UserID:      h.getUserID(c),
+		ProjectID:   projectID,
+		SectionID:   sectionID,
+		SortKey:     sortKey,
+		UserID:      userID,
 		Priority:    req.Priority,
 		Status:      model.TaskStatusPending,
+		Recurrence:  recurrence,
+		Labels:      labels,
 	}
 	if req.DueDate != nil {
 		task.DueDate = req.DueDate
@@ -163,12 +225,16 @@ func (h *taskHandler) Create(c *gin.Context) {
 	if req.ReminderAt != nil {
 		task.ReminderAt = req.ReminderAt
 	}
-	if err := h.db.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if err := tx.Create(task).Error; err != nil {
+		return nil, err
+	}
+	if err := service.SyncTaskReminder(tx, nil, task); err != nil {
+		return nil, err
+	}
+	if err := service.RecordCreate(tx, userID, task); err != nil {
+		return nil, err
 	}
-	h.db.Preload("Labels").First(&task, "id = ?", task.ID)
-	c.JSON(http.StatusCreated, taskToVO(task))
+	return task, nil
 }
 
 // Get returns a task by ID.
@@ -179,6 +245,7 @@ func (h *taskHandler) Create(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Task ID"
 // @Success 200 {object} dto.TaskVO
+// @Header 200 {string} ETag "Task version, for If-Match"
 // @Failure 404 {object} map[string]string
 // @Router /tasks/{id} [get]
 func (h *taskHandler) Get(c *gin.Context) {
@@ -188,20 +255,30 @@ func (h *taskHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
-	c.JSON(http.StatusOK, taskToVO(task))
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, h.taskVO(task))
 }
 
-// Update updates a task.
+// Update updates a task. With If-Match, the update only applies if the task
+// is still at one of the given versions. With base_version in the body, it
+// is merged with what changed since that version instead.
 // @Summary Update task
+// @Description Fields left out are unchanged, as are fields set to null or a zero value; use PATCH to clear them. label_ids replaces the labels. An update with base_version, the version the client edited, is three-way merged with the changes made since: title, description, due_date and priority are taken from the client where only the client changed them, and label additions and removals are applied to the current labels. If the client and someone else changed the same field to different values, nothing is applied and the conflicts are returned with 409.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Task ID"
 // @Param body body dto.TaskUpdateRequest true "Task update request"
+// @Param force query bool false "Complete even if the task has open blockers"
+// @Param If-Match header string false "ETag of the version the update is based on"
 // @Success 200 {object} dto.TaskVO
+// @Header 200 {string} ETag "New task version"
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} dto.TaskConflictVO "Disallowed transition, WIP limit reached (status, wip_limit, current), or fields changed on both sides since base_version (conflicts)"
+// @Failure 412 {object} map[string]string "The task changed since that version, or base_version is no longer available"
 // @Router /tasks/{id} [put]
 func (h *taskHandler) Update(c *gin.Context) {
 	id := c.Param("id")
@@ -210,49 +287,554 @@ func (h *taskHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	force := c.Query("force") == "true"
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &task, ifMatch(c)); err != nil {
+			return err
+		}
+		return h.update(tx, userID, &task, req, force)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// Patch applies a JSON merge patch to a task.
+// @Summary Patch task
+// @Description Applies an RFC 7396 JSON merge patch (application/merge-patch+json) to the task's editable fields: title, description, project_id, priority, status, progress, due_date, reminder_at, recurrence and label_ids. Members left out are unchanged and members set to null are cleared: description becomes empty, priority and progress 0, due_date and reminder_at unset, recurrence none, project_id the Inbox and label_ids no labels. The patched task is validated before it is saved, so clearing title or status is rejected.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.TaskPatch true "Merge patch"
+// @Param force query bool false "Complete even if the task has open blockers"
+// @Param If-Match header string false "ETag of the version the patch is based on"
+// @Success 200 {object} dto.TaskVO
+// @Header 200 {string} ETag "New task version"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Disallowed transition or WIP limit reached"
+// @Failure 412 {object} map[string]string "The task changed since that version"
+// @Failure 415 {object} map[string]string
+// @Router /tasks/{id} [patch]
+func (h *taskHandler) Patch(c *gin.Context) {
+	userID := h.getUserID(c)
 	var task model.Task
-	if err := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).First(&task).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
-	copier.CopyWithOption(&task, &req, copier.Option{IgnoreEmpty: true})
+	force := c.Query("force") == "true"
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &task, ifMatch(c)); err != nil {
+			return err
+		}
+		current, err := service.TaskLabelIDs(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		doc := dto.TaskPatch{
+			Title:       task.Title,
+			Description: task.Description,
+			ProjectID:   task.ProjectID,
+			Priority:    task.Priority,
+			Status:      task.Status,
+			Progress:    task.Progress,
+			DueDate:     task.DueDate,
+			ReminderAt:  task.ReminderAt,
+			Recurrence:  task.Recurrence,
+			LabelIDs:    current,
+		}
+		if !bindMergePatch(c, &doc) {
+			return errPatchRejected
+		}
+		before := task
+		task.Title, task.Description, task.Priority, task.Progress = doc.Title, doc.Description, doc.Priority, doc.Progress
+		task.DueDate, task.ReminderAt = doc.DueDate, doc.ReminderAt
+		if task.Recurrence, err = service.NormalizeRecurrence(doc.Recurrence); err != nil {
+			return err
+		}
+		var labelIDs []string
+		if !slices.Equal(doc.LabelIDs, current) {
+			labelIDs = append([]string{}, doc.LabelIDs...)
+		}
+		return h.save(tx, userID, &task, &before, &doc.ProjectID, &doc.Status, labelIDs, force)
+	})
+	if errors.Is(err, errPatchRejected) {
+		return
+	}
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	setETag(c, task.Version)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// update applies req to task in tx, recording a revision and the activity.
+// An update based on an older version is merged with the changes since.
+func (h *taskHandler) update(tx *gorm.DB, userID string, task *model.Task, req dto.TaskUpdateRequest, force bool) error {
+	if req.BaseVersion != nil {
+		edit, err := h.revisions.Merge(tx, task, *req.BaseVersion, service.TaskEdit{
+			Title:       req.Title,
+			Description: req.Description,
+			DueDate:     req.DueDate,
+			Priority:    req.Priority,
+			LabelIDs:    req.LabelIDs,
+		})
+		if err != nil {
+			return err
+		}
+		req.Title, req.Description, req.DueDate, req.Priority, req.LabelIDs =
+			edit.Title, edit.Description, edit.DueDate, edit.Priority, edit.LabelIDs
+	}
+	before := *task
+	projectID, status := task.ProjectID, task.Status
+	copier.CopyWithOption(task, &req, copier.Option{IgnoreEmpty: true})
+	task.ProjectID, task.Status = projectID, status
 	if req.DueDate != nil {
 		task.DueDate = req.DueDate
 	}
 	if req.ReminderAt != nil {
 		task.ReminderAt = req.ReminderAt
 	}
-	if err := h.db.Save(&task).Error; err != nil {
+	if req.Recurrence != nil {
+		recurrence, err := service.NormalizeRecurrence(*req.Recurrence)
+		if err != nil {
+			return err
+		}
+		task.Recurrence = recurrence
+	}
+	return h.save(tx, userID, task, &before, req.ProjectID, req.Status, req.LabelIDs, force)
+}
+
+// save stores task, whose plain fields have been edited from before, after
+// moving it to projectID, changing its status and replacing its labels with
+// labelIDs as far as those are non-nil.
+func (h *taskHandler) save(tx *gorm.DB, userID string, task, before *model.Task, projectID, status *string, labelIDs []string, force bool) error {
+	var labels []model.Label
+	if labelIDs != nil {
+		var err error
+		if labels, err = service.ResolveLabels(tx, task.UserID, labelIDs); err != nil {
+			return err
+		}
+	}
+	if err := h.revisions.Record(tx, userID, before); err != nil {
+		return err
+	}
+	if projectID != nil && *projectID != task.ProjectID {
+		if err := service.MoveTask(tx, task, task.UserID, *projectID); err != nil {
+			return err
+		}
+	}
+	to := task.Status
+	if status != nil {
+		to = *status
+	}
+	if err := h.statuses.Apply(tx, task, to, force, time.Now()); err != nil {
+		return err
+	}
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+	if labelIDs != nil {
+		if err := service.SetTaskLabels(tx, task.ID, labels); err != nil {
+			return err
+		}
+	}
+	if err := service.SyncTaskReminder(tx, before, task); err != nil {
+		return err
+	}
+	return service.RecordUpdate(tx, userID, before, task)
+}
+
+// Move moves a task into another project.
+// @Summary Move task
+// @Description Moves the task (and its subtasks) into another project the caller owns or is a member of. An in-progress task must fit the WIP limit of its column there. Labels are kept. An empty project_id moves the task to the Inbox.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.TaskMoveRequest true "Task move request"
+// @Success 200 {object} dto.TaskVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "WIP limit of the task's column in the target project reached (status, wip_limit, current)"
+// @Router /tasks/{id}/move [post]
+func (h *taskHandler) Move(c *gin.Context) {
+	id := c.Param("id")
+	var req dto.TaskMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	before := task
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := h.revisions.Record(tx, userID, &before); err != nil {
+			return err
+		}
+		if err := service.MoveTask(tx, &task, task.UserID, req.ProjectID); err != nil {
+			return err
+		}
+		return service.RecordUpdate(tx, userID, &before, &task)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// Reorder places a task in a section and/or between two tasks of its project.
+// Only the moved task's row is written.
+// @Summary Reorder task
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.TaskReorderRequest true "Task reorder request"
+// @Success 200 {object} dto.TaskVO
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/reorder [post]
+func (h *taskHandler) Reorder(c *gin.Context) {
+	var req dto.TaskReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	sectionID := task.SectionID
+	if req.SectionID != nil {
+		var err error
+		if sectionID, err = service.ResolveSection(h.db, task.ProjectID, *req.SectionID); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+	}
+	before := task
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.ReorderTask(tx, &task, sectionID, req.AfterID, req.BeforeID); err != nil {
+			return err
+		}
+		return service.RecordUpdate(tx, userID, &before, &task)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// Complete marks a task as completed. A recurring task stays open with its
+// due date moved to the next occurrence.
+// @Summary Complete task
+// @Description Completes the task. A recurring task (one with a recurrence rule and a due date) is not closed: its due date moves to the next occurrence after now, its reminder moves along with it, and its status stays as it was.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param force query bool false "Complete even if the task has open blockers"
+// @Success 200 {object} dto.TaskVO
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tasks/{id}/complete [post]
+func (h *taskHandler) Complete(c *gin.Context) {
+	h.setStatus(c, model.TaskStatusCompleted)
+}
+
+// Reopen moves a completed or cancelled task back to pending.
+// @Summary Reopen task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} dto.TaskVO
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /tasks/{id}/reopen [post]
+func (h *taskHandler) Reopen(c *gin.Context) {
+	h.setStatus(c, model.TaskStatusPending)
+}
+
+func (h *taskHandler) setStatus(c *gin.Context, to string) {
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	before := task
+	force := c.Query("force") == "true"
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := h.revisions.Record(tx, userID, &before); err != nil {
+			return err
+		}
+		if err := h.statuses.Apply(tx, &task, to, force, time.Now()); err != nil {
+			return err
+		}
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		// Completing a recurring task moves its reminder along.
+		if err := service.SyncTaskReminder(tx, &before, &task); err != nil {
+			return err
+		}
+		return service.RecordUpdate(tx, userID, &before, &task)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// AddBlocker marks the task as blocked by another task.
+// @Summary Add task blocker
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param body body dto.TaskBlockerRequest true "Blocker"
+// @Success 200 {object} dto.TaskVO
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Dependency would create a cycle"
+// @Router /tasks/{id}/blockers [post]
+func (h *taskHandler) AddBlocker(c *gin.Context) {
+	var req dto.TaskBlockerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.AddDependency(tx, task.UserID, task.ID, req.BlockerID); err != nil {
+			return err
+		}
+		return service.RecordCreate(tx, userID, &model.TaskDependency{TaskID: task.ID, BlockerID: req.BlockerID})
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
+	c.JSON(http.StatusOK, h.taskVO(task))
+}
+
+// RemoveBlocker removes a blocker from the task.
+// @Summary Remove task blocker
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param blockerId path string true "Blocking task ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/blockers/{blockerId} [delete]
+func (h *taskHandler) RemoveBlocker(c *gin.Context) {
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	dep := model.TaskDependency{TaskID: task.ID, BlockerID: c.Param("blockerId")}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.RemoveDependency(tx, dep.TaskID, dep.BlockerID); err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, userID, &dep)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Revisions lists the previous versions of a task, newest first.
+// @Summary List task revisions
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.TaskRevisionVO
+// @Header 200 {integer} X-Total-Count "Total number of revisions"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/revisions [get]
+func (h *taskHandler) Revisions(c *gin.Context) {
+	task, err := service.CheckTaskAccess(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	limit, offset := parsePage(c)
+	q := h.db.Where("task_id = ?", task.ID).Session(&gorm.Session{})
+	var total int64
+	if err := q.Model(&model.TaskRevision{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var revs []model.TaskRevision
+	if err := q.Order("revision DESC").Limit(limit).Offset(offset).Find(&revs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	vos := make([]dto.TaskRevisionVO, 0, len(revs))
+	_ = copier.Copy(&vos, &revs)
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, vos)
+}
+
+// RestoreRevision rolls a task's content back to a previous revision.
+// @Summary Restore task revision
+// @Description Restores title, description, priority, progress, due date and reminder from the revision. Status, project and section are kept. The current version is saved as a new revision first.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} dto.TaskVO
+// @Failure 404 {object} map[string]string
+// @Router /tasks/{id}/revisions/{rev}/restore [post]
+func (h *taskHandler) RestoreRevision(c *gin.Context) {
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	rev, err := service.FindRevision(h.db, task.ID, c.Param("rev"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		return h.revisions.Restore(tx, userID, &task, rev)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
 	h.db.Preload("Labels").Preload("Subtasks").First(&task, "id = ?", task.ID)
-	c.JSON(http.StatusOK, taskToVO(task))
+	c.JSON(http.StatusOK, h.taskVO(task))
 }
 
-// Delete deletes a task.
+// Delete deletes a task. With If-Match, only if it is still at one of the
+// given versions.
 // @Summary Delete task
 // @Tags tasks
 // @Security BearerAuth
 // @Param id path string true "Task ID"
+// @Param If-Match header string false "ETag of the version the delete is based on"
 // @Success 204
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string "The task changed since that version"
 // @Router /tasks/{id} [delete]
 func (h *taskHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
-	result := h.db.Where("id = ? AND user_id = ?", id, h.getUserID(c)).Delete(&model.Task{})
-	if result.RowsAffected == 0 {
+	userID := h.getUserID(c)
+	var task model.Task
+	if err := h.db.Where("id = ? AND user_id = ?", id, userID).First(&task).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
 		return
 	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.CheckVersion(tx, &task, ifMatch(c)); err != nil {
+			return err
+		}
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, userID, &task)
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
 
+// taskVO converts a single task, including its blockers.
+func (h *taskHandler) taskVO(t model.Task) dto.TaskVO {
+	vos := []dto.TaskVO{taskToVO(t)}
+	attachBlockers(h.db, vos)
+	return vos[0]
+}
+
+// attachBlockers fills Blocked/BlockedBy on vos in one query. It is best
+// effort: on a lookup error the tasks are returned without blockers.
+func attachBlockers(db *gorm.DB, vos []dto.TaskVO) {
+	ids := make([]string, len(vos))
+	for i, vo := range vos {
+		ids[i] = vo.ID
+	}
+	blockers, err := service.LoadBlockers(db, ids)
+	if err != nil {
+		return
+	}
+	var all []model.Task
+	for _, bs := range blockers {
+		all = append(all, bs...)
+	}
+	categories, err := service.StatusCategories(db, all)
+	if err != nil {
+		return
+	}
+	for i := range vos {
+		for _, b := range blockers[vos[i].ID] {
+			vos[i].BlockedBy = append(vos[i].BlockedBy, dto.TaskRefVO{ID: b.ID, Title: b.Title, Status: b.Status})
+			if service.IsOpen(b, categories[b.ID]) {
+				vos[i].Blocked = true
+			}
+		}
+	}
+}
+
 func taskToVO(t model.Task) dto.TaskVO {
 	vo := dto.TaskVO{}
 	_ = copier.Copy(&vo, &t)
 	for _, l := range t.Labels {
-		vo.Labels = append(vo.Labels, dto.LabelVO{ID: l.ID, Name: l.Name, Color: l.Color})
+		vo.Labels = append(vo.Labels, dto.LabelVO{ID: l.ID, Name: l.Name, Color: l.Color, Version: l.Version})
 	}
 	return vo
 }
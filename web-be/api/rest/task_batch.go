@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// maxTaskBatchItems bounds the task operations of one batch, over all
+// operations.
+const maxTaskBatchItems = 500
+
+// errBatchRejected aborts an atomic batch when an item failed.
+var errBatchRejected = errors.New("batch rejected")
+
+// Batch applies operations to many tasks in one transaction.
+// @Summary Batch task operations
+// @Description Applies each operation to each of its task_ids, in order: update (data, like PUT /tasks/{id}), complete, delete, move (project_id), add_labels and remove_labels (label_ids). In atomic mode (the default) nothing is applied if any item fails and the response is 422, with the failing items marked failed and the others rolled_back. In per_item mode failing items are skipped and the rest applied.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.TaskBatchRequest true "Operations"
+// @Success 200 {object} dto.TaskBatchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} dto.TaskBatchResponse
+// @Router /tasks/batch [post]
+func (h *taskHandler) Batch(c *gin.Context) {
+	var req dto.TaskBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	items := 0
+	for i, op := range req.Operations {
+		if err := validateBatchOperation(op); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("operations[%d]: %v", i, err)})
+			return
+		}
+		items += len(op.TaskIDs)
+	}
+	if items > maxTaskBatchItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a batch may touch at most %d tasks", maxTaskBatchItems)})
+		return
+	}
+	atomic := req.Mode != "per_item"
+
+	userID := h.getUserID(c)
+	results := make([]dto.TaskBatchResultVO, 0, items)
+	failed := false
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for i, op := range req.Operations {
+			for _, id := range op.TaskIDs {
+				res := dto.TaskBatchResultVO{Operation: i, TaskID: id, Status: syncApplied}
+				// Each item runs in a savepoint so that, per item, a failure
+				// only undoes that item.
+				if err := tx.Transaction(func(tx *gorm.DB) error { return h.applyBatch(tx, userID, op, id) }); err != nil {
+					if serviceStatus(err) == http.StatusInternalServerError {
+						return err
+					}
+					failed = true
+					res.Status = syncFailed
+					res.Code = serviceStatus(err)
+					res.Error = err.Error()
+				}
+				results = append(results, res)
+			}
+		}
+		if failed && atomic {
+			return errBatchRejected
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRejected) {
+		writeServiceError(c, err)
+		return
+	}
+	if failed && atomic {
+		for i := range results {
+			if results[i].Status == syncApplied {
+				results[i].Status = syncRolledBack
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, dto.TaskBatchResponse{Applied: false, Results: results, Tasks: []dto.TaskVO{}})
+		return
+	}
+
+	var ids []string
+	for _, r := range results {
+		if r.Status == syncApplied && !slices.Contains(ids, r.TaskID) {
+			ids = append(ids, r.TaskID)
+		}
+	}
+	var tasks []model.Task
+	if len(ids) > 0 {
+		if err := h.db.Where("id IN ? AND user_id = ?", ids, userID).Preload("Labels").Find(&tasks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	vos := make([]dto.TaskVO, 0, len(tasks))
+	for _, t := range tasks {
+		vos = append(vos, taskToVO(t))
+	}
+	attachBlockers(h.db, vos)
+	c.JSON(http.StatusOK, dto.TaskBatchResponse{Applied: !failed, Results: results, Tasks: vos})
+}
+
+// validateBatchOperation checks that op carries what its kind needs.
+func validateBatchOperation(op dto.TaskBatchOperation) error {
+	switch op.Op {
+	case dto.TaskBatchUpdate:
+		if op.Data == nil {
+			return errors.New("update needs data")
+		}
+	case dto.TaskBatchMove:
+		if op.ProjectID == nil {
+			return errors.New("move needs project_id")
+		}
+	case dto.TaskBatchAddLabels, dto.TaskBatchRemoveLabels:
+		if len(op.LabelIDs) == 0 {
+			return fmt.Errorf("%s needs label_ids", op.Op)
+		}
+	}
+	return nil
+}
+
+// applyBatch applies op to task id in tx with the same rules as the single
+// task endpoints.
+func (h *taskHandler) applyBatch(tx *gorm.DB, userID string, op dto.TaskBatchOperation, id string) error {
+	var task model.Task
+	if err := loadOwned(tx, &task, id, false, service.ErrTaskNotFound, "user_id = ?", userID); err != nil {
+		return err
+	}
+	var req dto.TaskUpdateRequest
+	switch op.Op {
+	case dto.TaskBatchDelete:
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		return service.RecordDelete(tx, userID, &task)
+	case dto.TaskBatchUpdate:
+		req = *op.Data
+	case dto.TaskBatchComplete:
+		status := model.TaskStatusCompleted
+		req.Status = &status
+	case dto.TaskBatchMove:
+		req.ProjectID = op.ProjectID
+	case dto.TaskBatchAddLabels, dto.TaskBatchRemoveLabels:
+		current, err := service.TaskLabelIDs(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if op.Op == dto.TaskBatchAddLabels {
+			for _, l := range op.LabelIDs {
+				if !slices.Contains(current, l) {
+					current = append(current, l)
+				}
+			}
+		} else {
+			current = slices.DeleteFunc(current, func(l string) bool { return slices.Contains(op.LabelIDs, l) })
+		}
+		req.LabelIDs = append([]string{}, current...)
+	}
+	return h.update(tx, userID, &task, req, op.Force)
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// QuickAdd creates a task from a line of natural-language text.
+// @Summary Quick add task
+// @Description Reads a task from text such as "Pay rent every month on the 1st 9am #Home @bills p1" or "每月1號早上9點繳房租 #家 @帳單 p1", in English or Chinese: the due date and time (today, tomorrow, next friday, in 3 days, Dec 24, 11/3, 明天, 下週五, 3天後, 11月3日, 9am, 17:30, 下午3點, 7點半 ...), recurrence (every day, every weekday, every mon and thu, every 2 weeks, every month on the 1st, 每天, 每週一三五, 每月1號, 每隔一天 ...), the project (#name, an existing project the caller can see, else left in the title), labels (@name; missing labels are created) and priority (p1 to p4). The rest of the text is the title. Dates are read in the given time zone; a time without a date is the next such time, and a date without a time is due at the end of that day. With dry_run=true nothing is created and what was read is returned instead.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.TaskQuickAddRequest true "Text"
+// @Param dry_run query bool false "Only return what was read"
+// @Success 200 {object} dto.TaskQuickAddVO "dry_run"
+// @Success 201 {object} dto.TaskVO
+// @Failure 400 {object} map[string]string
+// @Router /tasks/quick [post]
+func (h *taskHandler) QuickAdd(c *gin.Context) {
+	var req dto.TaskQuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown timezone " + req.Timezone})
+			return
+		}
+	}
+	userID := h.getUserID(c)
+
+	var projects []model.Project
+	if err := h.db.Where("user_id = ? OR id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID, userID).
+		Order("created_at").Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Own projects first, so they win over shared ones of the same name.
+	slices.SortStableFunc(projects, func(a, b model.Project) int {
+		return cmp.Compare(btoi(b.UserID == userID), btoi(a.UserID == userID))
+	})
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	parsed, err := service.ParseQuickAdd(req.Text, time.Now().In(loc), names)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+
+	vo := dto.TaskQuickAddVO{
+		Title:      parsed.Title,
+		DueDate:    parsed.DueDate,
+		AllDay:     parsed.AllDay,
+		Recurrence: parsed.Recurrence,
+		Labels:     []dto.TaskQuickAddLabelVO{},
+		Priority:   parsed.Priority,
+	}
+	for _, p := range projects {
+		if parsed.Project != "" && strings.EqualFold(p.Name, parsed.Project) {
+			vo.ProjectID, vo.ProjectName = &p.ID, p.Name
+			break
+		}
+	}
+	var labels []model.Label
+	if err := h.db.Where("user_id = ?", userID).Order("created_at").Find(&labels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, name := range parsed.Labels {
+		l := dto.TaskQuickAddLabelVO{Name: name}
+		for _, existing := range labels {
+			if strings.EqualFold(existing.Name, name) {
+				l.ID, l.Name = &existing.ID, existing.Name
+				break
+			}
+		}
+		vo.Labels = append(vo.Labels, l)
+	}
+	if c.Query("dry_run") == "true" {
+		c.JSON(http.StatusOK, vo)
+		return
+	}
+
+	create := dto.TaskCreateRequest{
+		Title:      vo.Title,
+		Priority:   vo.Priority,
+		DueDate:    vo.DueDate,
+		Recurrence: vo.Recurrence,
+		LabelIDs:   []string{},
+	}
+	if vo.ProjectID != nil {
+		create.ProjectID = *vo.ProjectID
+	}
+	var task *model.Task
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		for _, l := range vo.Labels {
+			if l.ID == nil {
+				label := model.Label{ID: uuid.New().String(), Name: l.Name, UserID: userID}
+				if err := service.CreateLogged(tx, userID, &label); err != nil {
+					return err
+				}
+				l.ID = &label.ID
+			}
+			create.LabelIDs = append(create.LabelIDs, *l.ID)
+		}
+		var err error
+		task, err = h.create(tx, userID, uuid.New().String(), create)
+		return err
+	})
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	h.db.Preload("Labels").First(task, "id = ?", task.ID)
+	c.JSON(http.StatusCreated, taskToVO(*task))
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
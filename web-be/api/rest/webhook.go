@@ -0,0 +1,287 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/dto"
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// RegisterWebhookRoutes registers webhook subscription and delivery log routes.
+func RegisterWebhookRoutes(r *gin.RouterGroup, db *gorm.DB) {
+	h := &webhookHandler{db: db}
+	group := r.Group("/webhooks")
+	{
+		group.GET("", h.List)
+		group.POST("", h.Create)
+		group.GET("/events", h.EventTypes)
+		group.GET("/:id", h.Get)
+		group.PUT("/:id", h.Update)
+		group.DELETE("/:id", h.Delete)
+		group.GET("/:id/deliveries", h.ListDeliveries)
+		group.POST("/:id/deliveries/:deliveryId/replay", h.Replay)
+	}
+}
+
+type webhookHandler struct {
+	db *gorm.DB
+}
+
+func (h *webhookHandler) getUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	return uid.(string)
+}
+
+// List returns the caller's webhooks, user- and project-level.
+// @Summary List webhooks
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param project_id query string false "Only webhooks of this project"
+// @Success 200 {array} dto.WebhookVO
+// @Router /webhooks [get]
+func (h *webhookHandler) List(c *gin.Context) {
+	q := h.db.Where("user_id = ?", h.getUserID(c))
+	if projectID := c.Query("project_id"); projectID != "" {
+		q = q.Where("project_id = ?", projectID)
+	}
+	var hooks []model.Webhook
+	if err := q.Order("created_at").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.WebhookVO, 0, len(hooks))
+	for i := range hooks {
+		vos = append(vos, webhookToVO(&hooks[i]))
+	}
+	c.JSON(http.StatusOK, vos)
+}
+
+// EventTypes lists the event types webhooks can subscribe to.
+// @Summary List webhook event types
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} string
+// @Router /webhooks/events [get]
+func (h *webhookHandler) EventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, service.WebhookEventTypes())
+}
+
+// Create subscribes a URL to task and project events. A project-level
+// webhook requires owning the project.
+// @Summary Create webhook
+// @Description Deliveries are POSTed as JSON with X-Webhook-Timestamp and X-Webhook-Signature ("sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the secret). An empty events list subscribes to all events.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.WebhookCreateRequest true "Webhook"
+// @Success 201 {object} dto.WebhookVO
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks [post]
+func (h *webhookHandler) Create(c *gin.Context) {
+	var req dto.WebhookCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := service.CreateWebhook(h.db, h.getUserID(c), req.ProjectID, req.URL, req.Events, req.Secret)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, webhookToVO(w))
+}
+
+// Get returns a webhook.
+// @Summary Get webhook
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} dto.WebhookVO
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func (h *webhookHandler) Get(c *gin.Context) {
+	w, err := service.FindWebhook(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhookToVO(w))
+}
+
+// Update changes a webhook's URL, events or secret, or disables or
+// re-enables it. Re-enabling resumes deliveries queued meanwhile.
+// @Summary Update webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param body body dto.WebhookUpdateRequest true "Changes"
+// @Success 200 {object} dto.WebhookVO
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [put]
+func (h *webhookHandler) Update(c *gin.Context) {
+	var req dto.WebhookUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	w, err := service.FindWebhook(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := service.UpdateWebhook(h.db, w, service.WebhookUpdate{
+		URL:     req.URL,
+		Events:  req.Events,
+		Secret:  req.Secret,
+		Enabled: req.Enabled,
+	}); err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhookToVO(w))
+}
+
+// Delete removes a webhook and its delivery log.
+// @Summary Delete webhook
+// @Tags webhooks
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func (h *webhookHandler) Delete(c *gin.Context) {
+	w, err := service.FindWebhook(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	if err := h.db.Delete(w).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns a webhook's delivery log, newest first.
+// @Summary List webhook deliveries
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Only deliveries with this status (pending, succeeded, failed)"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} dto.WebhookDeliveryVO
+// @Header 200 {integer} X-Total-Count "Total number of deliveries"
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id}/deliveries [get]
+func (h *webhookHandler) ListDeliveries(c *gin.Context) {
+	w, err := service.FindWebhook(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	q := h.db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", w.ID)
+	if status := c.Query("status"); status != "" {
+		q = q.Where("status = ?", status)
+	}
+	q = q.Session(&gorm.Session{})
+	limit, offset := parsePage(c)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var deliveries []model.WebhookDelivery
+	if err := q.Order("created_at DESC, id").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	vos := make([]dto.WebhookDeliveryVO, 0, len(deliveries))
+	for i := range deliveries {
+		vos = append(vos, webhookDeliveryToVO(&deliveries[i]))
+	}
+	setTotalCount(c, total)
+	c.JSON(http.StatusOK, vos)
+}
+
+// Replay queues an earlier delivery's event to be sent again, as a new delivery.
+// @Summary Replay webhook delivery
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 202 {object} dto.WebhookDeliveryVO
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Webhook is disabled"
+// @Router /webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func (h *webhookHandler) Replay(c *gin.Context) {
+	w, err := service.FindWebhook(h.db, h.getUserID(c), c.Param("id"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	d, err := service.FindWebhookDelivery(h.db, w.ID, c.Param("deliveryId"))
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	replay, err := service.ReplayWebhookDelivery(h.db, w, d)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, webhookDeliveryToVO(replay))
+}
+
+func webhookToVO(w *model.Webhook) dto.WebhookVO {
+	return dto.WebhookVO{
+		ID:                  w.ID,
+		ProjectID:           w.ProjectID,
+		URL:                 w.URL,
+		Events:              service.WebhookEvents(w),
+		Secret:              w.Secret,
+		Enabled:             w.DisabledAt == nil,
+		ConsecutiveFailures: w.ConsecutiveFailures,
+		DisabledAt:          w.DisabledAt,
+		DisabledReason:      w.DisabledReason,
+		CreatedAt:           w.CreatedAt,
+		UpdatedAt:           w.UpdatedAt,
+	}
+}
+
+func webhookDeliveryToVO(d *model.WebhookDelivery) dto.WebhookDeliveryVO {
+	vo := dto.WebhookDeliveryVO{
+		ID:             d.ID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		Error:          d.Error,
+		DeliveredAt:    d.DeliveredAt,
+		ReplayOf:       d.ReplayOf,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == model.WebhookDeliveryPending {
+		vo.NextAttemptAt = &d.NextAttemptAt
+	}
+	return vo
+}
@@ -21,8 +21,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -34,6 +37,9 @@ import (
 	"github.com/todo-tracking-app/web-be/internal/config"
 	"github.com/todo-tracking-app/web-be/internal/database"
 	"github.com/todo-tracking-app/web-be/internal/middleware"
+	"github.com/todo-tracking-app/web-be/internal/service"
+	"github.com/todo-tracking-app/web-be/internal/storage"
+	"github.com/todo-tracking-app/web-be/internal/webhook"
 )
 
 func main() {
@@ -47,6 +53,48 @@ func main() {
 		log.Fatalf("connect database: %v", err)
 	}
 
+	statuses, err := service.NewTaskStatusMachine(cfg.TaskStatusTransitions)
+	if err != nil {
+		log.Fatalf("task status transitions: %v", err)
+	}
+
+	blobs, err := newBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("attachment storage: %v", err)
+	}
+	attachments := service.NewAttachments(blobs, service.AttachmentLimits{
+		MaxFileBytes:      cfg.AttachmentMaxFileBytes,
+		FreeQuotaBytes:    cfg.AttachmentFreeQuotaBytes,
+		PremiumQuotaBytes: cfg.AttachmentPremiumQuotaBytes,
+	})
+
+	revisions := service.NewTaskRevisions(cfg.TaskRevisionsFree, cfg.TaskRevisionsPremium)
+
+	notifiers, err := newNotifiers(cfg, db)
+	if err != nil {
+		log.Fatalf("notifiers: %v", err)
+	}
+	reminders := service.NewReminderDispatcher(notifiers...)
+	go runDispatcher(context.Background(), "reminders", cfg.ReminderPollInterval, func(ctx context.Context, limit int) (int, error) {
+		return reminders.DispatchDue(ctx, db, limit)
+	})
+	sender := service.NewNotificationSender(notifiers...)
+	go runDispatcher(context.Background(), "notifications", cfg.ReminderPollInterval, func(ctx context.Context, limit int) (int, error) {
+		return sender.DispatchPending(ctx, db, limit)
+	})
+	webhooks := service.NewWebhookDispatcher(webhook.NewClient(cfg.WebhookAllowPrivateNetworks), cfg.WebhookDisableAfter)
+	go runDispatcher(context.Background(), "webhooks", cfg.WebhookPollInterval, func(ctx context.Context, limit int) (int, error) {
+		return webhooks.DispatchDue(ctx, db, limit)
+	})
+	// Sync tokens and the event stream only move past seqs a tick has settled.
+	go runDispatcher(context.Background(), "sync ticks", time.Second, func(ctx context.Context, _ int) (int, error) {
+		return 0, service.RecordSyncTick(ctx, db)
+	})
+	events := service.NewEventBroker(db, cfg.EventsReplayWindow)
+	go events.Run(context.Background(), cfg.EventsPollInterval)
+	idempotencyKeys := service.NewIdempotencyKeys(db, cfg.IdempotencyKeyTTL)
+	go runDispatcher(context.Background(), "idempotency keys", time.Hour, idempotencyKeys.Purge)
+
 	r := gin.Default()
 
 	// CORS
@@ -66,25 +114,50 @@ func main() {
 		// Stripe webhook (no auth - Stripe sends raw POST)
 		rest.RegisterSubscriptionRoutes(v1, db, cfg)
 
+		// iCalendar feed (no auth - the secret token is in the URL)
+		rest.RegisterCalendarRoutes(v1, db)
+
 		// Protected routes
 		protected := v1.Group("")
-		protected.Use(middleware.Auth(cfg))
+		protected.Use(middleware.Auth(cfg), middleware.Idempotency(idempotencyKeys))
 		{
 			rest.RegisterUserRoutes(protected, db)
+			rest.RegisterDeviceRoutes(protected, db)
 			rest.RegisterSubscriptionProtectedRoutes(protected, db, cfg)
 			rest.RegisterProjectRoutes(protected, db)
-			rest.RegisterTaskRoutes(protected, db)
+			rest.RegisterSectionRoutes(protected, db)
+			rest.RegisterProjectStatusRoutes(protected, db)
+			rest.RegisterTaskRoutes(protected, db, statuses, revisions)
 			rest.RegisterLabelRoutes(protected, db)
+			rest.RegisterCommentRoutes(protected, db)
+			rest.RegisterAttachmentRoutes(protected, db, attachments)
+			rest.RegisterActivityRoutes(protected, db)
+			rest.RegisterReminderRoutes(protected, db)
+			rest.RegisterAssignmentRoutes(protected, db)
+			rest.RegisterNotificationRoutes(protected, db)
+			rest.RegisterWebhookRoutes(protected, db)
+			rest.RegisterSyncRoutes(protected, db, statuses, revisions)
+			rest.RegisterSearchRoutes(protected, db)
+			rest.RegisterCalendarProtectedRoutes(protected, db)
+			rest.RegisterAppPasswordRoutes(protected, db)
 		}
+
+		// Live updates; EventSource cannot set headers, so the token may come in the query
+		stream := v1.Group("")
+		stream.Use(middleware.QueryToken(), middleware.Auth(cfg))
+		rest.RegisterEventRoutes(stream, events, cfg.EventsHeartbeatInterval)
 	}
 
+	// CalDAV (HTTP Basic with app passwords - clients look for it at the host root)
+	rest.RegisterCalDAVRoutes(&r.RouterGroup, db, statuses, revisions)
+
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// gRPC server (optional, on separate port)
 	if grpcAddr := os.Getenv("GRPC_PORT"); grpcAddr != "" {
 		go func() {
-			if err := grpc.Serve(db, ":"+grpcAddr); err != nil {
+			if err := grpc.Serve(db, statuses, revisions, idempotencyKeys, ":"+grpcAddr); err != nil {
 				log.Printf("gRPC server error: %v", err)
 			}
 		}()
@@ -99,3 +172,20 @@ func main() {
 		log.Fatalf("run server: %v", err)
 	}
 }
+
+// newBlobStore returns the attachment blob store selected by STORAGE_BACKEND.
+func newBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.StorageBackend {
+	case "local":
+		return storage.NewLocalStore(cfg.StorageLocalDir)
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		})
+	}
+	return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", cfg.StorageBackend)
+}
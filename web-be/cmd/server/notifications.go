@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/config"
+	"github.com/todo-tracking-app/web-be/internal/notify"
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// dispatchBatch caps how many rows one poll delivers before yielding.
+const dispatchBatch = 100
+
+// newNotifiers returns a notifier for every channel configured in cfg.
+func newNotifiers(cfg *config.Config, db *gorm.DB) ([]notify.Notifier, error) {
+	var out []notify.Notifier
+	if cfg.SMTPHost != "" {
+		out = append(out, notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}))
+	}
+	if cfg.ReminderWebhookURL != "" {
+		out = append(out, notify.NewWebhookNotifier(cfg.ReminderWebhookURL, cfg.ReminderWebhookSecret))
+	}
+	var apns *notify.APNsClient
+	if cfg.APNsKey != "" {
+		c, err := notify.NewAPNsClient(notify.APNsConfig{
+			Endpoint: cfg.APNsEndpoint,
+			KeyID:    cfg.APNsKeyID,
+			TeamID:   cfg.APNsTeamID,
+			Topic:    cfg.APNsTopic,
+			KeyPEM:   cfg.APNsKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		apns = c
+	}
+	var fcm *notify.FCMClient
+	if cfg.FCMServiceAccountJSON != "" {
+		c, err := notify.NewFCMClient(notify.FCMConfig{
+			Endpoint:           cfg.FCMEndpoint,
+			TokenURI:           cfg.FCMTokenURI,
+			ProjectID:          cfg.FCMProjectID,
+			ServiceAccountJSON: cfg.FCMServiceAccountJSON,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fcm = c
+	}
+	if apns != nil || fcm != nil {
+		out = append(out, notify.NewPushNotifier(service.NewDeviceStore(db), apns, fcm))
+	}
+	return out, nil
+}
+
+// runDispatcher calls dispatch every interval until ctx is done, draining
+// full batches back to back. Every instance runs it; dispatchers claim rows
+// with SKIP LOCKED so each one is delivered once.
+func runDispatcher(ctx context.Context, name string, interval time.Duration,
+	dispatch func(ctx context.Context, limit int) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := dispatch(ctx, dispatchBatch)
+			if err != nil {
+				log.Printf("%s: dispatch: %v", name, err)
+				break
+			}
+			if n < dispatchBatch {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_projects_user_inbox;
+ALTER TABLE projects DROP COLUMN IF EXISTS is_inbox;
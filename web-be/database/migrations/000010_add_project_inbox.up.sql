@@ -0,0 +1,4 @@
+-- Each user owns exactly one Inbox project, used as the default for new tasks
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS is_inbox BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_user_inbox ON projects(user_id) WHERE is_inbox AND deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- Timestamps maintained by the task status state machine
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed' AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_at ON tasks(completed_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_project_sort_key;
+DROP INDEX IF EXISTS idx_tasks_section_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS sort_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS section_id;
+DROP TABLE IF EXISTS sections;
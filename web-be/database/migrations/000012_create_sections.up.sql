@@ -0,0 +1,28 @@
+-- Sections group tasks inside a project (board columns).
+-- sort_key columns hold fractional indexes compared bytewise, hence COLLATE "C".
+CREATE TABLE IF NOT EXISTS sections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    sort_key TEXT COLLATE "C" NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sections_project_id ON sections(project_id, sort_key);
+CREATE INDEX IF NOT EXISTS idx_sections_deleted_at ON sections(deleted_at);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS section_id UUID REFERENCES sections(id) ON DELETE SET NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sort_key TEXT COLLATE "C" NOT NULL DEFAULT '';
+
+-- Give existing tasks distinct keys in creation order. Keys must not end in '0'.
+UPDATE tasks SET sort_key = 'a' || lpad(ranked.rn::text, 10, '0') || 'V'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY project_id ORDER BY created_at, id) AS rn
+    FROM tasks
+) ranked
+WHERE tasks.id = ranked.id AND tasks.sort_key = '';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_section_id ON tasks(section_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_sort_key ON tasks(project_id, section_id, sort_key);
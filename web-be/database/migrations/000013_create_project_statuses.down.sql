@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_project_status;
+DROP TABLE IF EXISTS project_statuses;
@@ -0,0 +1,16 @@
+-- project_statuses: custom status columns and WIP limits for status boards
+CREATE TABLE IF NOT EXISTS project_statuses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    key VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    wip_limit INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT project_statuses_category_check CHECK (category IN ('pending', 'in_progress', 'completed', 'cancelled')),
+    CONSTRAINT project_statuses_wip_limit_check CHECK (wip_limit >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_project_statuses_key ON project_statuses(project_id, key);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks(project_id, status);
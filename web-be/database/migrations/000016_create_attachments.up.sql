@@ -0,0 +1,17 @@
+-- files attached to tasks (and optionally to one of the task's comments);
+-- the bytes live in the configured blob store under storage_key
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL,  -- uploader; custom users.id or Supabase auth.users id
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size >= 0),
+    storage_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_task_id ON attachments(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_attachments_comment_id ON attachments(comment_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
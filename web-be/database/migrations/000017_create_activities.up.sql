@@ -0,0 +1,18 @@
+-- audit log of create/update/delete on tasks, projects and their children;
+-- task_id/project_id are the task and project the entity belonged to at the time
+CREATE TABLE IF NOT EXISTS activities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID NOT NULL,  -- custom users.id or Supabase auth.users id
+    entity_type VARCHAR(30) NOT NULL,
+    entity_id UUID NOT NULL,
+    task_id UUID,  -- no FK: the log outlives deleted tasks and projects
+    project_id UUID,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activities_task_id ON activities(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_activities_project_id ON activities(project_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_activities_entity ON activities(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_activities_actor_id ON activities(actor_id);
@@ -0,0 +1,19 @@
+-- previous versions of tasks, one row per update; pruned to a per-owner retention limit
+CREATE TABLE IF NOT EXISTS task_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    actor_id UUID NOT NULL,  -- custom users.id or Supabase auth.users id
+    title VARCHAR(500) NOT NULL,
+    description TEXT,
+    project_id UUID,
+    section_id UUID,
+    priority INT NOT NULL DEFAULT 0,
+    status VARCHAR(20),
+    progress INT NOT NULL DEFAULT 0,
+    due_date TIMESTAMPTZ,
+    reminder_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_revisions_task_rev ON task_revisions(task_id, revision);
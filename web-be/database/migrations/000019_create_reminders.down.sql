@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS reminder_deliveries;
+DROP TABLE IF EXISTS reminders;
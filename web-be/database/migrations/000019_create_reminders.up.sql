@@ -0,0 +1,53 @@
+-- reminders: several per task; from_task marks the one mirroring tasks.reminder_at
+CREATE TABLE IF NOT EXISTS reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,  -- recipient; custom users.id or Supabase auth.users id
+    remind_at TIMESTAMPTZ NOT NULL,
+    from_task BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',  -- pending, sent, failed, cancelled
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,  -- retry backoff after a failed delivery
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    snooze_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id);
+-- the scheduler polls pending reminders by due time
+CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders(remind_at) WHERE status = 'pending';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reminders_from_task ON reminders(task_id) WHERE from_task;
+
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,  -- sent, failed, skipped
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminder_deliveries_reminder_id ON reminder_deliveries(reminder_id);
+
+-- push notification targets
+CREATE TABLE IF NOT EXISTS devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('apns', 'fcm')),
+    token TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_token ON devices(token);
+CREATE INDEX IF NOT EXISTS idx_devices_user_id ON devices(user_id);
+
+-- carry over reminders still ahead; past ones are not replayed
+INSERT INTO reminders (task_id, user_id, remind_at, from_task)
+SELECT id, user_id, reminder_at, TRUE
+FROM tasks
+WHERE reminder_at > NOW() AND deleted_at IS NULL AND completed_at IS NULL
+ON CONFLICT DO NOTHING;
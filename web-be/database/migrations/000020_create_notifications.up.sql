@@ -0,0 +1,34 @@
+-- notifications: in-app inbox plus the email/push delivery queue
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,  -- recipient; custom users.id or Supabase auth.users id
+    actor_id UUID,
+    type VARCHAR(20) NOT NULL,  -- assignment, mention, comment, reminder
+    title VARCHAR(500) NOT NULL,
+    body TEXT,
+    task_id UUID,  -- no FK: notifications outlive deleted tasks and comments
+    project_id UUID,
+    comment_id UUID,
+    in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    email_status VARCHAR(20) NOT NULL DEFAULT '',  -- '', pending, sent, failed, skipped
+    push_status VARCHAR(20) NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at) WHERE in_app;
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE in_app AND read_at IS NULL;
+-- the sender polls rows with outstanding email or push deliveries
+CREATE INDEX IF NOT EXISTS idx_notifications_pending ON notifications(created_at)
+    WHERE email_status = 'pending' OR push_status = 'pending';
+
+-- per-type channel choices; missing rows fall back to the built-in defaults
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    push BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, type)
+);
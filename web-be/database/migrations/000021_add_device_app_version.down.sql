@@ -0,0 +1 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS app_version;
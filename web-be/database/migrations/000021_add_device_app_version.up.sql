@@ -0,0 +1,2 @@
+-- App build that registered the push token, for targeting and debugging
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS app_version VARCHAR(50);
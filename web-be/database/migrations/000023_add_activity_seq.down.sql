@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_activities_seq;
+ALTER TABLE activities DROP COLUMN IF EXISTS seq;
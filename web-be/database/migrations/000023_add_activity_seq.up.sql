@@ -0,0 +1,3 @@
+-- Monotonic cursor over the activity log, used as the live event stream's event ID
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS seq BIGSERIAL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_activities_seq ON activities(seq);
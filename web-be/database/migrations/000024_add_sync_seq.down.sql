@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS subtasks_sync_seq ON subtasks;
+DROP TRIGGER IF EXISTS labels_sync_seq ON labels;
+DROP TRIGGER IF EXISTS projects_sync_seq ON projects;
+DROP TRIGGER IF EXISTS tasks_sync_seq ON tasks;
+
+DROP INDEX IF EXISTS idx_subtasks_sync_seq;
+DROP INDEX IF EXISTS idx_labels_sync_seq;
+DROP INDEX IF EXISTS idx_projects_sync_seq;
+DROP INDEX IF EXISTS idx_tasks_sync_seq;
+
+ALTER TABLE subtasks DROP COLUMN IF EXISTS sync_seq;
+ALTER TABLE labels DROP COLUMN IF EXISTS sync_seq;
+ALTER TABLE projects DROP COLUMN IF EXISTS sync_seq;
+ALTER TABLE tasks DROP COLUMN IF EXISTS sync_seq;
+
+DROP FUNCTION IF EXISTS bump_sync_seq();
+DROP SEQUENCE IF EXISTS sync_seq;
@@ -0,0 +1,34 @@
+-- Delta sync: every insert or update (including soft deletes) of a synced row
+-- stamps it with the next value of one shared sequence; GET /sync returns rows
+-- above the client's token.
+CREATE SEQUENCE IF NOT EXISTS sync_seq;
+
+CREATE OR REPLACE FUNCTION bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_seq := nextval('sync_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE labels ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS sync_seq BIGINT NOT NULL DEFAULT nextval('sync_seq');
+
+CREATE INDEX IF NOT EXISTS idx_tasks_sync_seq ON tasks(user_id, sync_seq);
+CREATE INDEX IF NOT EXISTS idx_projects_sync_seq ON projects(sync_seq);
+CREATE INDEX IF NOT EXISTS idx_labels_sync_seq ON labels(user_id, sync_seq);
+CREATE INDEX IF NOT EXISTS idx_subtasks_sync_seq ON subtasks(sync_seq);
+
+DROP TRIGGER IF EXISTS tasks_sync_seq ON tasks;
+CREATE TRIGGER tasks_sync_seq BEFORE INSERT OR UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_seq();
+DROP TRIGGER IF EXISTS projects_sync_seq ON projects;
+CREATE TRIGGER projects_sync_seq BEFORE INSERT OR UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_seq();
+DROP TRIGGER IF EXISTS labels_sync_seq ON labels;
+CREATE TRIGGER labels_sync_seq BEFORE INSERT OR UPDATE ON labels
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_seq();
+DROP TRIGGER IF EXISTS subtasks_sync_seq ON subtasks;
+CREATE TRIGGER subtasks_sync_seq BEFORE INSERT OR UPDATE ON subtasks
+    FOR EACH ROW EXECUTE FUNCTION bump_sync_seq();
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS subtasks_version ON subtasks;
+DROP TRIGGER IF EXISTS labels_version ON labels;
+DROP TRIGGER IF EXISTS projects_version ON projects;
+DROP TRIGGER IF EXISTS tasks_version ON tasks;
+
+ALTER TABLE subtasks DROP COLUMN IF EXISTS version;
+ALTER TABLE labels DROP COLUMN IF EXISTS version;
+ALTER TABLE projects DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
+
+DROP FUNCTION IF EXISTS bump_version();
@@ -0,0 +1,27 @@
+-- Optimistic concurrency: every update (including soft deletes) of a versioned
+-- row increments its version; clients send it back in If-Match or
+-- expected_version and get 412 / FAILED_PRECONDITION when it moved on.
+CREATE OR REPLACE FUNCTION bump_version() RETURNS trigger AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE labels ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+DROP TRIGGER IF EXISTS tasks_version ON tasks;
+CREATE TRIGGER tasks_version BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+DROP TRIGGER IF EXISTS projects_version ON projects;
+CREATE TRIGGER projects_version BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+DROP TRIGGER IF EXISTS labels_version ON labels;
+CREATE TRIGGER labels_version BEFORE UPDATE ON labels
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+DROP TRIGGER IF EXISTS subtasks_version ON subtasks;
+CREATE TRIGGER subtasks_version BEFORE UPDATE ON subtasks
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_revisions_version;
+
+ALTER TABLE task_revisions DROP COLUMN IF EXISTS label_ids;
+ALTER TABLE task_revisions DROP COLUMN IF EXISTS version;
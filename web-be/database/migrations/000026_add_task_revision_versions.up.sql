@@ -0,0 +1,6 @@
+-- Base snapshots for three-way merges: the task version each revision held
+-- and its labels. Older revisions keep version 0 and are never used as a base.
+ALTER TABLE task_revisions ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0;
+ALTER TABLE task_revisions ADD COLUMN IF NOT EXISTS label_ids JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_task_revisions_version ON task_revisions(task_id, version);
@@ -0,0 +1,14 @@
+-- responses to mutating requests sent with an Idempotency-Key (or gRPC request_id),
+-- replayed to retries; purged after IDEMPOTENCY_KEY_TTL
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL,  -- custom users.id or Supabase auth.users id
+    key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,  -- sha256 of method, path and body
+    status INT NOT NULL DEFAULT 0,  -- response status; 0 while the request is in progress
+    headers JSONB NOT NULL DEFAULT '{}',
+    body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_comments_body_trgm;
+DROP INDEX IF EXISTS idx_subtasks_title_trgm;
+DROP INDEX IF EXISTS idx_tasks_description_trgm;
+DROP INDEX IF EXISTS idx_tasks_title_trgm;
+
+DROP INDEX IF EXISTS idx_comments_search_vector;
+DROP INDEX IF EXISTS idx_subtasks_search_vector;
+DROP INDEX IF EXISTS idx_tasks_search_vector;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE subtasks DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector;
+
+-- pg_trgm is left installed; other objects may depend on it.
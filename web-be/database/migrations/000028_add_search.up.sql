@@ -0,0 +1,29 @@
+-- Full-text search (GET /search). Text is indexed with both the english
+-- configuration (stemmed) and simple (exact words, any language) so prefix
+-- queries match either. Chinese has no word breaks for the parser to use, so
+-- CJK queries go through the trigram indexes instead.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A')
+) STORED;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(body, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(body, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_subtasks_search_vector ON subtasks USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_comments_search_vector ON comments USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_title_trgm ON tasks USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tasks_description_trgm ON tasks USING GIN (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_subtasks_title_trgm ON subtasks USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_comments_body_trgm ON comments USING GIN (body gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- Recurring tasks: an RRULE subset (see service.ParseRecurrence), e.g.
+-- FREQ=MONTHLY;BYMONTHDAY=1;TZID=Asia/Taipei. Completing a recurring task
+-- moves its due date to the next occurrence instead of closing it.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- per-user secret iCalendar feed URLs (GET /calendar/:token.ics); only a
+-- sha256 of the token is kept, it is shown once when created or rotated
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY,  -- custom users.id or Supabase auth.users id
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+-- app passwords: generated per-app secrets for HTTP Basic auth where a bearer
+-- token cannot be used, such as CalDAV clients (/dav); only a sha256 is kept
+CREATE TABLE IF NOT EXISTS app_passwords (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,  -- custom users.id or Supabase auth.users id
+    name VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(64) NOT NULL UNIQUE,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_passwords_user_id ON app_passwords(user_id);
@@ -0,0 +1,7 @@
+-- iCalendar UID of tasks and subtasks created over CalDAV, which clients
+-- choose and name the resource after; empty means the UID is the id
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS ical_uid TEXT NOT NULL DEFAULT '';
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS ical_uid TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_ical_uid ON tasks(ical_uid) WHERE ical_uid <> '';
+CREATE INDEX IF NOT EXISTS idx_subtasks_ical_uid ON subtasks(ical_uid) WHERE ical_uid <> '';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_sending;
+UPDATE notifications SET email_status = 'failed' WHERE email_status = 'sending';
+UPDATE notifications SET push_status = 'failed' WHERE push_status = 'sending';
+ALTER TABLE notifications DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,7 @@
+-- the sender claims a batch by marking its deliveries 'sending' until
+-- claimed_until, then sends outside the claiming transaction; deliveries still
+-- 'sending' past their claim are given up on rather than sent twice
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_sending ON notifications(claimed_until)
+    WHERE email_status = 'sending' OR push_status = 'sending';
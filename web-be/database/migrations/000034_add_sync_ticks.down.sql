@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS sync_ticks;
+
+DROP TRIGGER IF EXISTS activities_seq ON activities;
+DROP FUNCTION IF EXISTS bump_activity_seq();
+
+CREATE OR REPLACE FUNCTION bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_seq := nextval('sync_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
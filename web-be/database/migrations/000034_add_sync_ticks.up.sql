@@ -0,0 +1,33 @@
+-- Settled sync positions: seqs are taken at write but committed later, so a
+-- token may only move past a seq once every transaction that could still
+-- commit one below it has ended. Writers take their transaction ID before
+-- the seq; a tick records the sequences' values and, read after them, the
+-- snapshot xmax. Once pg_snapshot_xmin passes a tick's xmax, every seq up to
+-- the tick's values is committed or rolled back.
+CREATE OR REPLACE FUNCTION bump_sync_seq() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_current_xact_id();
+    NEW.sync_seq := nextval('sync_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION bump_activity_seq() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_current_xact_id();
+    NEW.seq := nextval('activities_seq_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS activities_seq ON activities;
+CREATE TRIGGER activities_seq BEFORE INSERT ON activities
+    FOR EACH ROW EXECUTE FUNCTION bump_activity_seq();
+
+CREATE TABLE IF NOT EXISTS sync_ticks (
+    id BIGSERIAL PRIMARY KEY,
+    sync_seq BIGINT NOT NULL,
+    activity_seq BIGINT NOT NULL,
+    xmax xid8 NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
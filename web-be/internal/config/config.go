@@ -1,6 +1,12 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config holds application configuration.
 type Config struct {
@@ -18,10 +24,107 @@ type Config struct {
 	// Google Play
 	GooglePackageName       string
 	GoogleServiceAccountJSON string
+	// Task status transitions, from -> allowed targets (nil = service defaults)
+	TaskStatusTransitions map[string][]string
+	// Attachment blob storage: "local" (StorageLocalDir) or "s3"
+	StorageBackend    string
+	StorageLocalDir   string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	// Attachment limits in bytes
+	AttachmentMaxFileBytes     int64
+	AttachmentFreeQuotaBytes   int64
+	AttachmentPremiumQuotaBytes int64
+	// Task revisions kept per task, by owner tier
+	TaskRevisionsFree    int
+	TaskRevisionsPremium int
+	// Reminder and notification delivery; empty channel settings disable that channel
+	ReminderPollInterval   time.Duration
+	SMTPHost               string
+	SMTPPort               string
+	SMTPUsername           string
+	SMTPPassword           string
+	SMTPFrom               string
+	ReminderWebhookURL     string
+	ReminderWebhookSecret  string
+	APNsEndpoint           string
+	APNsKeyID              string
+	APNsTeamID             string
+	APNsTopic              string
+	APNsKey                string
+	FCMEndpoint            string
+	FCMTokenURI            string
+	FCMProjectID           string
+	FCMServiceAccountJSON  string
+	// Outbound webhooks
+	WebhookPollInterval         time.Duration
+	WebhookDisableAfter         int
+	WebhookAllowPrivateNetworks bool
+	// Live updates (GET /events)
+	EventsPollInterval      time.Duration
+	EventsHeartbeatInterval time.Duration
+	EventsReplayWindow      time.Duration
+	// How long responses to requests with an Idempotency-Key are kept for replay
+	IdempotencyKeyTTL time.Duration
 }
 
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
+	transitions, err := parseTransitions(getEnv("TASK_STATUS_TRANSITIONS", ""))
+	if err != nil {
+		return nil, err
+	}
+	maxFile, err := getEnvInt64("ATTACHMENT_MAX_FILE_BYTES", 25<<20)
+	if err != nil {
+		return nil, err
+	}
+	freeQuota, err := getEnvInt64("ATTACHMENT_FREE_QUOTA_BYTES", 100<<20)
+	if err != nil {
+		return nil, err
+	}
+	premiumQuota, err := getEnvInt64("ATTACHMENT_PREMIUM_QUOTA_BYTES", 10<<30)
+	if err != nil {
+		return nil, err
+	}
+	revisionsFree, err := getEnvInt64("TASK_REVISIONS_FREE", 10)
+	if err != nil {
+		return nil, err
+	}
+	revisionsPremium, err := getEnvInt64("TASK_REVISIONS_PREMIUM", 100)
+	if err != nil {
+		return nil, err
+	}
+	pollInterval, err := getEnvDuration("REMINDER_POLL_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	webhookInterval, err := getEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	webhookDisableAfter, err := getEnvInt64("WEBHOOK_DISABLE_AFTER_FAILURES", 15)
+	if err != nil {
+		return nil, err
+	}
+	eventsPoll, err := getEnvDuration("EVENTS_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	eventsHeartbeat, err := getEnvDuration("EVENTS_HEARTBEAT_INTERVAL", 20*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	eventsWindow, err := getEnvDuration("EVENTS_REPLAY_WINDOW", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	idempotencyTTL, err := getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
 		DatabaseURL:            getEnv("DATABASE_URL", "postgres://localhost:5432/todo?sslmode=disable"),
 		JWTSecret:              getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
@@ -34,9 +137,96 @@ func Load() (*Config, error) {
 		AppleSharedSecret:      getEnv("APPLE_SHARED_SECRET", ""),
 		GooglePackageName:      getEnv("GOOGLE_PACKAGE_NAME", ""),
 		GoogleServiceAccountJSON: getEnv("GOOGLE_SERVICE_ACCOUNT_JSON", ""),
+		TaskStatusTransitions:  transitions,
+		StorageBackend:         getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:        getEnv("STORAGE_LOCAL_DIR", "./data/attachments"),
+		S3Endpoint:             getEnv("S3_ENDPOINT", ""),
+		S3Region:               getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:               getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:          getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:      getEnv("S3_SECRET_ACCESS_KEY", ""),
+		AttachmentMaxFileBytes:     maxFile,
+		AttachmentFreeQuotaBytes:   freeQuota,
+		AttachmentPremiumQuotaBytes: premiumQuota,
+		TaskRevisionsFree:      int(revisionsFree),
+		TaskRevisionsPremium:   int(revisionsPremium),
+		ReminderPollInterval:   pollInterval,
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getEnv("SMTP_PORT", "587"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:               getEnv("SMTP_FROM", ""),
+		ReminderWebhookURL:     getEnv("REMINDER_WEBHOOK_URL", ""),
+		ReminderWebhookSecret:  getEnv("REMINDER_WEBHOOK_SECRET", ""),
+		APNsEndpoint:           getEnv("APNS_ENDPOINT", ""),
+		APNsKeyID:              getEnv("APNS_KEY_ID", ""),
+		APNsTeamID:             getEnv("APNS_TEAM_ID", ""),
+		APNsTopic:              getEnv("APNS_TOPIC", ""),
+		APNsKey:                getEnv("APNS_KEY", ""),
+		FCMEndpoint:            getEnv("FCM_ENDPOINT", ""),
+		FCMTokenURI:            getEnv("FCM_TOKEN_URI", ""),
+		FCMProjectID:           getEnv("FCM_PROJECT_ID", ""),
+		FCMServiceAccountJSON:  getEnv("FCM_SERVICE_ACCOUNT_JSON", ""),
+		WebhookPollInterval:    webhookInterval,
+		WebhookDisableAfter:    int(webhookDisableAfter),
+		WebhookAllowPrivateNetworks: getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "") == "true",
+		EventsPollInterval:      eventsPoll,
+		EventsHeartbeatInterval: eventsHeartbeat,
+		EventsReplayWindow:      eventsWindow,
+		IdempotencyKeyTTL:       idempotencyTTL,
 	}, nil
 }
 
+// parseTransitions parses "from:to1,to2;from2:to3" into a transition table.
+func parseTransitions(spec string) (map[string][]string, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	out := make(map[string][]string)
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		from, tos, ok := strings.Cut(rule, ":")
+		if !ok || strings.TrimSpace(from) == "" {
+			return nil, fmt.Errorf("TASK_STATUS_TRANSITIONS: invalid rule %q", rule)
+		}
+		from = strings.TrimSpace(from)
+		out[from] = []string{}
+		for _, to := range strings.Split(tos, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				out[from] = append(out[from], to)
+			}
+		}
+	}
+	return out, nil
+}
+
+func getEnvInt64(key string, defaultVal int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s: invalid non-negative integer %q", key, v)
+	}
+	return n, nil
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s: invalid positive duration %q", key, v)
+	}
+	return d, nil
+}
+
 func getEnv(key, defaultVal string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ActivityVO is the view object for an activity-log entry.
+type ActivityVO struct {
+	ID         string          `json:"id"`
+	ActorID    string          `json:"actor_id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	TaskID     *string         `json:"task_id,omitempty"`
+	ProjectID  *string         `json:"project_id,omitempty"`
+	Action     string          `json:"action"`
+	Changes    json.RawMessage `json:"changes" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// AppPasswordCreateRequest names a new app password after the app it is for.
+type AppPasswordCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// AppPasswordVO is the view object for an app password, without the password.
+type AppPasswordVO struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AppPasswordCreatedVO is a new app password with the credentials to enter
+// in the app; the password is only returned this once.
+type AppPasswordCreatedVO struct {
+	AppPasswordVO
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
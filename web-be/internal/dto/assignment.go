@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// TaskAssignRequest is the request body for assigning a task.
+type TaskAssignRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// TaskAssignmentVO is the view object for a task assignment.
+type TaskAssignmentVO struct {
+	TaskID    string    `json:"task_id"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// AttachmentVO is the view object for attachment.
+type AttachmentVO struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"task_id"`
+	CommentID   *string   `json:"comment_id,omitempty"`
+	UserID      string    `json:"user_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AttachmentUsageVO reports the caller's attachment storage use and limits, in bytes.
+type AttachmentUsageVO struct {
+	UsedBytes    int64 `json:"used_bytes"`
+	QuotaBytes   int64 `json:"quota_bytes"`
+	MaxFileBytes int64 `json:"max_file_bytes"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// CalendarFeedVO describes the caller's iCalendar feed. The token itself is
+// only returned when it is created or rotated.
+type CalendarFeedVO struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarFeedTokenVO is a newly created or rotated iCalendar feed token and
+// the URL to subscribe to. Filters may be added to the URL as query
+// parameters: project_id, label_id and component (VEVENT or VTODO).
+type CalendarFeedTokenVO struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
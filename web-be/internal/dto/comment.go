@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// CommentCreateRequest is the request body for creating a comment.
+// Body is markdown; @handle or @email mentions of project members are resolved.
+type CommentCreateRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentUpdateRequest is the request body for editing a comment.
+type CommentUpdateRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentVO is the view object for comment.
+type CommentVO struct {
+	ID        string     `json:"id"`
+	TaskID    *string    `json:"task_id,omitempty"`
+	ProjectID *string    `json:"project_id,omitempty"`
+	UserID    string     `json:"user_id"`
+	Body      string     `json:"body"`
+	Mentions  []string   `json:"mentions"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// DeviceRegisterRequest registers a push token of the calling app install.
+type DeviceRegisterRequest struct {
+	Platform   string `json:"platform" binding:"required,oneof=apns fcm"`
+	Token      string `json:"token" binding:"required,max=4096"`
+	AppVersion string `json:"app_version" binding:"max=50"`
+}
+
+// DeviceUnregisterRequest removes a push token, e.g. on sign-out.
+type DeviceUnregisterRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// DeviceVO is the view object for a registered push device.
+type DeviceVO struct {
+	ID         string    `json:"id"`
+	Platform   string    `json:"platform"`
+	Token      string    `json:"token"`
+	AppVersion string    `json:"app_version,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
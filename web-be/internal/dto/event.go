@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventVO is the data of a live update on GET /events. The SSE event name
+// is Type and the SSE id is the position to resume from.
+type EventVO struct {
+	ID         string          `json:"id"` // activity ID
+	Type       string          `json:"type"`
+	ActorID    string          `json:"actor_id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	TaskID     *string         `json:"task_id,omitempty"`
+	ProjectID  *string         `json:"project_id,omitempty"`
+	Changes    json.RawMessage `json:"changes" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// EventResetVO is the data of a reset event: the client missed events that
+// cannot be replayed and has to refetch.
+type EventResetVO struct {
+	Reason string `json:"reason"`
+}
@@ -12,10 +12,18 @@ type LabelUpdateRequest struct {
 	Color *string `json:"color"`
 }
 
+// LabelPatch holds the editable fields of a label for PATCH
+// /labels/{id}, a JSON merge patch: null clears a field.
+type LabelPatch struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
 // LabelVO is the view object for label.
 type LabelVO struct {
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Color  string `json:"color"`
-	UserID string `json:"user_id"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	UserID  string `json:"user_id"`
+	Version int    `json:"version"`
 }
@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// NotificationVO is the view object for an inbox notification.
+type NotificationVO struct {
+	ID        string     `json:"id"`
+	ActorID   *string    `json:"actor_id,omitempty"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body,omitempty"`
+	TaskID    *string    `json:"task_id,omitempty"`
+	ProjectID *string    `json:"project_id,omitempty"`
+	CommentID *string    `json:"comment_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationUnreadVO counts unread notifications, in total and by type.
+type NotificationUnreadVO struct {
+	Total  int64            `json:"total"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+// NotificationReadAllResponse reports how many notifications were marked read.
+type NotificationReadAllResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// NotificationPreferenceVO is the channels one notification type is delivered on.
+type NotificationPreferenceVO struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+	Push  bool   `json:"push"`
+}
+
+// NotificationPreferenceUpdateRequest changes the channels of a notification
+// type. Omitted channels keep their current setting.
+type NotificationPreferenceUpdateRequest struct {
+	InApp *bool `json:"in_app"`
+	Email *bool `json:"email"`
+	Push  *bool `json:"push"`
+}
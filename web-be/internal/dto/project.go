@@ -14,11 +14,20 @@ type ProjectUpdateRequest struct {
 	Color *string `json:"color"`
 }
 
+// ProjectPatch holds the editable fields of a project for PATCH
+// /projects/{id}, a JSON merge patch: null clears a field.
+type ProjectPatch struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+}
+
 // ProjectVO is the view object for project.
 type ProjectVO struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
 	Color     string    `json:"color"`
 	UserID    string    `json:"user_id"`
+	IsInbox   bool      `json:"is_inbox"`
 	CreatedAt time.Time `json:"created_at"`
+	Version   int       `json:"version"`
 }
@@ -0,0 +1,40 @@
+package dto
+
+// ProjectStatusCreateRequest defines a custom status column for a project.
+// Category is the built-in status it behaves as (pending, in_progress,
+// completed, cancelled). Using a built-in key with itself as category only
+// sets that column's name and WIP limit.
+type ProjectStatusCreateRequest struct {
+	Key      string `json:"key" binding:"required"`
+	Name     string `json:"name"`
+	Category string `json:"category" binding:"required"`
+	WIPLimit int    `json:"wip_limit"`
+}
+
+// ProjectStatusUpdateRequest is the request body for updating a status column.
+type ProjectStatusUpdateRequest struct {
+	Name     *string `json:"name"`
+	WIPLimit *int    `json:"wip_limit"`
+}
+
+// ProjectStatusVO is the view object for a status column.
+type ProjectStatusVO struct {
+	ID        string `json:"id,omitempty"`
+	ProjectID string `json:"project_id"`
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	WIPLimit  int    `json:"wip_limit"`
+}
+
+// StatusColumnVO is a status column with its tasks.
+type StatusColumnVO struct {
+	ProjectStatusVO
+	Tasks []TaskVO `json:"tasks"`
+}
+
+// StatusBoardVO is the status board view of a project: tasks grouped by status.
+type StatusBoardVO struct {
+	Project ProjectVO        `json:"project"`
+	Columns []StatusColumnVO `json:"columns"`
+}
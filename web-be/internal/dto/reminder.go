@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// ReminderCreateRequest is the request body for adding a reminder to a task.
+type ReminderCreateRequest struct {
+	RemindAt time.Time `json:"remind_at" binding:"required"`
+}
+
+// ReminderSnoozeRequest is the request body for snoozing a reminder. Set
+// either minutes from now or an absolute time.
+type ReminderSnoozeRequest struct {
+	Minutes int        `json:"minutes" binding:"omitempty,min=1,max=43200"`
+	Until   *time.Time `json:"until"`
+}
+
+// ReminderVO is the view object for reminder.
+type ReminderVO struct {
+	ID            string               `json:"id"`
+	TaskID        string               `json:"task_id"`
+	RemindAt      time.Time            `json:"remind_at"`
+	FromTask      bool                 `json:"from_task"`
+	Status        string               `json:"status"`
+	Attempts      int                  `json:"attempts"`
+	NextAttemptAt *time.Time           `json:"next_attempt_at,omitempty"`
+	LastError     string               `json:"last_error,omitempty"`
+	SentAt        *time.Time           `json:"sent_at,omitempty"`
+	SnoozeCount   int                  `json:"snooze_count"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Deliveries    []ReminderDeliveryVO `json:"deliveries"`
+}
+
+// ReminderDeliveryVO is the outcome of one delivery attempt over a channel.
+type ReminderDeliveryVO struct {
+	Channel   string    `json:"channel"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// SearchResultVO is a task, subtask or comment found by GET /search. Title
+// is the task's or subtask's title, or for a comment that of its task (empty
+// for a comment on a project). Snippet is the matching text, HTML-escaped,
+// with matches between <mark> and </mark>.
+type SearchResultVO struct {
+	Type      string    `json:"type"` // task, subtask or comment
+	ID        string    `json:"id"`
+	TaskID    *string   `json:"task_id,omitempty"`
+	ProjectID string    `json:"project_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+// SectionCreateRequest is the request body for creating a section.
+// New sections are appended after the project's last section.
+type SectionCreateRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SectionUpdateRequest is the request body for renaming a section.
+type SectionUpdateRequest struct {
+	Name *string `json:"name"`
+}
+
+// SectionMoveRequest places a section between two sibling sections.
+// Omit both IDs to move the section to the end.
+type SectionMoveRequest struct {
+	AfterID  string `json:"after_id"`
+	BeforeID string `json:"before_id"`
+}
+
+// SectionVO is the view object for section.
+type SectionVO struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+	SortKey   string `json:"sort_key"`
+}
+
+// BoardSectionVO is a section with its tasks in board order.
+type BoardSectionVO struct {
+	SectionVO
+	Tasks []TaskVO `json:"tasks"`
+}
+
+// BoardVO is the board view of a project: tasks grouped by section.
+type BoardVO struct {
+	Project     ProjectVO        `json:"project"`
+	Unsectioned []TaskVO         `json:"unsectioned"`
+	Sections    []BoardSectionVO `json:"sections"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// SubtaskCreateRequest is the data of a subtask create mutation.
+type SubtaskCreateRequest struct {
+	TaskID    string `json:"task_id" binding:"required"`
+	Title     string `json:"title" binding:"required"`
+	Completed bool   `json:"completed"`
+}
+
+// SubtaskUpdateRequest is the data of a subtask update mutation.
+type SubtaskUpdateRequest struct {
+	Title     *string `json:"title"`
+	Completed *bool   `json:"completed"`
+}
+
+// SubtaskVO is the view object for subtask.
+type SubtaskVO struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
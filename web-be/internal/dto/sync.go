@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SyncChangesVO is one page of GET /sync. Rows with deleted_at set are
+// tombstones.
+type SyncChangesVO struct {
+	Token    string          `json:"token"`
+	HasMore  bool            `json:"has_more"`
+	Tasks    []SyncTaskVO    `json:"tasks"`
+	Projects []SyncProjectVO `json:"projects"`
+	Labels   []SyncLabelVO   `json:"labels"`
+	Subtasks []SyncSubtaskVO `json:"subtasks"`
+}
+
+// SyncTaskVO is a task in a sync page.
+type SyncTaskVO struct {
+	TaskVO
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SyncProjectVO is a project in a sync page.
+type SyncProjectVO struct {
+	ProjectVO
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SyncLabelVO is a label in a sync page.
+type SyncLabelVO struct {
+	LabelVO
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SyncSubtaskVO is a subtask in a sync page.
+type SyncSubtaskVO struct {
+	SubtaskVO
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SyncMutation is one client change in POST /sync. ID is generated by the
+// client for creates. Data is the create or update request body of the
+// entity (e.g. TaskCreateRequest, SubtaskUpdateRequest) and is ignored for
+// deletes. An update or delete with ExpectedVersion fails unless the row is
+// still at that version.
+type SyncMutation struct {
+	Op              string          `json:"op" binding:"required,oneof=create update delete"`
+	Entity          string          `json:"entity" binding:"required,oneof=task project label subtask"`
+	ID              string          `json:"id" binding:"required"`
+	Data            json.RawMessage `json:"data" swaggertype:"object"`
+	ExpectedVersion *int            `json:"expected_version,omitempty"`
+}
+
+// SyncPushRequest is the request body of POST /sync. Mutations are applied
+// in order, all or none.
+type SyncPushRequest struct {
+	Mutations []SyncMutation `json:"mutations" binding:"required,min=1,max=500,dive"`
+}
+
+// SyncResultVO is the outcome of one mutation: applied, failed, or
+// rolled_back when another mutation of the batch failed.
+type SyncResultVO struct {
+	ID     string `json:"id"`
+	Op     string `json:"op"`
+	Entity string `json:"entity"`
+	Status string `json:"status"`
+	Code   int    `json:"code,omitempty"` // HTTP status of the failure
+	Error  string `json:"error,omitempty"`
+}
+
+// SyncPushResponse is the response of POST /sync.
+type SyncPushResponse struct {
+	Applied bool           `json:"applied"`
+	Results []SyncResultVO `json:"results"`
+}
@@ -7,13 +7,17 @@ type TaskCreateRequest struct {
 	Title       string    `json:"title" binding:"required"`
 	Description string    `json:"description"`
 	ProjectID   string    `json:"project_id"`
+	SectionID   string    `json:"section_id"`
 	Priority    int       `json:"priority"`
 	DueDate     *time.Time `json:"due_date"`
 	ReminderAt  *time.Time `json:"reminder_at"`
+	Recurrence  string    `json:"recurrence"` // e.g. FREQ=WEEKLY;BYDAY=MO,TH;TZID=Asia/Taipei
 	LabelIDs    []string  `json:"label_ids"`
 }
 
-// TaskUpdateRequest is the request body for updating a task.
+// TaskUpdateRequest is the request body for updating a task. With
+// BaseVersion, the version the client edited, changes made since are merged
+// rather than overwritten. LabelIDs replaces the task's labels.
 type TaskUpdateRequest struct {
 	Title       *string    `json:"title"`
 	Description *string    `json:"description"`
@@ -23,7 +27,39 @@ type TaskUpdateRequest struct {
 	Progress    *int       `json:"progress"`
 	DueDate     *time.Time `json:"due_date"`
 	ReminderAt  *time.Time `json:"reminder_at"`
+	Recurrence  *string    `json:"recurrence"` // "" stops the task repeating
 	LabelIDs    []string   `json:"label_ids"`
+	BaseVersion *int       `json:"base_version"`
+}
+
+// TaskPatch holds the editable fields of a task for PATCH /tasks/{id}, a
+// JSON merge patch: null clears a field (the Inbox for project_id, no labels
+// for label_ids, no repeating for recurrence). The patched result must still be a valid task.
+type TaskPatch struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	ProjectID   string     `json:"project_id"`
+	Priority    int        `json:"priority" binding:"min=0"`
+	Status      string     `json:"status" binding:"required"`
+	Progress    int        `json:"progress" binding:"min=0,max=100"`
+	DueDate     *time.Time `json:"due_date"`
+	ReminderAt  *time.Time `json:"reminder_at"`
+	Recurrence  string     `json:"recurrence"`
+	LabelIDs    []string   `json:"label_ids"`
+}
+
+// TaskMoveRequest is the request body for moving a task to another project.
+type TaskMoveRequest struct {
+	ProjectID string `json:"project_id"`
+}
+
+// TaskReorderRequest places a task within its project. SectionID omitted
+// keeps the current section, "" removes the task from any section.
+// AfterID/BeforeID name the neighbouring tasks; omit both to append.
+type TaskReorderRequest struct {
+	SectionID *string `json:"section_id"`
+	AfterID   string  `json:"after_id"`
+	BeforeID  string  `json:"before_id"`
 }
 
 // TaskVO is the view object for task.
@@ -32,13 +68,50 @@ type TaskVO struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	ProjectID   string    `json:"project_id"`
+	SectionID   *string   `json:"section_id,omitempty"`
+	SortKey     string    `json:"sort_key"`
 	UserID      string    `json:"user_id"`
 	Priority    int       `json:"priority"`
 	Status      string    `json:"status"`
 	Progress    int       `json:"progress"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	ReminderAt  *time.Time `json:"reminder_at,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
 	Labels      []LabelVO `json:"labels,omitempty"`
+	Blocked     bool       `json:"blocked"`
+	BlockedBy   []TaskRefVO `json:"blocked_by,omitempty"`
+}
+
+// TaskRefVO is a short reference to another task.
+type TaskRefVO struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// TaskBlockerRequest is the request body for adding a blocker to a task.
+type TaskBlockerRequest struct {
+	BlockerID string `json:"blocker_id" binding:"required"`
+}
+
+// TaskConflictVO is the response to a task update based on an older version
+// when a field it changes was also changed by someone else since.
+type TaskConflictVO struct {
+	Error     string            `json:"error"`
+	Version   int               `json:"version"` // current version, to base a resolved update on
+	Conflicts []FieldConflictVO `json:"conflicts"`
+}
+
+// FieldConflictVO is a field changed on both sides: its value at the base
+// version, the server's current value and the client's.
+type FieldConflictVO struct {
+	Field  string `json:"field"`
+	Base   any    `json:"base"`
+	Server any    `json:"server"`
+	Client any    `json:"client"`
 }
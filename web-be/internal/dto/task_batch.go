@@ -0,0 +1,49 @@
+package dto
+
+// Batch task operations.
+const (
+	TaskBatchUpdate       = "update"
+	TaskBatchComplete     = "complete"
+	TaskBatchDelete       = "delete"
+	TaskBatchMove         = "move"
+	TaskBatchAddLabels    = "add_labels"
+	TaskBatchRemoveLabels = "remove_labels"
+)
+
+// TaskBatchOperation is one operation of POST /tasks/batch, applied to each
+// task in TaskIDs. Data is required for update, ProjectID for move ("" moves
+// to the Inbox) and LabelIDs for add_labels and remove_labels.
+type TaskBatchOperation struct {
+	Op        string             `json:"op" binding:"required,oneof=update complete delete move add_labels remove_labels"`
+	TaskIDs   []string           `json:"task_ids" binding:"required,min=1"`
+	Data      *TaskUpdateRequest `json:"data"`
+	ProjectID *string            `json:"project_id"`
+	LabelIDs  []string           `json:"label_ids"`
+	Force     bool               `json:"force"` // complete even if the task has open blockers
+}
+
+// TaskBatchRequest is the request body of POST /tasks/batch. In atomic
+// mode (the default) the operations apply all or none; in per_item mode
+// each task succeeds or fails on its own.
+type TaskBatchRequest struct {
+	Mode       string               `json:"mode" binding:"omitempty,oneof=atomic per_item"`
+	Operations []TaskBatchOperation `json:"operations" binding:"required,min=1,max=50,dive"`
+}
+
+// TaskBatchResultVO is the outcome of one operation on one task: applied,
+// failed, or rolled_back when another item of an atomic batch failed.
+type TaskBatchResultVO struct {
+	Operation int    `json:"operation"` // index into operations
+	TaskID    string `json:"task_id"`
+	Status    string `json:"status"`
+	Code      int    `json:"code,omitempty"` // HTTP status of the failure
+	Error     string `json:"error,omitempty"`
+}
+
+// TaskBatchResponse is the response of POST /tasks/batch. Tasks holds the
+// tasks touched by the batch as they are afterwards, deleted ones left out.
+type TaskBatchResponse struct {
+	Applied bool                `json:"applied"`
+	Results []TaskBatchResultVO `json:"results"`
+	Tasks   []TaskVO            `json:"tasks"`
+}
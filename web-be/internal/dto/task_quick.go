@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// TaskQuickAddRequest is the request body of POST /tasks/quick. Timezone
+// is the IANA name of the user's time zone, which relative dates such as
+// "tomorrow 9am" are read in; it defaults to UTC.
+type TaskQuickAddRequest struct {
+	Text     string `json:"text" binding:"required,max=500"`
+	Timezone string `json:"timezone" example:"Asia/Taipei"`
+}
+
+// TaskQuickAddVO is what POST /tasks/quick read from the text, returned
+// instead of creating the task on a dry run.
+type TaskQuickAddVO struct {
+	Title       string                `json:"title"`
+	DueDate     *time.Time            `json:"due_date,omitempty"`
+	AllDay      bool                  `json:"all_day"` // no time was given; due at the end of the day
+	Recurrence  string                `json:"recurrence,omitempty"`
+	ProjectID   *string               `json:"project_id,omitempty"` // omitted for the Inbox
+	ProjectName string                `json:"project_name,omitempty"`
+	Labels      []TaskQuickAddLabelVO `json:"labels"`
+	Priority    int                   `json:"priority"`
+}
+
+// TaskQuickAddLabelVO is a label named in quick-add text. Labels that do not
+// exist yet have no ID and are created with the task.
+type TaskQuickAddLabelVO struct {
+	ID   *string `json:"id,omitempty"`
+	Name string  `json:"name"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// TaskRevisionVO is the view object for a previous version of a task.
+type TaskRevisionVO struct {
+	Revision    int        `json:"revision"`
+	TaskID      string     `json:"task_id"`
+	ActorID     string     `json:"actor_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	ProjectID   string     `json:"project_id"`
+	SectionID   *string    `json:"section_id,omitempty"`
+	Priority    int        `json:"priority"`
+	Status      string     `json:"status"`
+	Progress    int        `json:"progress"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	ReminderAt  *time.Time `json:"reminder_at,omitempty"`
+	Version     int        `json:"version"` // task version this revision held; 0 if unknown
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookCreateRequest subscribes a URL to events. Without project_id the
+// webhook is user-level. An omitted secret is generated.
+type WebhookCreateRequest struct {
+	URL       string   `json:"url" binding:"required,max=2048"`
+	Events    []string `json:"events"`
+	Secret    string   `json:"secret" binding:"max=255"`
+	ProjectID *string  `json:"project_id"`
+}
+
+// WebhookUpdateRequest changes a webhook. An empty secret generates a new one.
+type WebhookUpdateRequest struct {
+	URL     *string   `json:"url" binding:"omitempty,max=2048"`
+	Events  *[]string `json:"events"`
+	Secret  *string   `json:"secret" binding:"omitempty,max=255"`
+	Enabled *bool     `json:"enabled"`
+}
+
+// WebhookVO is the view object for webhook.
+type WebhookVO struct {
+	ID                  string     `json:"id"`
+	ProjectID           *string    `json:"project_id,omitempty"`
+	URL                 string     `json:"url"`
+	Events              []string   `json:"events"`
+	Secret              string     `json:"secret"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryVO is one entry in a webhook's delivery log.
+type WebhookDeliveryVO struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	ReplayOf       *string         `json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
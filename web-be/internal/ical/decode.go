@@ -0,0 +1,289 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformed is returned for data that is not iCalendar.
+var ErrMalformed = errors.New("ical: malformed data")
+
+// Component is a parsed component, e.g. a VCALENDAR with its VTODOs.
+type Component struct {
+	Name       string
+	Props      []*Prop
+	Components []*Component
+}
+
+// Prop is a parsed property. Names and parameter names are upper case;
+// Value is as written, still escaped.
+type Prop struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Decode parses data holding one component, unfolding its lines.
+func Decode(data []byte) (*Component, error) {
+	var lines []string
+	for _, l := range strings.Split(string(data), "\n") {
+		l = strings.TrimSuffix(l, "\r")
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+		} else if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	var root *Component
+	var stack []*Component
+	for _, l := range lines {
+		p, err := parseLine(l)
+		if err != nil {
+			return nil, err
+		}
+		switch p.Name {
+		case "BEGIN":
+			c := &Component{Name: strings.ToUpper(p.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			} else if root != nil {
+				return nil, fmt.Errorf("%w: more than one top-level component", ErrMalformed)
+			} else {
+				root = c
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrMalformed, p.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: property %s outside a component", ErrMalformed, p.Name)
+			}
+			c := stack[len(stack)-1]
+			c.Props = append(c.Props, p)
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, fmt.Errorf("%w: unterminated component", ErrMalformed)
+	}
+	return root, nil
+}
+
+// parseLine parses an unfolded content line: name, ;-separated parameters
+// with optionally quoted values, then : and the value.
+func parseLine(l string) (*Prop, error) {
+	end := strings.IndexAny(l, ";:")
+	if end <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrMalformed, l)
+	}
+	p := &Prop{Name: strings.ToUpper(l[:end]), Params: map[string]string{}}
+	rest := l[end:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrMalformed, l)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+		var values []string
+		for {
+			var v string
+			if strings.HasPrefix(rest, `"`) {
+				q := strings.IndexByte(rest[1:], '"')
+				if q < 0 {
+					return nil, fmt.Errorf("%w: %q", ErrMalformed, l)
+				}
+				v, rest = rest[1:q+1], rest[q+2:]
+			} else {
+				i := strings.IndexAny(rest, ",;:")
+				if i < 0 {
+					return nil, fmt.Errorf("%w: %q", ErrMalformed, l)
+				}
+				v, rest = rest[:i], rest[i:]
+			}
+			values = append(values, v)
+			if !strings.HasPrefix(rest, ",") {
+				break
+			}
+			rest = rest[1:]
+		}
+		p.Params[name] = strings.Join(values, ",")
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return nil, fmt.Errorf("%w: %q", ErrMalformed, l)
+	}
+	p.Value = rest[1:]
+	return p, nil
+}
+
+// Prop returns the first property called name, or nil.
+func (c *Component) Prop(name string) *Prop {
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// All returns the properties called name.
+func (c *Component) All(name string) []*Prop {
+	var out []*Prop
+	for _, p := range c.Props {
+		if p.Name == name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Children returns the subcomponents called name.
+func (c *Component) Children(name string) []*Component {
+	var out []*Component
+	for _, sub := range c.Components {
+		if sub.Name == name {
+			out = append(out, sub)
+		}
+	}
+	return out
+}
+
+// Text returns the value as unescaped TEXT.
+func (p *Prop) Text() string {
+	return unescapeText(p.Value)
+}
+
+// List returns the value as a list of TEXT, split at unescaped commas, e.g.
+// for CATEGORIES.
+func (p *Prop) List() []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(p.Value); i++ {
+		switch p.Value[i] {
+		case '\\':
+			i++
+		case ',':
+			out = append(out, unescapeText(p.Value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(out, unescapeText(p.Value[start:]))
+}
+
+// IsDate reports whether the value is a DATE rather than a DATE-TIME.
+func (p *Prop) IsDate() bool {
+	return strings.EqualFold(p.Params["VALUE"], "DATE") || len(p.Value) == len("20060102")
+}
+
+// Time parses a DATE or DATE-TIME value: in UTC when it ends in Z, else in
+// the location of its TZID parameter, else in floating. A DATE is midnight.
+// TZIDs that are not IANA time zone names, and floating times, are taken
+// to be in floating.
+func (p *Prop) Time(floating *time.Location) (time.Time, error) {
+	loc := floating
+	if l := p.Location(); l != nil {
+		loc = l
+	}
+	v := p.Value
+	switch {
+	case p.IsDate():
+		return time.ParseInLocation("20060102", v, loc)
+	case strings.HasSuffix(v, "Z"):
+		return time.Parse("20060102T150405Z", v)
+	default:
+		return time.ParseInLocation("20060102T150405", v, loc)
+	}
+}
+
+// Location returns the time zone of the TZID parameter, or nil when there is
+// none or it is not an IANA time zone name.
+func (p *Prop) Location() *time.Location {
+	if tzid := p.Params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			return l
+		}
+	}
+	return nil
+}
+
+// ParseDuration parses a DURATION value such as -PT15M or P1W.
+func ParseDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("%w: duration %q", ErrMalformed, orig)
+	}
+	s = s[1:]
+	var d time.Duration
+	inTime := false
+	for s != "" {
+		if s[0] == 'T' {
+			inTime, s = true, s[1:]
+			continue
+		}
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, fmt.Errorf("%w: duration %q", ErrMalformed, orig)
+		}
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("%w: duration %q", ErrMalformed, orig)
+		}
+		var unit time.Duration
+		switch {
+		case !inTime && s[i] == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && s[i] == 'D':
+			unit = 24 * time.Hour
+		case inTime && s[i] == 'H':
+			unit = time.Hour
+		case inTime && s[i] == 'M':
+			unit = time.Minute
+		case inTime && s[i] == 'S':
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("%w: duration %q", ErrMalformed, orig)
+		}
+		d += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return sign * d, nil
+}
+
+// unescapeText undoes Text.
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
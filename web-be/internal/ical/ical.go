@@ -0,0 +1,224 @@
+// Package ical reads and writes iCalendar (RFC 5545) data.
+//
+// Decode parses a component with its properties and subcomponents. An
+// Encoder writes content lines, folded at 75 octets and ended with CRLF;
+// values are written as given, so TEXT values go through Text first:
+//
+//	e := ical.NewEncoder(w)
+//	e.Begin("VCALENDAR")
+//	e.Prop("SUMMARY", ical.Text("Buy milk, eggs"))
+//	e.Prop("DUE", ical.UTC(due))
+//	e.End("VCALENDAR")
+//	err := e.Err()
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the longest a content line may be before folding.
+const maxLineOctets = 75
+
+// Param is a property parameter, e.g. TZID=Europe/Berlin.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Encoder writes content lines to a writer. The first write error is kept
+// and returned by Err; later writes are skipped.
+type Encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Begin starts a component, e.g. VEVENT.
+func (e *Encoder) Begin(name string) {
+	e.Prop("BEGIN", name)
+}
+
+// End ends a component.
+func (e *Encoder) End(name string) {
+	e.Prop("END", name)
+}
+
+// Prop writes a property. Parameter values with characters that need it
+// are quoted.
+func (e *Encoder) Prop(name, value string, params ...Param) {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, p := range params {
+		b.WriteString(";" + p.Name + "=")
+		if strings.ContainsAny(p.Value, ":;,") {
+			b.WriteString(`"` + strings.ReplaceAll(p.Value, `"`, "") + `"`)
+		} else {
+			b.WriteString(p.Value)
+		}
+	}
+	b.WriteString(":" + value)
+	e.line(b.String())
+}
+
+// Err flushes what was written and returns the first error.
+func (e *Encoder) Err() error {
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+// line writes s folded into lines of at most maxLineOctets octets, never
+// splitting a UTF-8 sequence; continuation lines start with a space.
+func (e *Encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if _, e.err = e.w.WriteString(s[:cut] + "\r\n "); e.err != nil {
+			return
+		}
+		s = s[cut:]
+		limit = maxLineOctets - 1
+	}
+	_, e.err = e.w.WriteString(s + "\r\n")
+}
+
+// Text escapes s as a TEXT value.
+func Text(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// UTC formats t as a DATE-TIME in UTC, e.g. 20261019T083000Z.
+func UTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Local formats t as a DATE-TIME in its own location, for use with a TZID
+// parameter naming it.
+func Local(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// Duration formats d as a DURATION value, e.g. -PT15M or P1DT2H.
+func Duration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteString("-")
+		d = -d
+	}
+	b.WriteString("P")
+	d = d.Truncate(time.Second)
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		if b.Len() <= 2 {
+			b.WriteString("T0S")
+		}
+		return b.String()
+	}
+	b.WriteString("T")
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		fmt.Fprintf(&b, "%dS", d/time.Second)
+	}
+	return b.String()
+}
+
+// Timezone writes a VTIMEZONE for loc with the offset changes between from
+// and to. Clients keep the last observance past to, so to should be well
+// beyond the dates the calendar uses.
+func (e *Encoder) Timezone(loc *time.Location, from, to time.Time) {
+	e.Begin("VTIMEZONE")
+	e.Prop("TZID", loc.String())
+	start := time.Date(from.In(loc).Year(), 1, 1, 0, 0, 0, 0, loc)
+	_, offset := start.Zone()
+	e.observance(start, offset)
+	for t := start; t.Before(to); {
+		next := nextTransition(t, to)
+		if next.IsZero() {
+			break
+		}
+		e.observance(next, offset)
+		_, offset = next.Zone()
+		t = next
+	}
+	e.End("VTIMEZONE")
+}
+
+// observance writes the STANDARD or DAYLIGHT observance starting at t, when
+// the offset changes from prev.
+func (e *Encoder) observance(t time.Time, prev int) {
+	name, offset := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	e.Begin(kind)
+	// DTSTART is the local time the change happens at, on the old offset.
+	e.Prop("DTSTART", Local(t.UTC().Add(time.Duration(prev)*time.Second)))
+	e.Prop("TZOFFSETFROM", formatOffset(prev))
+	e.Prop("TZOFFSETTO", formatOffset(offset))
+	if name != "" && !strings.ContainsAny(name, "+-") {
+		e.Prop("TZNAME", Text(name))
+	}
+	e.End(kind)
+}
+
+// nextTransition returns the first instant after t and before to at which
+// the UTC offset or abbreviation of t's location changes, or the zero time.
+func nextTransition(t, to time.Time) time.Time {
+	name, offset := t.Zone()
+	for {
+		_, end := t.ZoneBounds()
+		if end.IsZero() || !end.Before(to) {
+			return time.Time{}
+		}
+		// A zone period can end without a visible change, e.g. when only
+		// the DST flag flips.
+		if n, o := end.Zone(); n != name || o != offset {
+			return end
+		}
+		t = end
+	}
+}
+
+// formatOffset formats a UTC offset in seconds as a UTC-OFFSET value, e.g.
+// +0530.
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
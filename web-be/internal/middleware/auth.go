@@ -62,6 +62,20 @@ func Auth(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// QueryToken lets clients that cannot set headers, such as a browser
+// EventSource, pass the bearer token as the access_token query parameter.
+// It must run before Auth.
+func QueryToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
+
 // verifySupabaseJWT validates a Supabase-issued JWT and returns the user ID (sub claim).
 func verifySupabaseJWT(tokenStr, secret string) (string, error) {
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+// AppPasswordAuth authenticates HTTP Basic credentials against the users' app
+// passwords, for clients such as CalDAV apps that cannot use bearer tokens.
+// Failures get a Basic challenge in realm.
+func AppPasswordAuth(db *gorm.DB, realm string) gin.HandlerFunc {
+	challenge := `Basic realm="` + realm + `", charset="UTF-8"`
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		userID, err := service.AuthenticateAppPassword(db, username, password)
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			c.Header("WWW-Authenticate", challenge)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Set("user_id", userID)
+		c.Set("auth_provider", "app_password")
+		c.Next()
+	}
+}
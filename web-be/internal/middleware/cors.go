@@ -9,8 +9,10 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		if c.Request.Method == "OPTIONS" {
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, Last-Event-ID, If-Match, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag, Idempotent-Replayed")
+		// Only preflights; other OPTIONS requests, e.g. from CalDAV clients, go on to their route
+		if c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != "" {
 			c.AbortWithStatus(204)
 			return
 		}
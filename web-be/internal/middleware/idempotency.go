@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/todo-tracking-app/web-be/internal/service"
+)
+
+const (
+	// maxIdempotencyKeyLen is the longest Idempotency-Key accepted.
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBody is the largest request body hashed for a key.
+	maxIdempotentBody = 1 << 20
+	// maxStoredResponse is the largest response body kept for replay.
+	maxStoredResponse = 1 << 20
+)
+
+// replayedHeaders are the response headers stored with an idempotent
+// response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests sent with an
+// Idempotency-Key header safe to retry: the first response is stored per
+// user and key, and a retry with the same method, path and body gets it back
+// with Idempotent-Replayed: true. Reusing a key for a different request is
+// rejected with 422. Server errors are not stored, so they can be retried.
+// A response over maxStoredResponse is stored without its body: a retry gets
+// the status and headers back rather than running the request again. Keyed
+// request bodies are limited to maxIdempotentBody (413 above it); multipart
+// uploads are passed through without a key, as their bodies are too large to
+// hold. It must run after Auth.
+func Idempotency(keys *service.IdempotencyKeys) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			key = ""
+		}
+		if key == "" || strings.HasPrefix(c.ContentType(), "multipart/") {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+		if c.Request.ContentLength > maxIdempotentBody {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large for an Idempotency-Key"})
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large for an Idempotency-Key"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		h := sha256.New()
+		io.WriteString(h, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+		h.Write(body)
+
+		userID := c.GetString("user_id")
+		ctx := context.WithoutCancel(c.Request.Context())
+		rec, err := keys.Begin(ctx, userID, key, hex.EncodeToString(h.Sum(nil)))
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyInFlight):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if rec != nil {
+			var headers map[string]string
+			_ = json.Unmarshal([]byte(rec.Headers), &headers)
+			for name, v := range headers {
+				c.Header(name, v)
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Status(rec.Status)
+			c.Writer.Write(rec.Body)
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			err = keys.Release(ctx, userID, key)
+		} else {
+			headers := make(map[string]string)
+			for _, name := range replayedHeaders {
+				// A truncated body is stored empty, so its type is dropped.
+				if v := w.Header().Get(name); v != "" && !(w.truncated && name == "Content-Type") {
+					headers[name] = v
+				}
+			}
+			err = keys.Complete(ctx, userID, key, w.Status(), headers, w.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("idempotency: store %s for %s: %v", key, userID, err)
+		}
+	}
+}
+
+// recordingWriter keeps a copy of the response body, up to
+// maxStoredResponse; truncated is set once it outgrows that.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.record(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(b []byte) {
+	if w.truncated || w.body.Len()+len(b) > maxStoredResponse {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
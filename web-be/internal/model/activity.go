@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Activity actions.
+const (
+	ActivityCreate = "create"
+	ActivityUpdate = "update"
+	ActivityDelete = "delete"
+)
+
+// Activity is one audit-log entry: actor changed an entity. TaskID and
+// ProjectID are the task and project the entity belonged to at the time, so
+// the entry shows up in their activity feeds. Seq is assigned by the
+// database and orders entries for the live event stream.
+type Activity struct {
+	ID         string    `gorm:"primaryKey;type:uuid"`
+	Seq        int64     `gorm:"->"`
+	ActorID    string    `gorm:"type:uuid;index;not null"`
+	EntityType string    `gorm:"size:30;not null"` // task, project, section, comment, ...
+	EntityID   string    `gorm:"type:uuid;not null"`
+	TaskID     *string   `gorm:"type:uuid;index"`
+	ProjectID  *string   `gorm:"type:uuid;index"`
+	Action     string    `gorm:"size:20;not null"`
+	Changes    string    `gorm:"type:jsonb;not null"` // {"field": {"old": ..., "new": ...}}
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (Activity) TableName() string {
+	return "activities"
+}
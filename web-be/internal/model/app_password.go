@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// AppPassword is a generated password a user gives one app, such as a CalDAV
+// client, to sign in with HTTP Basic auth. Only its SHA-256 is stored.
+type AppPassword struct {
+	ID           string     `gorm:"primaryKey;type:uuid"`
+	UserID       string     `gorm:"type:uuid;index;not null"`
+	Name         string     `gorm:"size:100;not null"`
+	PasswordHash string     `gorm:"size:64;uniqueIndex;not null"`
+	LastUsedAt   *time.Time `gorm:"type:timestamptz"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (AppPassword) TableName() string {
+	return "app_passwords"
+}
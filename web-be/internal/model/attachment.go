@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Attachment is a file uploaded to a task, optionally as part of one of its comments.
+type Attachment struct {
+	ID          string    `gorm:"primaryKey;type:uuid"`
+	TaskID      string    `gorm:"type:uuid;index;not null"`
+	CommentID   *string   `gorm:"type:uuid;index"`
+	UserID      string    `gorm:"type:uuid;index;not null"` // uploader, charged against their quota
+	FileName    string    `gorm:"not null"`
+	ContentType string    `gorm:"not null"` // sniffed from the content, not client-supplied
+	Size        int64     `gorm:"not null"`
+	StorageKey  string    `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (Attachment) TableName() string {
+	return "attachments"
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// CalendarFeed is a user's secret iCalendar feed URL. Only the SHA-256 of
+// the token in the URL is stored; rotating replaces it.
+type CalendarFeed struct {
+	UserID    string    `gorm:"primaryKey;type:uuid"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
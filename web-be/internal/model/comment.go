@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a markdown comment on a task or, when TaskID is nil, on a project.
+type Comment struct {
+	ID        string         `gorm:"primaryKey;type:uuid"`
+	TaskID    *string        `gorm:"type:uuid;index"`
+	ProjectID *string        `gorm:"type:uuid;index"`
+	UserID    string         `gorm:"type:uuid;index;not null"` // author
+	Body      string         `gorm:"type:text;not null"`
+	EditedAt  *time.Time     `gorm:"type:timestamptz"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Mentions []CommentMention `gorm:"foreignKey:CommentID"`
+}
+
+// TableName overrides the table name.
+func (Comment) TableName() string {
+	return "comments"
+}
+
+// CommentMention records a user @mentioned in a comment.
+type CommentMention struct {
+	CommentID string    `gorm:"primaryKey;type:uuid"`
+	UserID    string    `gorm:"primaryKey;type:uuid;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (CommentMention) TableName() string {
+	return "comment_mentions"
+}
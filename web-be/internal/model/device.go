@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Device is a mobile app install registered for push notifications. A token
+// belongs to one user at a time; re-registering it moves it.
+type Device struct {
+	ID         string    `gorm:"primaryKey;type:uuid"`
+	UserID     string    `gorm:"type:uuid;index;not null"`
+	Platform   string    `gorm:"size:10;not null"` // apns or fcm
+	Token      string    `gorm:"uniqueIndex;not null"`
+	AppVersion string    `gorm:"size:50"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name.
+func (Device) TableName() string {
+	return "devices"
+}
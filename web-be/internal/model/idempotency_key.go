@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// IdempotencyKey is the stored outcome of a mutating request sent with an
+// Idempotency-Key header or a gRPC request_id, replayed when it is retried.
+type IdempotencyKey struct {
+	UserID      string    `gorm:"primaryKey;type:uuid"`
+	Key         string    `gorm:"primaryKey;size:255"`
+	RequestHash string    `gorm:"size:64;not null"`
+	Status      int       `gorm:"not null;default:0"`               // 0 while the request is in progress
+	Headers     string    `gorm:"type:jsonb;not null;default:'{}'"` // replayed response headers
+	Body        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName overrides the table name.
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -15,6 +15,8 @@ type Label struct {
 	CreatedAt time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
+	SyncSeq   int64          `gorm:"->"` // set by the database on every write, see GET /sync
+	Version   int            `gorm:"->"` // incremented by the database on every update
 }
 
 // TableName overrides the table name.
@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// Notification types.
+const (
+	NotificationAssignment = "assignment"
+	NotificationMention    = "mention"
+	NotificationComment    = "comment"
+	NotificationReminder   = "reminder"
+)
+
+// NotificationTypes lists every notification type, in display order.
+var NotificationTypes = []string{NotificationAssignment, NotificationMention, NotificationComment, NotificationReminder}
+
+// Delivery statuses of a notification on the email and push channels. An
+// empty status means the channel was not requested.
+const (
+	DeliveryPending = "pending"
+	DeliverySending = "sending" // claimed by a sender until ClaimedUntil
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliverySkipped = "skipped"
+)
+
+// Notification is something that happened which a user should hear about.
+// InApp rows appear in the user's inbox; EmailStatus and PushStatus track
+// delivery on the other channels the user's preferences selected.
+type Notification struct {
+	ID           string     `gorm:"primaryKey;type:uuid"`
+	UserID       string     `gorm:"type:uuid;index;not null"` // recipient
+	ActorID      *string    `gorm:"type:uuid"`
+	Type         string     `gorm:"size:20;not null"`
+	Title        string     `gorm:"size:500;not null"`
+	Body         string     `gorm:"type:text"`
+	TaskID       *string    `gorm:"type:uuid"`
+	ProjectID    *string    `gorm:"type:uuid"`
+	CommentID    *string    `gorm:"type:uuid"`
+	InApp        bool       `gorm:"not null;default:true"`
+	EmailStatus  string     `gorm:"size:20;not null;default:''"`
+	PushStatus   string     `gorm:"size:20;not null;default:''"`
+	ClaimedUntil *time.Time `gorm:"type:timestamptz"` // while deliveries are sending
+	ReadAt       *time.Time `gorm:"type:timestamptz"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference selects the channels a user receives one type of
+// notification on. Types without a row use DefaultNotificationPreference.
+type NotificationPreference struct {
+	UserID    string    `gorm:"primaryKey;type:uuid"`
+	Type      string    `gorm:"primaryKey;size:20"`
+	InApp     bool      `gorm:"not null"`
+	Email     bool      `gorm:"not null"`
+	Push      bool      `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name.
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference is the preference of userID for typ when they
+// have not set one: in-app and push for everything, email for reminders only.
+func DefaultNotificationPreference(userID, typ string) NotificationPreference {
+	return NotificationPreference{
+		UserID: userID,
+		Type:   typ,
+		InApp:  true,
+		Email:  typ == NotificationReminder,
+		Push:   true,
+	}
+}
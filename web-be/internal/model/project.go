@@ -12,12 +12,16 @@ type Project struct {
 	Name      string         `gorm:"not null"`
 	Color     string         `gorm:"size:7"`
 	UserID    string         `gorm:"type:uuid;index;not null"`
+	IsInbox   bool           `gorm:"not null;default:false"` // default project, one per user, cannot be deleted
 	CreatedAt time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
+	SyncSeq   int64          `gorm:"->"` // set by the database on every write, see GET /sync
+	Version   int            `gorm:"->"` // incremented by the database on every update
 
-	Tasks   []Task          `gorm:"foreignKey:ProjectID"`
-	Members []ProjectMember `gorm:"foreignKey:ProjectID"`
+	Tasks    []Task          `gorm:"foreignKey:ProjectID"`
+	Sections []Section       `gorm:"foreignKey:ProjectID"`
+	Members  []ProjectMember `gorm:"foreignKey:ProjectID"`
 }
 
 // TableName overrides the table name.
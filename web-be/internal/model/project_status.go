@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// ProjectStatus is a board column of a project: either a custom task status
+// mapped onto one of the built-in TaskStatus* categories, or a built-in status
+// given a display name and WIP limit.
+type ProjectStatus struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	ProjectID string    `gorm:"type:uuid;uniqueIndex:idx_project_statuses_key;not null"`
+	Key       string    `gorm:"size:20;uniqueIndex:idx_project_statuses_key;not null"` // value stored in tasks.status
+	Name      string    `gorm:"not null"`
+	Category  string    `gorm:"size:20;not null"`                    // pending, in_progress, completed, cancelled
+	WIPLimit  int       `gorm:"column:wip_limit;not null;default:0"` // 0 = unlimited
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name.
+func (ProjectStatus) TableName() string {
+	return "project_statuses"
+}
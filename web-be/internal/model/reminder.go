@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Reminder statuses.
+const (
+	ReminderPending   = "pending"
+	ReminderSent      = "sent"
+	ReminderFailed    = "failed"
+	ReminderCancelled = "cancelled"
+)
+
+// Reminder is a notification due for a user about a task. A task may have
+// several; the one with FromTask set mirrors Task.ReminderAt.
+type Reminder struct {
+	ID            string     `gorm:"primaryKey;type:uuid"`
+	TaskID        string     `gorm:"type:uuid;index;not null"`
+	UserID        string     `gorm:"type:uuid;index;not null"` // recipient
+	RemindAt      time.Time  `gorm:"type:timestamptz;not null"`
+	FromTask      bool       `gorm:"not null;default:false"`
+	Status        string     `gorm:"size:20;not null;default:pending"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time `gorm:"type:timestamptz"` // retry backoff after a failed delivery
+	LastError     string     `gorm:"type:text"`
+	SentAt        *time.Time `gorm:"type:timestamptz"`
+	SnoozeCount   int        `gorm:"not null;default:0"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+
+	Deliveries []ReminderDelivery `gorm:"foreignKey:ReminderID"`
+}
+
+// TableName overrides the table name.
+func (Reminder) TableName() string {
+	return "reminders"
+}
+
+// ReminderDelivery records one attempt to deliver a reminder over a channel.
+type ReminderDelivery struct {
+	ID         string    `gorm:"primaryKey;type:uuid"`
+	ReminderID string    `gorm:"type:uuid;index;not null"`
+	Channel    string    `gorm:"size:20;not null"`
+	Status     string    `gorm:"size:20;not null"` // sent, failed or skipped
+	Error      string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (ReminderDelivery) TableName() string {
+	return "reminder_deliveries"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Section represents a column/group of tasks inside a project.
+type Section struct {
+	ID        string         `gorm:"primaryKey;type:uuid"`
+	ProjectID string         `gorm:"type:uuid;index;not null"`
+	Name      string         `gorm:"not null"`
+	SortKey   string         `gorm:"type:text;not null;default:''"` // fractional index, see service.KeyBetween
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Tasks []Task `gorm:"foreignKey:SectionID"`
+}
+
+// TableName overrides the table name.
+func (Section) TableName() string {
+	return "sections"
+}
@@ -12,9 +12,12 @@ type Subtask struct {
 	TaskID    string         `gorm:"type:uuid;index;not null"`
 	Title     string         `gorm:"not null"`
 	Completed bool           `gorm:"default:false"`
+	ICalUID   string         `gorm:"column:ical_uid;type:text;not null;default:''"` // UID given by a CalDAV client; empty means ID
 	CreatedAt time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
+	SyncSeq   int64          `gorm:"->"` // set by the database on every write, see GET /sync
+	Version   int            `gorm:"->"` // incremented by the database on every update
 }
 
 // TableName overrides the table name.
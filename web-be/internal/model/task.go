@@ -20,17 +20,26 @@ type Task struct {
 	Title       string          `gorm:"not null"`
 	Description string          `gorm:"type:text"`
 	ProjectID   string          `gorm:"type:uuid;index"`
+	SectionID   *string         `gorm:"type:uuid;index"`
+	SortKey     string          `gorm:"type:text;not null;default:''"` // fractional index within project/section
 	UserID      string          `gorm:"type:uuid;index;not null"`
 	Priority    int             `gorm:"default:0"` // 0=none, 1=p4, 2=p3, 3=p2, 4=p1
 	Status      string          `gorm:"size:20;default:pending"`
 	DueDate     *time.Time      `gorm:"type:timestamptz"`
 	ReminderAt  *time.Time      `gorm:"type:timestamptz"`
+	Recurrence  string          `gorm:"type:text;not null;default:''"` // RRULE subset, see service.ParseRecurrence
+	ICalUID     string          `gorm:"column:ical_uid;type:text;not null;default:''"` // UID given by a CalDAV client; empty means ID
 	Progress    int             `gorm:"default:0"` // 0-100
+	StartedAt   *time.Time      `gorm:"type:timestamptz"` // first entered in_progress
+	CompletedAt *time.Time      `gorm:"type:timestamptz;index"` // set while status is completed
 	CreatedAt   time.Time       `gorm:"autoCreateTime"`
 	UpdatedAt   time.Time       `gorm:"autoUpdateTime"`
 	DeletedAt   gorm.DeletedAt  `gorm:"index"`
+	SyncSeq     int64           `gorm:"->"` // set by the database on every write, see GET /sync
+	Version     int             `gorm:"->"` // incremented by the database on every update
 
 	Project      *Project         `gorm:"foreignKey:ProjectID"`
+	Section      *Section         `gorm:"foreignKey:SectionID"`
 	Labels       []Label          `gorm:"many2many:task_labels;"`
 	Subtasks     []Subtask        `gorm:"foreignKey:TaskID"`
 	Assignments  []TaskAssignment `gorm:"foreignKey:TaskID"`
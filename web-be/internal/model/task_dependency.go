@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// TaskDependency records that TaskID is blocked by BlockerID.
+type TaskDependency struct {
+	TaskID    string    `gorm:"primaryKey;type:uuid"`
+	BlockerID string    `gorm:"primaryKey;type:uuid;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
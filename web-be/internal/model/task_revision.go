@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// TaskRevision is a snapshot of a task's fields as they were before an update.
+// Revision numbers increase per task starting at 1.
+type TaskRevision struct {
+	ID          string  `gorm:"primaryKey;type:uuid"`
+	TaskID      string  `gorm:"type:uuid;not null;uniqueIndex:idx_task_revisions_task_rev"`
+	Revision    int     `gorm:"not null;uniqueIndex:idx_task_revisions_task_rev"`
+	ActorID     string  `gorm:"type:uuid;not null"` // who made the update that replaced this version
+	Title       string  `gorm:"not null"`
+	Description string  `gorm:"type:text"`
+	ProjectID   string  `gorm:"type:uuid"`
+	SectionID   *string `gorm:"type:uuid"`
+	Priority    int
+	Status      string `gorm:"size:20"`
+	Progress    int
+	DueDate     *time.Time `gorm:"type:timestamptz"`
+	ReminderAt  *time.Time `gorm:"type:timestamptz"`
+	Version     int        `gorm:"not null"`                         // task version this snapshot held; 0 if unknown
+	LabelIDs    string     `gorm:"type:jsonb;not null;default:'[]'"` // JSON array of label IDs
+	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name.
+func (TaskRevision) TableName() string {
+	return "task_revisions"
+}
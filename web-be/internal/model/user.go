@@ -22,3 +22,8 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// HasPremium reports whether the user's premium tier is active at now.
+func (u User) HasPremium(now time.Time) bool {
+	return u.IsPremium && (u.PremiumExpiresAt == nil || u.PremiumExpiresAt.After(now))
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription to task and project events. A user-level
+// webhook (ProjectID nil) receives events in every project its owner can
+// see plus the owner's own changes; a project-level one only that project's.
+type Webhook struct {
+	ID                  string     `gorm:"primaryKey;type:uuid"`
+	UserID              string     `gorm:"type:uuid;index;not null"` // owner
+	ProjectID           *string    `gorm:"type:uuid;index"`
+	URL                 string     `gorm:"size:2048;not null"`
+	Events              string     `gorm:"type:jsonb;not null"` // event types; empty means all
+	Secret              string     `gorm:"size:255;not null"`
+	ConsecutiveFailures int        `gorm:"not null;default:0"`
+	DisabledAt          *time.Time `gorm:"type:timestamptz"`
+	DisabledReason      string     `gorm:"type:text"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name.
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDelivery is one event queued for, or delivered to, a webhook. It
+// records the latest attempt; a replay is a new delivery with ReplayOf set.
+type WebhookDelivery struct {
+	ID             string     `gorm:"primaryKey;type:uuid"`
+	WebhookID      string     `gorm:"type:uuid;index;not null"`
+	EventID        string     `gorm:"type:uuid;not null"` // the activity that caused it
+	EventType      string     `gorm:"size:50;not null"`
+	Payload        string     `gorm:"type:jsonb;not null"`
+	Status         string     `gorm:"size:20;not null;default:pending"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `gorm:"type:timestamptz;not null"`
+	ResponseStatus int        `gorm:"not null;default:0"`
+	ResponseBody   string     `gorm:"type:text"`
+	Error          string     `gorm:"type:text"`
+	DeliveredAt    *time.Time `gorm:"type:timestamptz"`
+	ReplayOf       *string    `gorm:"type:uuid"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+// TableName overrides the table name.
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned when a push provider reports that a device
+// token is no longer valid and should be forgotten.
+var ErrInvalidToken = errors.New("notify: invalid device token")
+
+// APNsConfig configures APNsClient. Endpoint defaults to Apple's production
+// gateway; point it at https://api.sandbox.push.apple.com or a local fake.
+type APNsConfig struct {
+	Endpoint string
+	KeyID    string
+	TeamID   string
+	Topic    string // app bundle ID
+	KeyPEM   string // contents of the .p8 signing key
+}
+
+// APNsClient sends notifications through the APNs HTTP/2 provider API using
+// token-based (JWT) authentication.
+type APNsClient struct {
+	cfg    APNsConfig
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mu       sync.Mutex
+	bearer   string
+	issuedAt time.Time
+}
+
+// NewAPNsClient parses the signing key and returns a client.
+func NewAPNsClient(cfg APNsConfig) (*APNsClient, error) {
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(cfg.KeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("apns: signing key: %w", err)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://api.push.apple.com"
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &APNsClient{cfg: cfg, key: key, client: &http.Client{Timeout: 15 * time.Second}}, nil
+}
+
+// token returns the provider JWT, refreshed well within Apple's one-hour limit.
+func (c *APNsClient) token() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bearer != "" && time.Since(c.issuedAt) < 40*time.Minute {
+		return c.bearer, nil
+	}
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"iss": c.cfg.TeamID, "iat": now.Unix()})
+	t.Header["kid"] = c.cfg.KeyID
+	signed, err := t.SignedString(c.key)
+	if err != nil {
+		return "", err
+	}
+	c.bearer, c.issuedAt = signed, now
+	return signed, nil
+}
+
+// Send delivers msg to one device token.
+func (c *APNsClient) Send(ctx context.Context, deviceToken string, msg Message) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	bearer, err := c.token()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Endpoint+"/3/device/"+deviceToken, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", c.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var reason struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&reason)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		reason.Reason == "BadDeviceToken", reason.Reason == "Unregistered", reason.Reason == "DeviceTokenNotForTopic":
+		return fmt.Errorf("%w: apns %s", ErrInvalidToken, reason.Reason)
+	}
+	return fmt.Errorf("apns: %s: %s", resp.Status, reason.Reason)
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig configures EmailNotifier.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// EmailNotifier sends plain-text mail through an SMTP relay.
+type EmailNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewEmailNotifier returns an EmailNotifier for cfg.
+func NewEmailNotifier(cfg SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (n *EmailNotifier) Channel() string { return ChannelEmail }
+
+func (n *EmailNotifier) Notify(_ context.Context, msg Message) error {
+	if msg.Email == "" {
+		return ErrNoRecipient
+	}
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	return smtp.SendMail(net.JoinHostPort(n.cfg.Host, n.cfg.Port), auth, n.cfg.From, []string{msg.Email},
+		buildMail(n.cfg.From, msg.Email, msg.Title, msg.Body))
+}
+
+// buildMail renders a minimal RFC 5322 message. Header values are stripped of
+// line breaks so a task title cannot inject headers.
+func buildMail(from, to, subject, body string) []byte {
+	clean := strings.NewReplacer("\r", " ", "\n", " ")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", clean.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", clean.Replace(to))
+	fmt.Fprintf(&b, "Subject: %s\r\n", clean.Replace(subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
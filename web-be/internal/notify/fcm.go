@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMConfig configures FCMClient. ServiceAccountJSON is a Google service
+// account key; access tokens are fetched from its token_uri unless TokenURI
+// overrides it, so a fake server can stand in for both Google OAuth and FCM.
+type FCMConfig struct {
+	Endpoint           string // default https://fcm.googleapis.com
+	TokenURI           string // default: token_uri from the service account
+	ProjectID          string // default: project_id from the service account
+	ServiceAccountJSON string
+}
+
+// FCMClient sends notifications through the FCM HTTP v1 API.
+type FCMClient struct {
+	endpoint  string
+	projectID string
+	email     string
+	tokenURI  string
+	key       *rsa.PrivateKey
+	client    *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMClient parses the service account and returns a client.
+func NewFCMClient(cfg FCMConfig) (*FCMClient, error) {
+	var sa struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal([]byte(cfg.ServiceAccountJSON), &sa); err != nil {
+		return nil, fmt.Errorf("fcm: service account: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(sa.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("fcm: service account key: %w", err)
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://fcm.googleapis.com"
+	}
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = sa.ProjectID
+	}
+	if cfg.TokenURI != "" {
+		sa.TokenURI = cfg.TokenURI
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &FCMClient{
+		endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
+		projectID: cfg.ProjectID,
+		email:     sa.ClientEmail,
+		tokenURI:  sa.TokenURI,
+		key:       key,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// token returns an OAuth access token from the service-account JWT grant,
+// cached until shortly before it expires.
+func (c *FCMClient) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.email,
+		"scope": fcmScope,
+		"aud":   c.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(c.key)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("fcm: token: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("fcm: token: %w", err)
+	}
+	c.accessToken = tok.AccessToken
+	c.expiresAt = now.Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
+
+// Send delivers msg to one registration token.
+func (c *FCMClient) Send(ctx context.Context, deviceToken string, msg Message) error {
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        deviceToken,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	access, err := c.token(ctx)
+	if err != nil {
+		return err
+	}
+	endpoint := c.endpoint + "/v1/projects/" + url.PathEscape(c.projectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+access)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var fcmErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&fcmErr)
+	for _, d := range fcmErr.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return fmt.Errorf("%w: fcm UNREGISTERED", ErrInvalidToken)
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: fcm %s", ErrInvalidToken, fcmErr.Error.Status)
+	}
+	return fmt.Errorf("fcm: %s: %s", resp.Status, fcmErr.Error.Message)
+}
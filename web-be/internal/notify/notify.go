@@ -0,0 +1,36 @@
+// Package notify delivers user notifications over email, webhooks and push.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Channel names. ChannelInApp is the user's notification inbox, which is
+// stored rather than sent and has no Notifier.
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelPush    = "push"
+)
+
+// ErrNoRecipient is returned when a user cannot be reached on a channel,
+// e.g. no email address or no registered device. It is not a delivery failure.
+var ErrNoRecipient = errors.New("notify: no recipient on this channel")
+
+// Message is a notification for one user.
+type Message struct {
+	UserID string
+	Email  string // empty when the user has no address on file
+	Title  string
+	Body   string
+	// Data is passed through to webhook and push payloads, e.g. task_id.
+	Data map[string]string
+}
+
+// Notifier delivers messages over one channel.
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, msg Message) error
+}
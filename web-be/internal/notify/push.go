@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+)
+
+// Push platforms.
+const (
+	PlatformAPNs = "apns"
+	PlatformFCM  = "fcm"
+)
+
+// pushWorkers bounds concurrent requests to the push providers. APNs and FCM
+// have no multi-recipient send, so a batch is fanned out over this many
+// requests in flight (multiplexed on one HTTP/2 connection per provider).
+const pushWorkers = 16
+
+// Device is a push target of a user.
+type Device struct {
+	Platform string
+	Token    string
+}
+
+// DeviceSource lists the push devices registered by a user and forgets the
+// ones providers reject.
+type DeviceSource interface {
+	Devices(ctx context.Context, userID string) ([]Device, error)
+	RemoveTokens(ctx context.Context, tokens []string) error
+}
+
+// BatchNotifier is a Notifier that delivers many messages at once. The
+// returned slice holds one result per message, as Notify would have returned.
+type BatchNotifier interface {
+	Notifier
+	NotifyBatch(ctx context.Context, msgs []Message) []error
+}
+
+// PushNotifier delivers messages to every device of the user through APNs
+// or FCM. A nil client disables that platform.
+type PushNotifier struct {
+	devices DeviceSource
+	apns    *APNsClient
+	fcm     *FCMClient
+}
+
+// NewPushNotifier returns a PushNotifier.
+func NewPushNotifier(devices DeviceSource, apns *APNsClient, fcm *FCMClient) *PushNotifier {
+	return &PushNotifier{devices: devices, apns: apns, fcm: fcm}
+}
+
+func (n *PushNotifier) Channel() string { return ChannelPush }
+
+// Notify succeeds when at least one device received the message.
+func (n *PushNotifier) Notify(ctx context.Context, msg Message) error {
+	return n.NotifyBatch(ctx, []Message{msg})[0]
+}
+
+// NotifyBatch sends every message to all devices of its user concurrently.
+// A message succeeds when at least one of its devices received it, and fails
+// with ErrNoRecipient when its user has no valid device on an enabled
+// platform. Tokens the providers report as invalid are removed from the
+// DeviceSource.
+func (n *PushNotifier) NotifyBatch(ctx context.Context, msgs []Message) []error {
+	type job struct {
+		msg    int
+		device Device
+	}
+	results := make([]error, len(msgs))
+	devicesOf := make(map[string][]Device)
+	var jobs []job
+	for i, msg := range msgs {
+		devices, ok := devicesOf[msg.UserID]
+		if !ok {
+			var err error
+			if devices, err = n.devices.Devices(ctx, msg.UserID); err != nil {
+				results[i] = err
+				continue
+			}
+			devicesOf[msg.UserID] = devices
+		}
+		for _, d := range devices {
+			if (d.Platform == PlatformAPNs && n.apns != nil) || (d.Platform == PlatformFCM && n.fcm != nil) {
+				jobs = append(jobs, job{msg: i, device: d})
+			}
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		sent    = make([]bool, len(msgs))
+		errs    = make([][]error, len(msgs))
+		invalid []string
+		wg      sync.WaitGroup
+		queue   = make(chan job)
+	)
+	for w := 0; w < min(pushWorkers, len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				err := n.send(ctx, j.device, msgs[j.msg])
+				mu.Lock()
+				switch {
+				case err == nil:
+					sent[j.msg] = true
+				case errors.Is(err, ErrInvalidToken):
+					if !slices.Contains(invalid, j.device.Token) {
+						invalid = append(invalid, j.device.Token)
+					}
+				default:
+					errs[j.msg] = append(errs[j.msg], err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(invalid) > 0 {
+		if err := n.devices.RemoveTokens(ctx, invalid); err != nil {
+			log.Printf("push: prune %d invalid tokens: %v", len(invalid), err)
+		}
+	}
+	for i := range msgs {
+		switch {
+		case results[i] != nil || sent[i]:
+		case len(errs[i]) == 0:
+			results[i] = ErrNoRecipient
+		default:
+			results[i] = errors.Join(errs[i]...)
+		}
+	}
+	return results
+}
+
+func (n *PushNotifier) send(ctx context.Context, d Device, msg Message) error {
+	if d.Platform == PlatformAPNs {
+		return n.apns.Send(ctx, d.Token, msg)
+	}
+	return n.fcm.Send(ctx, d.Token, msg)
+}
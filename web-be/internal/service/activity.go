@@ -0,0 +1,223 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// unloggedFields are bookkeeping columns left out of activity diffs.
+var unloggedFields = map[string]bool{"created_at": true, "updated_at": true, "deleted_at": true, "sync_seq": true, "version": true}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// RecordCreate logs that actorID created entity, with all its fields as new values.
+func RecordCreate(tx *gorm.DB, actorID string, entity any) error {
+	return recordActivity(tx, actorID, entity, model.ActivityCreate, diffFields(nil, snapshot(entity)))
+}
+
+// RecordUpdate logs the fields that differ between before and after. Nothing
+// is logged when no field changed.
+func RecordUpdate(tx *gorm.DB, actorID string, before, after any) error {
+	changes := diffFields(snapshot(before), snapshot(after))
+	if len(changes) == 0 {
+		return nil
+	}
+	return recordActivity(tx, actorID, after, model.ActivityUpdate, changes)
+}
+
+// RecordDelete logs that actorID deleted entity, with all its fields as old values.
+func RecordDelete(tx *gorm.DB, actorID string, entity any) error {
+	return recordActivity(tx, actorID, entity, model.ActivityDelete, diffFields(snapshot(entity), nil))
+}
+
+// CreateLogged inserts entity and logs its creation in one transaction.
+func CreateLogged(db *gorm.DB, actorID string, entity any) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entity).Error; err != nil {
+			return err
+		}
+		return RecordCreate(tx, actorID, entity)
+	})
+}
+
+// SaveLogged saves after and logs its changes from before in one transaction.
+func SaveLogged(db *gorm.DB, actorID string, before, after any) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(after).Error; err != nil {
+			return err
+		}
+		return RecordUpdate(tx, actorID, before, after)
+	})
+}
+
+// DeleteLogged deletes entity and logs its deletion in one transaction.
+func DeleteLogged(db *gorm.DB, actorID string, entity any) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(entity).Error; err != nil {
+			return err
+		}
+		return RecordDelete(tx, actorID, entity)
+	})
+}
+
+func recordActivity(tx *gorm.DB, actorID string, entity any, action string, changes map[string]map[string]any) error {
+	entityType, entityID, taskID, projectID, err := activityTarget(tx, entity)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	a := &model.Activity{
+		ID:         uuid.New().String(),
+		ActorID:    actorID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		TaskID:     taskID,
+		ProjectID:  projectID,
+		Action:     action,
+		Changes:    string(raw),
+	}
+	if err := tx.Create(a).Error; err != nil {
+		return err
+	}
+	return enqueueWebhooks(tx, a)
+}
+
+// activityTarget identifies entity and the task and project whose feeds it
+// belongs in. A subtask is logged against its task, a dependency against
+// the blocked task and an assignment against the assigned task.
+func activityTarget(tx *gorm.DB, entity any) (entityType, entityID string, taskID, projectID *string, err error) {
+	switch e := entity.(type) {
+	case *model.Task:
+		return "task", e.ID, &e.ID, optional(e.ProjectID), nil
+	case *model.Project:
+		return "project", e.ID, nil, &e.ID, nil
+	case *model.Section:
+		return "section", e.ID, nil, &e.ProjectID, nil
+	case *model.ProjectStatus:
+		return "project_status", e.ID, nil, &e.ProjectID, nil
+	case *model.Label:
+		return "label", e.ID, nil, nil, nil
+	case *model.Comment:
+		if e.TaskID == nil {
+			return "comment", e.ID, nil, e.ProjectID, nil
+		}
+		projectID, err = taskProjectID(tx, *e.TaskID)
+		return "comment", e.ID, e.TaskID, projectID, err
+	case *model.Subtask:
+		projectID, err = taskProjectID(tx, e.TaskID)
+		return "subtask", e.ID, &e.TaskID, projectID, err
+	case *model.Attachment:
+		projectID, err = taskProjectID(tx, e.TaskID)
+		return "attachment", e.ID, &e.TaskID, projectID, err
+	case *model.TaskDependency:
+		projectID, err = taskProjectID(tx, e.TaskID)
+		return "task_dependency", e.TaskID, &e.TaskID, projectID, err
+	case *model.TaskAssignment:
+		projectID, err = taskProjectID(tx, e.TaskID)
+		return "task_assignment", e.TaskID, &e.TaskID, projectID, err
+	}
+	return "", "", nil, nil, fmt.Errorf("activity: unsupported entity %T", entity)
+}
+
+// taskProjectID returns the project of a task, including a soft-deleted one.
+func taskProjectID(tx *gorm.DB, taskID string) (*string, error) {
+	var task model.Task
+	if err := tx.Unscoped().Select("id", "project_id").Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return optional(task.ProjectID), nil
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// snapshot returns the column values of a model struct keyed by column name.
+// Relations and bookkeeping columns are skipped; times are normalised to UTC
+// so that values loaded from the database compare equal to request values.
+func snapshot(entity any) map[string]any {
+	v := reflect.Indirect(reflect.ValueOf(entity))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	naming := schema.NamingStrategy{}
+	out := make(map[string]any)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() || !isColumn(f.Type) {
+			continue
+		}
+		name := naming.ColumnName("", f.Name)
+		for _, opt := range strings.Split(f.Tag.Get("gorm"), ";") {
+			if col, ok := strings.CutPrefix(opt, "column:"); ok {
+				name = col
+			}
+		}
+		if unloggedFields[name] {
+			continue
+		}
+		out[name] = columnValue(v.Field(i))
+	}
+	return out
+}
+
+// isColumn reports whether a field of type t is a plain column rather than a relation.
+func isColumn(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return t == timeType
+	case reflect.Slice, reflect.Map:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+func columnValue(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return v.Interface()
+}
+
+// diffFields returns {"field": {"old": ..., "new": ...}} for fields that
+// differ. A nil before (create) or after (delete) yields only new or old values.
+func diffFields(before, after map[string]any) map[string]map[string]any {
+	changes := make(map[string]map[string]any)
+	for k, nv := range after {
+		if before == nil {
+			changes[k] = map[string]any{"new": nv}
+		} else if ov := before[k]; !reflect.DeepEqual(ov, nv) {
+			changes[k] = map[string]any{"old": ov, "new": nv}
+		}
+	}
+	if after == nil {
+		for k, ov := range before {
+			changes[k] = map[string]any{"old": ov}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrAppPasswordNotFound is returned when the caller has no app password with the given ID.
+	ErrAppPasswordNotFound = errors.New("app password not found")
+	// ErrAppPasswordLimit is returned when the caller already has maxAppPasswords.
+	ErrAppPasswordLimit = errors.New("too many app passwords")
+	// ErrInvalidCredentials is returned for a username and password that do
+	// not match an app password.
+	ErrInvalidCredentials = errors.New("invalid username or app password")
+)
+
+const (
+	// maxAppPasswords caps the app passwords of a user.
+	maxAppPasswords = 20
+	// appPasswordGroups and appPasswordGroupLen shape generated passwords,
+	// e.g. abcd-efgh-ijkl-mnop: 16 letters, about 75 bits.
+	appPasswordGroups   = 4
+	appPasswordGroupLen = 4
+	// appPasswordTouchEvery is how stale last_used_at may get before a sign-in
+	// updates it, so that clients polling every minute don't write each time.
+	appPasswordTouchEvery = 5 * time.Minute
+)
+
+// CreateAppPassword generates an app password named name for userID and
+// returns it; it cannot be retrieved again.
+func CreateAppPassword(db *gorm.DB, userID, name string) (string, *model.AppPassword, error) {
+	var count int64
+	if err := db.Model(&model.AppPassword{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return "", nil, err
+	}
+	if count >= maxAppPasswords {
+		return "", nil, fmt.Errorf("%w: at most %d", ErrAppPasswordLimit, maxAppPasswords)
+	}
+	password, err := newAppPassword()
+	if err != nil {
+		return "", nil, err
+	}
+	ap := &model.AppPassword{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		Name:         name,
+		PasswordHash: hashAppPassword(password),
+	}
+	if err := db.Create(ap).Error; err != nil {
+		return "", nil, err
+	}
+	return password, ap, nil
+}
+
+// RevokeAppPassword deletes an app password of userID; apps using it can no
+// longer sign in.
+func RevokeAppPassword(db *gorm.DB, userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrAppPasswordNotFound
+	}
+	res := db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.AppPassword{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAppPasswordNotFound
+	}
+	return nil
+}
+
+// AuthenticateAppPassword returns the user an app password belongs to.
+// username must be the user's email, or their ID for accounts without one
+// here (Supabase).
+func AuthenticateAppPassword(db *gorm.DB, username, password string) (string, error) {
+	var ap model.AppPassword
+	if err := db.Where("password_hash = ?", hashAppPassword(password)).First(&ap).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrInvalidCredentials
+		}
+		return "", err
+	}
+	if !strings.EqualFold(username, ap.UserID) {
+		var user model.User
+		if err := db.Select("email").Where("id = ?", ap.UserID).First(&user).Error; err != nil || !strings.EqualFold(username, user.Email) {
+			return "", ErrInvalidCredentials
+		}
+	}
+	now := time.Now()
+	if ap.LastUsedAt == nil || now.Sub(*ap.LastUsedAt) > appPasswordTouchEvery {
+		db.Model(&ap).UpdateColumn("last_used_at", now)
+	}
+	return ap.UserID, nil
+}
+
+// AppPasswordUsername returns what userID signs in with alongside an app
+// password: their email, or their ID when there is none.
+func AppPasswordUsername(db *gorm.DB, userID string) string {
+	var user model.User
+	if err := db.Select("email").Where("id = ?", userID).First(&user).Error; err == nil && user.Email != "" {
+		return user.Email
+	}
+	return userID
+}
+
+// newAppPassword returns random lowercase letters in dash-separated groups,
+// easy to type on a phone.
+func newAppPassword() (string, error) {
+	groups := make([]string, appPasswordGroups)
+	for i := range groups {
+		b := make([]byte, appPasswordGroupLen)
+		for j := range b {
+			n, err := rand.Int(rand.Reader, big.NewInt(26))
+			if err != nil {
+				return "", err
+			}
+			b[j] = byte('a' + n.Int64())
+		}
+		groups[i] = string(b)
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// hashAppPassword hashes a password ignoring case, dashes and spaces, so it
+// may be typed without them.
+func hashAppPassword(password string) string {
+	norm := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(password))
+	sum := sha256.Sum256([]byte(norm))
+	return hex.EncodeToString(sum[:])
+}
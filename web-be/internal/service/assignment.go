@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrInvalidAssignee is returned when assigning a task to someone who
+	// cannot see it: neither its owner nor a member of its project.
+	ErrInvalidAssignee = errors.New("assignee must be the task owner or a member of its project")
+	// ErrAssignmentNotFound is returned when the user is not assigned to the task.
+	ErrAssignmentNotFound = errors.New("assignment not found")
+)
+
+// TaskAssigneeIDs returns the users assigned to taskID, earliest first.
+func TaskAssigneeIDs(db *gorm.DB, taskID string) ([]string, error) {
+	var ids []string
+	err := db.Model(&model.TaskAssignment{}).Where("task_id = ?", taskID).
+		Order("created_at").Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AssignTask assigns task to userID on behalf of actorID and notifies the
+// assignee. Assigning someone already assigned is a no-op.
+func AssignTask(db *gorm.DB, actorID string, task *model.Task, userID string) (*model.TaskAssignment, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, ErrInvalidAssignee
+	}
+	if userID != task.UserID {
+		if task.ProjectID == "" {
+			return nil, ErrInvalidAssignee
+		}
+		members, err := ProjectMemberIDs(db, task.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(members, userID) {
+			return nil, ErrInvalidAssignee
+		}
+	}
+	a := &model.TaskAssignment{TaskID: task.ID, UserID: userID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(a)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := RecordCreate(tx, actorID, a); err != nil {
+			return err
+		}
+		return notifyUsers(tx, []string{userID}, model.Notification{
+			ActorID:   &actorID,
+			Type:      model.NotificationAssignment,
+			Title:     truncate(fmt.Sprintf("Assigned to you: %s", task.Title), 500),
+			Body:      truncate(task.Description, 1000),
+			TaskID:    &task.ID,
+			ProjectID: optional(task.ProjectID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("task_id = ? AND user_id = ?", task.ID, userID).First(a).Error; err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// UnassignTask removes userID from task on behalf of actorID.
+func UnassignTask(db *gorm.DB, actorID string, task *model.Task, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return ErrAssignmentNotFound
+	}
+	var a model.TaskAssignment
+	if err := db.Where("task_id = ? AND user_id = ?", task.ID, userID).First(&a).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAssignmentNotFound
+		}
+		return err
+	}
+	return DeleteLogged(db, actorID, &a)
+}
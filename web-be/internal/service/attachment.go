@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/storage"
+)
+
+var (
+	// ErrAttachmentNotFound is returned when an attachment does not exist on the task.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge is returned when a file exceeds the per-file size limit.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrAttachmentQuota is returned when an upload would exceed the uploader's storage quota.
+	ErrAttachmentQuota = errors.New("attachment storage quota exceeded")
+)
+
+// sniffLen is how much of a file http.DetectContentType looks at.
+const sniffLen = 512
+
+// AttachmentLimits bounds attachment uploads, in bytes.
+type AttachmentLimits struct {
+	MaxFileBytes      int64
+	FreeQuotaBytes    int64
+	PremiumQuotaBytes int64
+}
+
+// Attachments stores task attachments in a BlobStore and enforces limits.
+type Attachments struct {
+	blobs  storage.BlobStore
+	limits AttachmentLimits
+}
+
+// NewAttachments returns an Attachments service backed by blobs.
+func NewAttachments(blobs storage.BlobStore, limits AttachmentLimits) *Attachments {
+	return &Attachments{blobs: blobs, limits: limits}
+}
+
+// MaxFileBytes is the per-file size limit.
+func (a *Attachments) MaxFileBytes() int64 {
+	return a.limits.MaxFileBytes
+}
+
+// Quota returns the storage quota of user.
+func (a *Attachments) Quota(user model.User) int64 {
+	if user.HasPremium(time.Now()) {
+		return a.limits.PremiumQuotaBytes
+	}
+	return a.limits.FreeQuotaBytes
+}
+
+// Usage returns the bytes of attachments uploaded by userID.
+func Usage(db *gorm.DB, userID string) (int64, error) {
+	var used int64
+	err := db.Model(&model.Attachment{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error
+	return used, err
+}
+
+// checkQuota fails with ErrAttachmentQuota when adding size bytes would take
+// userID over quota.
+func (a *Attachments) checkQuota(db *gorm.DB, userID string, size int64) error {
+	var user model.User
+	if err := db.Select("id", "is_premium", "premium_expires_at").Where("id = ?", userID).
+		First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	used, err := Usage(db, userID)
+	if err != nil {
+		return err
+	}
+	if quota := a.Quota(user); used+size > quota {
+		return fmt.Errorf("%w: %d of %d bytes used", ErrAttachmentQuota, used, quota)
+	}
+	return nil
+}
+
+// Upload stores size bytes from r as an attachment on task by userID. The
+// content type is sniffed from the data; the client's claim is ignored.
+// commentID, when set, must be a comment on task.
+func (a *Attachments) Upload(ctx context.Context, db *gorm.DB, userID string, task *model.Task,
+	commentID *string, fileName string, r io.Reader, size int64) (*model.Attachment, error) {
+	if size > a.limits.MaxFileBytes {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrAttachmentTooLarge, size, a.limits.MaxFileBytes)
+	}
+	if commentID != nil {
+		if _, err := uuid.Parse(*commentID); err != nil {
+			return nil, ErrCommentNotFound
+		}
+		var n int64
+		if err := db.Model(&model.Comment{}).Where("id = ? AND task_id = ?", *commentID, task.ID).
+			Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, ErrCommentNotFound
+		}
+	}
+	// Fail fast before uploading; the check is repeated under a lock below.
+	if err := a.checkQuota(db, userID, size); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	att := &model.Attachment{
+		ID:          uuid.New().String(),
+		TaskID:      task.ID,
+		CommentID:   commentID,
+		UserID:      userID,
+		FileName:    cleanFileName(fileName),
+		ContentType: http.DetectContentType(head),
+		Size:        size,
+	}
+	att.StorageKey = "attachments/" + task.ID + "/" + att.ID
+	body := io.MultiReader(bytes.NewReader(head), r)
+	if err := a.blobs.Put(ctx, att.StorageKey, body, size, att.ContentType); err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Serialise a user's uploads so concurrent requests cannot each pass the quota check.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('attachments:' || ?))", userID).Error; err != nil {
+			return err
+		}
+		if err := a.checkQuota(tx, userID, size); err != nil {
+			return err
+		}
+		if err := tx.Create(att).Error; err != nil {
+			return err
+		}
+		return RecordCreate(tx, userID, att)
+	})
+	if err != nil {
+		a.deleteBlob(att.StorageKey)
+		return nil, err
+	}
+	return att, nil
+}
+
+// Open returns the content of att.
+func (a *Attachments) Open(ctx context.Context, att *model.Attachment) (io.ReadCloser, error) {
+	rc, err := a.blobs.Get(ctx, att.StorageKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return rc, err
+}
+
+// Delete removes att and its content on behalf of actorID.
+func (a *Attachments) Delete(db *gorm.DB, actorID string, att *model.Attachment) error {
+	if err := DeleteLogged(db, actorID, att); err != nil {
+		return err
+	}
+	a.deleteBlob(att.StorageKey)
+	return nil
+}
+
+// deleteBlob removes a blob whose row is gone. Failures only leak storage,
+// so they are logged rather than returned.
+func (a *Attachments) deleteBlob(key string) {
+	if err := a.blobs.Delete(context.Background(), key); err != nil {
+		log.Printf("attachments: delete blob %s: %v", key, err)
+	}
+}
+
+// FindAttachment loads an attachment of taskID.
+func FindAttachment(db *gorm.DB, taskID, id string) (*model.Attachment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrAttachmentNotFound
+	}
+	var att model.Attachment
+	if err := db.Where("id = ? AND task_id = ?", id, taskID).First(&att).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &att, nil
+}
+
+// cleanFileName strips any client path and keeps the name within 255 bytes.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		name = "file"
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
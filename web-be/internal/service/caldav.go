@@ -0,0 +1,562 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/ical"
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrCalendarObjectNotFound is returned for a CalDAV resource name that is
+	// not a task or subtask of the collection.
+	ErrCalendarObjectNotFound = errors.New("calendar object not found")
+	// ErrInvalidCalendarObject is returned for a CalDAV resource body that is
+	// not a VCALENDAR with one VTODO.
+	ErrInvalidCalendarObject = errors.New("invalid calendar object")
+	// ErrUnsupportedComponent is returned for a CalDAV resource with events
+	// or journal entries rather than a to-do.
+	ErrUnsupportedComponent = errors.New("only VTODO calendar objects are supported")
+)
+
+// calDAVObjectSuffix ends the name of every calendar object resource.
+const calDAVObjectSuffix = ".ics"
+
+// CalDAVObject is a task, or a subtask of Task, as a CalDAV calendar object
+// resource holding one VTODO.
+type CalDAVObject struct {
+	Task    *model.Task
+	Subtask *model.Subtask
+	Data    []byte
+	ETag    string // quoted, changes with Data
+}
+
+// UID returns the iCalendar UID of the object.
+func (o *CalDAVObject) UID() string {
+	if o.Subtask != nil {
+		return SubtaskUID(o.Subtask)
+	}
+	return TaskUID(o.Task)
+}
+
+// Name returns the resource name of the object within its collection.
+func (o *CalDAVObject) Name() string {
+	return o.UID() + calDAVObjectSuffix
+}
+
+// UpdatedAt returns when the task or subtask was last changed.
+func (o *CalDAVObject) UpdatedAt() time.Time {
+	if o.Subtask != nil {
+		return o.Subtask.UpdatedAt
+	}
+	return o.Task.UpdatedAt
+}
+
+// TaskUID returns the iCalendar UID of t: the UID it was created with over
+// CalDAV, else its ID.
+func TaskUID(t *model.Task) string {
+	if t.ICalUID != "" {
+		return t.ICalUID
+	}
+	return t.ID
+}
+
+// SubtaskUID returns the iCalendar UID of s, like TaskUID.
+func SubtaskUID(s *model.Subtask) string {
+	if s.ICalUID != "" {
+		return s.ICalUID
+	}
+	return s.ID
+}
+
+// CalDAVCollections returns the projects userID can see, each a calendar
+// collection of their tasks in it: the Inbox first, then by creation.
+func CalDAVCollections(db *gorm.DB, userID string) ([]model.Project, error) {
+	var projects []model.Project
+	err := db.Where("user_id = ? OR id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID, userID).
+		Order("is_inbox DESC, created_at, id").Find(&projects).Error
+	return projects, err
+}
+
+// CalDAVCollection returns a project userID can see.
+func CalDAVCollection(db *gorm.DB, userID, projectID string) (*model.Project, error) {
+	if err := CheckProjectAccess(db, userID, projectID); err != nil {
+		if errors.Is(err, ErrProjectForbidden) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	var proj model.Project
+	if err := db.Where("id = ?", projectID).First(&proj).Error; err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// CalDAVChanges is what changed in a calendar collection since a sync token.
+type CalDAVChanges struct {
+	Objects []CalDAVObject
+	// Removed are the names of objects deleted or moved out of the
+	// collection; possibly ones the client never had.
+	Removed []string
+	// Token is the position to sync from next time.
+	Token int64
+}
+
+// CalDAVChangesSince returns the tasks of userID in projectID, and their
+// subtasks, written after the sync position since; 0 returns all of them,
+// without removals. Tasks moved to another project since are reported
+// removed, as are their subtasks.
+func CalDAVChangesSince(db *gorm.DB, userID, projectID string, since int64) (*CalDAVChanges, error) {
+	// Read before the rows, so every row up to it is visible to them.
+	settled, _, err := settledSeqs(db)
+	if err != nil {
+		return nil, err
+	}
+	q := db.Unscoped().Preload("Labels", "deleted_at IS NULL").
+		Where("user_id = ? AND sync_seq > ?", userID, since)
+	if since == 0 {
+		q = q.Where("project_id = ?", projectID)
+	} else {
+		// Moves out are looked up per task written since, through the
+		// activities entity index, rather than across all activities.
+		q = q.Where("project_id = ? OR EXISTS (SELECT 1 FROM activities WHERE activities.entity_type = 'task' "+
+			"AND activities.entity_id = tasks.id AND activities.action = ? "+
+			"AND activities.changes->'project_id'->>'old' = ?)", projectID, model.ActivityUpdate, projectID)
+	}
+	var tasks []model.Task
+	if err := q.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[string]*model.Task, len(tasks))
+	ids := make([]string, len(tasks))
+	for i := range tasks {
+		parents[tasks[i].ID] = &tasks[i]
+		ids[i] = tasks[i].ID
+	}
+	// Subtasks of changed tasks follow them out of or into the collection
+	// without being written themselves.
+	var subtasks []model.Subtask
+	if err := db.Unscoped().
+		Where("task_id IN ? OR (sync_seq > ? AND task_id IN (SELECT id FROM tasks WHERE user_id = ? AND project_id = ?))",
+			ids, since, userID, projectID).
+		Find(&subtasks).Error; err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, s := range subtasks {
+		if parents[s.TaskID] == nil && !slices.Contains(missing, s.TaskID) {
+			missing = append(missing, s.TaskID)
+		}
+	}
+	if len(missing) > 0 {
+		var more []model.Task
+		if err := db.Unscoped().Where("id IN ?", missing).Find(&more).Error; err != nil {
+			return nil, err
+		}
+		for i := range more {
+			parents[more[i].ID] = &more[i]
+		}
+	}
+	categories, err := StatusCategories(db, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	live := func(t *model.Task) bool {
+		return t != nil && !t.DeletedAt.Valid && t.ProjectID == projectID
+	}
+	out := &CalDAVChanges{Token: since}
+	var seqs []int64
+	for i := range tasks {
+		t := &tasks[i]
+		if live(t) {
+			out.Objects = append(out.Objects, taskObject(t, categories[t.ID]))
+		} else if since > 0 {
+			out.Removed = append(out.Removed, TaskUID(t)+calDAVObjectSuffix)
+		}
+		seqs = append(seqs, t.SyncSeq)
+	}
+	for i := range subtasks {
+		s := &subtasks[i]
+		parent := parents[s.TaskID]
+		if !s.DeletedAt.Valid && live(parent) {
+			out.Objects = append(out.Objects, subtaskObject(s, parent))
+		} else if since > 0 {
+			out.Removed = append(out.Removed, SubtaskUID(s)+calDAVObjectSuffix)
+		}
+		if s.SyncSeq > since {
+			seqs = append(seqs, s.SyncSeq)
+		}
+	}
+	slices.Sort(seqs)
+	for _, seq := range seqs {
+		if seq > settled {
+			// Not settled yet: sent now, and again next time.
+			break
+		}
+		out.Token = seq
+	}
+	return out, nil
+}
+
+// FindCalDAVObject returns the task or subtask of userID named name in
+// projectID.
+func FindCalDAVObject(db *gorm.DB, userID, projectID, name string) (*CalDAVObject, error) {
+	uid, ok := strings.CutSuffix(name, calDAVObjectSuffix)
+	if !ok || uid == "" {
+		return nil, ErrCalendarObjectNotFound
+	}
+	// Objects without a UID of their own are named after their ID.
+	byUID, args := "ical_uid = ?", []any{uid}
+	if id, err := uuid.Parse(uid); err == nil && id.String() == uid {
+		byUID, args = "(ical_uid = ? OR (ical_uid = '' AND id = ?))", []any{uid, uid}
+	}
+
+	var task model.Task
+	err := db.Preload("Labels").Where("user_id = ? AND project_id = ?", userID, projectID).
+		Where(byUID, args...).First(&task).Error
+	if err == nil {
+		categories, err := StatusCategories(db, []model.Task{task})
+		if err != nil {
+			return nil, err
+		}
+		obj := taskObject(&task, categories[task.ID])
+		return &obj, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var sub model.Subtask
+	if err := db.Where("task_id IN (SELECT id FROM tasks WHERE user_id = ? AND project_id = ? AND deleted_at IS NULL)", userID, projectID).
+		Where(byUID, args...).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarObjectNotFound
+		}
+		return nil, err
+	}
+	if err := db.Where("id = ?", sub.TaskID).First(&task).Error; err != nil {
+		return nil, err
+	}
+	obj := subtaskObject(&sub, &task)
+	return &obj, nil
+}
+
+// FindCalDAVParent returns the task of userID in projectID with the given
+// UID, for a subtask to be added to.
+func FindCalDAVParent(db *gorm.DB, userID, projectID, uid string) (*model.Task, error) {
+	obj, err := FindCalDAVObject(db, userID, projectID, uid+calDAVObjectSuffix)
+	if err != nil {
+		return nil, err
+	}
+	if obj.Subtask != nil {
+		return nil, fmt.Errorf("%w: RELATED-TO %s is a subtask", ErrInvalidCalendarObject, uid)
+	}
+	return obj.Task, nil
+}
+
+func taskObject(t *model.Task, category string) CalDAVObject {
+	var buf bytes.Buffer
+	e := ical.NewEncoder(&buf)
+	beginCalendar(e)
+	// Labels come in no particular order; sorted, CATEGORIES and the ETag
+	// only change with them.
+	slices.SortFunc(t.Labels, func(a, b model.Label) int { return cmp.Compare(a.Name, b.Name) })
+	if t.DueDate != nil {
+		// Time zones cover ten years from the due date rather than from now,
+		// so that the data, and its ETag, only change with the task.
+		writeTimezones(e, []model.Task{*t}, *t.DueDate)
+	}
+	writeTaskComponent(e, t, CalendarTodos, category)
+	e.End("VCALENDAR")
+	_ = e.Err()
+	return newCalDAVObject(t, nil, buf.Bytes())
+}
+
+func subtaskObject(s *model.Subtask, parent *model.Task) CalDAVObject {
+	var buf bytes.Buffer
+	e := ical.NewEncoder(&buf)
+	beginCalendar(e)
+	e.Begin(CalendarTodos)
+	e.Prop("UID", SubtaskUID(s))
+	e.Prop("DTSTAMP", ical.UTC(s.UpdatedAt))
+	e.Prop("CREATED", ical.UTC(s.CreatedAt))
+	e.Prop("LAST-MODIFIED", ical.UTC(s.UpdatedAt))
+	e.Prop("SUMMARY", ical.Text(s.Title))
+	if s.Completed {
+		e.Prop("STATUS", "COMPLETED")
+		e.Prop("COMPLETED", ical.UTC(s.UpdatedAt))
+	} else {
+		e.Prop("STATUS", "NEEDS-ACTION")
+	}
+	e.Prop("RELATED-TO", TaskUID(parent), ical.Param{Name: "RELTYPE", Value: "PARENT"})
+	e.End(CalendarTodos)
+	e.End("VCALENDAR")
+	_ = e.Err()
+	return newCalDAVObject(parent, s, buf.Bytes())
+}
+
+func newCalDAVObject(t *model.Task, s *model.Subtask, data []byte) CalDAVObject {
+	sum := sha256.Sum256(data)
+	return CalDAVObject{Task: t, Subtask: s, Data: data, ETag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+}
+
+// VTodo is what a CalDAV client stored: a VTODO read into task fields. Fields
+// the VTODO leaves out are zero, as they are to be cleared.
+type VTodo struct {
+	UID         string
+	Summary     string
+	Description string
+	Status      string // a built-in status
+	Priority    int    // 4 = p1 ... 1 = p4, 0 = none
+	Progress    int
+	DueDate     *time.Time
+	ReminderAt  *time.Time
+	Recurrence  string
+	Categories  []string
+	// ParentUID is the UID of the task a subtask belongs to, from RELATED-TO.
+	ParentUID string
+}
+
+// ParseVTodo reads the VTODO of a calendar object resource. A DUE date
+// without a time is due at the end of that day, in UTC; a reminder is the
+// first VALARM.
+func ParseVTodo(data []byte) (*VTodo, error) {
+	cal, err := ical.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendarObject, err)
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: not a VCALENDAR", ErrInvalidCalendarObject)
+	}
+	if len(cal.Children("VEVENT")) > 0 || len(cal.Children("VJOURNAL")) > 0 {
+		return nil, ErrUnsupportedComponent
+	}
+	// Overrides of single occurrences (RECURRENCE-ID) are not kept.
+	var todo *ical.Component
+	for _, c := range cal.Children(CalendarTodos) {
+		if c.Prop("RECURRENCE-ID") != nil {
+			continue
+		}
+		if todo != nil {
+			return nil, fmt.Errorf("%w: more than one VTODO", ErrInvalidCalendarObject)
+		}
+		todo = c
+	}
+	if todo == nil {
+		return nil, ErrUnsupportedComponent
+	}
+	uid := todo.Prop("UID")
+	if uid == nil || uid.Value == "" {
+		return nil, fmt.Errorf("%w: UID is required", ErrInvalidCalendarObject)
+	}
+
+	v := &VTodo{UID: uid.Text(), Status: model.TaskStatusPending}
+	if p := todo.Prop("SUMMARY"); p != nil {
+		v.Summary = p.Text()
+	}
+	if p := todo.Prop("DESCRIPTION"); p != nil {
+		v.Description = p.Text()
+	}
+	var start *time.Time
+	var loc *time.Location
+	if p := todo.Prop("DTSTART"); p != nil {
+		t, err := p.Time(time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("%w: DTSTART %q", ErrInvalidCalendarObject, p.Value)
+		}
+		start, loc = &t, p.Location()
+	}
+	if p := todo.Prop("DUE"); p != nil {
+		t, err := p.Time(time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("%w: DUE %q", ErrInvalidCalendarObject, p.Value)
+		}
+		if p.IsDate() {
+			t = t.Add(24*time.Hour - time.Minute)
+		}
+		v.DueDate, loc = &t, p.Location()
+	}
+
+	switch p := todo.Prop("STATUS"); {
+	case p != nil && strings.EqualFold(p.Value, "IN-PROCESS"):
+		v.Status = model.TaskStatusInProgress
+	case p != nil && strings.EqualFold(p.Value, "COMPLETED"), p == nil && todo.Prop("COMPLETED") != nil:
+		v.Status = model.TaskStatusCompleted
+	case p != nil && strings.EqualFold(p.Value, "CANCELLED"):
+		v.Status = model.TaskStatusCancelled
+	}
+	if p := todo.Prop("PRIORITY"); p != nil {
+		n, _ := strconv.Atoi(p.Value)
+		v.Priority = taskPriority(n)
+	}
+	if p := todo.Prop("PERCENT-COMPLETE"); p != nil {
+		n, _ := strconv.Atoi(p.Value)
+		v.Progress = min(max(n, 0), 100)
+	}
+	for _, p := range todo.All("CATEGORIES") {
+		for _, name := range p.List() {
+			name = strings.TrimSpace(name)
+			if name != "" && !slices.ContainsFunc(v.Categories, func(c string) bool { return strings.EqualFold(c, name) }) {
+				v.Categories = append(v.Categories, name)
+			}
+		}
+	}
+	if p := todo.Prop("RRULE"); p != nil {
+		if v.DueDate == nil {
+			v.DueDate = start
+		}
+		if v.DueDate == nil {
+			return nil, fmt.Errorf("%w: RRULE needs DUE or DTSTART", ErrInvalidCalendarObject)
+		}
+		if v.Recurrence, err = recurrenceFromRRule(p.Value, loc); err != nil {
+			return nil, err
+		}
+	}
+	for _, alarm := range todo.Children("VALARM") {
+		if v.ReminderAt, err = alarmTime(alarm, start, v.DueDate); err != nil {
+			return nil, err
+		}
+		if v.ReminderAt != nil {
+			break
+		}
+	}
+	for _, p := range todo.All("RELATED-TO") {
+		if rel := p.Params["RELTYPE"]; rel == "" || strings.EqualFold(rel, "PARENT") {
+			v.ParentUID = p.Text()
+		}
+	}
+	return v, nil
+}
+
+// alarmTime returns when a VALARM goes off, or nil when its trigger is
+// relative to a time the VTODO does not have.
+func alarmTime(alarm *ical.Component, start, due *time.Time) (*time.Time, error) {
+	trigger := alarm.Prop("TRIGGER")
+	if trigger == nil {
+		return nil, nil
+	}
+	if strings.EqualFold(trigger.Params["VALUE"], "DATE-TIME") {
+		t, err := trigger.Time(time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("%w: TRIGGER %q", ErrInvalidCalendarObject, trigger.Value)
+		}
+		return &t, nil
+	}
+	d, err := ical.ParseDuration(trigger.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: TRIGGER %q", ErrInvalidCalendarObject, trigger.Value)
+	}
+	ref := start
+	if strings.EqualFold(trigger.Params["RELATED"], "END") || ref == nil {
+		ref = due
+	}
+	if ref == nil {
+		return nil, nil
+	}
+	t := ref.Add(d)
+	return &t, nil
+}
+
+// recurrenceFromRRule turns an RRULE into a recurrence rule, in loc when
+// set. It undoes rrule, and rejects what a task cannot repeat like, such as
+// an end (COUNT, UNTIL) or the nth weekday of a month.
+func recurrenceFromRRule(value string, loc *time.Location) (string, error) {
+	var parts []string
+	var monthDays []int
+	lastOfSet := false
+	for _, part := range strings.Split(value, ";") {
+		name, v, _ := strings.Cut(part, "=")
+		switch name = strings.ToUpper(name); name {
+		case "FREQ", "INTERVAL", "BYDAY":
+			parts = append(parts, name+"="+v)
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(v, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil {
+					return "", fmt.Errorf("%w: BYMONTHDAY %q", ErrInvalidRecurrence, v)
+				}
+				monthDays = append(monthDays, n)
+			}
+		case "BYSETPOS":
+			if v != "-1" {
+				return "", fmt.Errorf("%w: BYSETPOS %q", ErrInvalidRecurrence, v)
+			}
+			lastOfSet = true
+		case "WKST", "BYMONTH":
+			// Weeks start on Monday; a yearly rule repeats in the due date's month.
+		default:
+			return "", fmt.Errorf("%w: unsupported RRULE part %q", ErrInvalidRecurrence, name)
+		}
+	}
+	switch {
+	case len(monthDays) == 1 && !lastOfSet:
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(monthDays[0]))
+	case len(monthDays) > 1 && lastOfSet:
+		// The last of consecutive days from at most 28, as rrule writes a
+		// day clamped to short months.
+		if !slices.IsSorted(monthDays) || monthDays[0] > 28 || monthDays[len(monthDays)-1]-monthDays[0] != len(monthDays)-1 {
+			return "", fmt.Errorf("%w: BYMONTHDAY with BYSETPOS", ErrInvalidRecurrence)
+		}
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(monthDays[len(monthDays)-1]))
+	case len(monthDays) > 0 || lastOfSet:
+		return "", fmt.Errorf("%w: BYMONTHDAY with BYSETPOS", ErrInvalidRecurrence)
+	}
+	if loc != nil && loc != time.UTC {
+		parts = append(parts, "TZID="+loc.String())
+	}
+	return NormalizeRecurrence(strings.Join(parts, ";"))
+}
+
+// taskPriority maps an iCalendar PRIORITY to a task priority, undoing
+// icalPriority: 1-4 is p1, 5 p2, 6-8 p3 and 9 p4.
+func taskPriority(p int) int {
+	switch {
+	case p >= 1 && p <= 4:
+		return 4
+	case p == 5:
+		return 3
+	case p >= 6 && p <= 8:
+		return 2
+	case p == 9:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// EnsureLabels returns the IDs of userID's labels with the given names,
+// matched ignoring case, creating those missing.
+func EnsureLabels(tx *gorm.DB, userID string, names []string) ([]string, error) {
+	var labels []model.Label
+	if err := tx.Where("user_id = ?", userID).Order("created_at").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(labels, func(l model.Label) bool { return strings.EqualFold(l.Name, name) })
+		if i < 0 {
+			labels = append(labels, model.Label{ID: uuid.New().String(), Name: name, UserID: userID})
+			i = len(labels) - 1
+			if err := CreateLogged(tx, userID, &labels[i]); err != nil {
+				return nil, err
+			}
+		}
+		if !slices.Contains(ids, labels[i].ID) {
+			ids = append(ids, labels[i].ID)
+		}
+	}
+	return ids, nil
+}
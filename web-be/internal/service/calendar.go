@@ -0,0 +1,389 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/ical"
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrCalendarFeedNotFound is returned for an unknown or revoked feed token,
+	// and when the caller has no feed.
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	// ErrInvalidCalendar is returned for malformed feed filters.
+	ErrInvalidCalendar = errors.New("invalid calendar query")
+)
+
+// Calendar components tasks can be written as.
+const (
+	CalendarEvents = "VEVENT"
+	CalendarTodos  = "VTODO"
+)
+
+const (
+	// maxCalendarTasks caps the tasks in a feed, latest due first.
+	maxCalendarTasks = 2000
+	// calendarTimezoneYears is how many years of offset changes time zone
+	// definitions cover.
+	calendarTimezoneYears = 10
+	// calendarTimezoneHistory is how many years before now they reach back
+	// at most; older dates use the earliest offset given.
+	calendarTimezoneHistory = 2
+	// calendarProdID identifies the generator of the calendars.
+	calendarProdID = "-//todo-tracking-app//Tasks//EN"
+)
+
+// RotateCalendarFeed gives userID a new feed token, replacing the old one so
+// that its URL stops working, and returns it. Only its hash is stored.
+func RotateCalendarFeed(db *gorm.DB, userID string) (string, *model.CalendarFeed, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	token := "cal_" + hex.EncodeToString(b)
+	feed := &model.CalendarFeed{UserID: userID, TokenHash: hashCalendarToken(token), CreatedAt: time.Now()}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+	}).Create(feed).Error; err != nil {
+		return "", nil, err
+	}
+	return token, feed, nil
+}
+
+// GetCalendarFeed returns userID's feed.
+func GetCalendarFeed(db *gorm.DB, userID string) (*model.CalendarFeed, error) {
+	var feed model.CalendarFeed
+	if err := db.Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// RevokeCalendarFeed removes userID's feed token.
+func RevokeCalendarFeed(db *gorm.DB, userID string) error {
+	res := db.Where("user_id = ?", userID).Delete(&model.CalendarFeed{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// CalendarFeedUser returns the user whose feed token is token.
+func CalendarFeedUser(db *gorm.DB, token string) (string, error) {
+	var feed model.CalendarFeed
+	if err := db.Where("token_hash = ?", hashCalendarToken(token)).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrCalendarFeedNotFound
+		}
+		return "", err
+	}
+	return feed.UserID, nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CalendarQuery selects the tasks of a feed: those with a due date, in
+// ProjectID and with LabelID when set. Component is CalendarEvents (the
+// default, which calendar apps show) or CalendarTodos.
+type CalendarQuery struct {
+	ProjectID string
+	LabelID   string
+	Component string
+}
+
+// WriteCalendarFeed writes userID's tasks matching q to w as a VCALENDAR.
+func WriteCalendarFeed(db *gorm.DB, w io.Writer, userID string, q CalendarQuery, now time.Time) error {
+	switch q.Component = strings.ToUpper(q.Component); q.Component {
+	case "":
+		q.Component = CalendarEvents
+	case CalendarEvents, CalendarTodos:
+	default:
+		return fmt.Errorf("%w: component must be VEVENT or VTODO", ErrInvalidCalendar)
+	}
+	for name, id := range map[string]string{"project_id": q.ProjectID, "label_id": q.LabelID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return fmt.Errorf("%w: %s must be a UUID", ErrInvalidCalendar, name)
+		}
+	}
+	name := "Tasks"
+	tx := db.Preload("Labels").Where("user_id = ? AND due_date IS NOT NULL", userID)
+	if q.ProjectID != "" {
+		var proj model.Project
+		if err := db.Select("name").Where("id = ? AND user_id = ?", q.ProjectID, userID).First(&proj).Error; err == nil {
+			name = proj.Name
+		}
+		tx = tx.Where("project_id = ?", q.ProjectID)
+	}
+	if q.LabelID != "" {
+		tx = tx.Where("id IN (SELECT task_id FROM task_labels WHERE label_id = ?)", q.LabelID)
+	}
+	var tasks []model.Task
+	if err := tx.Order("due_date DESC").Limit(maxCalendarTasks).Find(&tasks).Error; err != nil {
+		return err
+	}
+	categories, err := StatusCategories(db, tasks)
+	if err != nil {
+		return err
+	}
+
+	e := ical.NewEncoder(w)
+	beginCalendar(e)
+	e.Prop("METHOD", "PUBLISH")
+	e.Prop("X-WR-CALNAME", ical.Text(name))
+	e.Prop("REFRESH-INTERVAL", "PT1H", ical.Param{Name: "VALUE", Value: "DURATION"})
+	e.Prop("X-PUBLISHED-TTL", "PT1H")
+	writeTimezones(e, tasks, now)
+	for i := range tasks {
+		writeTaskComponent(e, &tasks[i], q.Component, categories[tasks[i].ID])
+	}
+	e.End("VCALENDAR")
+	return e.Err()
+}
+
+// beginCalendar starts a VCALENDAR and writes its required properties.
+func beginCalendar(e *ical.Encoder) {
+	e.Begin("VCALENDAR")
+	e.Prop("VERSION", "2.0")
+	e.Prop("PRODID", calendarProdID)
+	e.Prop("CALSCALE", "GREGORIAN")
+}
+
+// StatusCategories returns the built-in category of each task's status,
+// by task ID.
+func StatusCategories(db *gorm.DB, tasks []model.Task) (map[string]string, error) {
+	sets := map[string]StatusSet{}
+	categories := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		set, ok := sets[t.ProjectID]
+		if !ok {
+			var err error
+			if set, err = LoadStatusSet(db, t.ProjectID); err != nil {
+				return nil, err
+			}
+			sets[t.ProjectID] = set
+		}
+		categories[t.ID] = set[t.Status]
+	}
+	return categories, nil
+}
+
+// taskRecurrence returns the parsed recurrence of t, or nil when it has
+// none or it does not parse.
+func taskRecurrence(t *model.Task) *Recurrence {
+	if t.Recurrence == "" || t.DueDate == nil {
+		return nil
+	}
+	r, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// writeTimezones writes a VTIMEZONE for each time zone recurring tasks keep
+// their time of day in, from the earliest of their due dates on, but no more
+// than calendarTimezoneHistory years before now.
+func writeTimezones(e *ical.Encoder, tasks []model.Task, now time.Time) {
+	from := map[string]time.Time{}
+	locs := map[string]*time.Location{}
+	var names []string
+	for i := range tasks {
+		r := taskRecurrence(&tasks[i])
+		if r == nil || r.Location == time.UTC {
+			continue
+		}
+		name := r.Location.String()
+		if _, ok := locs[name]; !ok {
+			locs[name] = r.Location
+			names = append(names, name)
+		}
+		if f, ok := from[name]; !ok || tasks[i].DueDate.Before(f) {
+			from[name] = *tasks[i].DueDate
+		}
+	}
+	slices.Sort(names)
+	earliest := now.AddDate(-calendarTimezoneHistory, 0, 0)
+	for _, name := range names {
+		f := from[name]
+		if f.Before(earliest) {
+			f = earliest
+		}
+		e.Timezone(locs[name], f.In(locs[name]), now.AddDate(calendarTimezoneYears, 0, 0))
+	}
+}
+
+// writeTaskComponent writes t as a VEVENT at its due date or a VTODO due
+// then. category is the built-in category of its status.
+func writeTaskComponent(e *ical.Encoder, t *model.Task, component, category string) {
+	r := taskRecurrence(t)
+	at := func(name string, tm time.Time) {
+		if r != nil && r.Location != time.UTC {
+			e.Prop(name, ical.Local(tm.In(r.Location)), ical.Param{Name: "TZID", Value: r.Location.String()})
+		} else {
+			e.Prop(name, ical.UTC(tm))
+		}
+	}
+
+	e.Begin(component)
+	e.Prop("UID", TaskUID(t))
+	e.Prop("DTSTAMP", ical.UTC(t.UpdatedAt))
+	e.Prop("CREATED", ical.UTC(t.CreatedAt))
+	e.Prop("LAST-MODIFIED", ical.UTC(t.UpdatedAt))
+	e.Prop("SUMMARY", ical.Text(t.Title))
+	if t.Description != "" {
+		e.Prop("DESCRIPTION", ical.Text(t.Description))
+	}
+	if component == CalendarTodos {
+		if t.DueDate != nil {
+			// A recurring VTODO needs DTSTART to recur from.
+			if r != nil {
+				at("DTSTART", *t.DueDate)
+			}
+			at("DUE", *t.DueDate)
+		}
+		e.Prop("STATUS", todoStatus(category))
+		if category == model.TaskStatusCompleted && t.CompletedAt != nil {
+			e.Prop("COMPLETED", ical.UTC(*t.CompletedAt))
+		}
+		if t.Progress > 0 {
+			e.Prop("PERCENT-COMPLETE", strconv.Itoa(t.Progress))
+		}
+	} else {
+		at("DTSTART", *t.DueDate)
+		status := "CONFIRMED"
+		if category == model.TaskStatusCancelled {
+			status = "CANCELLED"
+		}
+		e.Prop("STATUS", status)
+		// A due date is not time the user is busy.
+		e.Prop("TRANSP", "TRANSPARENT")
+	}
+	if p := icalPriority(t.Priority); p > 0 {
+		e.Prop("PRIORITY", strconv.Itoa(p))
+	}
+	if len(t.Labels) > 0 {
+		names := make([]string, len(t.Labels))
+		for i, l := range t.Labels {
+			names[i] = ical.Text(l.Name)
+		}
+		e.Prop("CATEGORIES", strings.Join(names, ","))
+	}
+	if r != nil {
+		e.Prop("RRULE", rrule(r, *t.DueDate))
+	}
+	if t.ReminderAt != nil {
+		e.Begin("VALARM")
+		e.Prop("ACTION", "DISPLAY")
+		e.Prop("DESCRIPTION", ical.Text(t.Title))
+		switch {
+		case t.DueDate == nil:
+			e.Prop("TRIGGER", ical.UTC(*t.ReminderAt), ical.Param{Name: "VALUE", Value: "DATE-TIME"})
+		case component == CalendarTodos:
+			// Relative to DUE, so that it follows each occurrence.
+			e.Prop("TRIGGER", ical.Duration(t.ReminderAt.Sub(*t.DueDate)), ical.Param{Name: "RELATED", Value: "END"})
+		default:
+			e.Prop("TRIGGER", ical.Duration(t.ReminderAt.Sub(*t.DueDate)))
+		}
+		e.End("VALARM")
+	}
+	e.End(component)
+}
+
+// todoStatus maps a status category to a VTODO STATUS.
+func todoStatus(category string) string {
+	switch category {
+	case model.TaskStatusInProgress:
+		return "IN-PROCESS"
+	case model.TaskStatusCompleted:
+		return "COMPLETED"
+	case model.TaskStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// icalPriority maps a task priority (4 = p1 ... 1 = p4, 0 = none) to an
+// iCalendar PRIORITY (1 highest ... 9 lowest, 0 undefined), p2 being the
+// medium 5 of apps with three levels.
+func icalPriority(p int) int {
+	switch p {
+	case 4:
+		return 1
+	case 3:
+		return 5
+	case 2:
+		return 7
+	case 1:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// rrule returns r as an RRULE value for a series starting at first. Where r
+// clamps a day of the month to shorter months, which RRULE would skip, it
+// picks the last of the days up to it instead; a yearly rule names its month.
+func rrule(r *Recurrence, first time.Time) string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = rruleDays[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	day := r.ByMonthDay
+	if day == 0 && (r.Freq == FreqMonthly || r.Freq == FreqYearly) {
+		day = first.In(r.Location).Day()
+	}
+	// The shortest the months repeated in can be: any month, or for a yearly
+	// rule the due date's month in a common year.
+	short := 28
+	if r.Freq == FreqYearly {
+		month := first.In(r.Location).Month()
+		short = clampDay(2001, month, 31)
+		if day > short || r.ByMonthDay > 0 {
+			// A yearly BYMONTHDAY alone would repeat in every month.
+			parts = append(parts, "BYMONTH="+strconv.Itoa(int(month)))
+		}
+	}
+	switch {
+	case day > short:
+		days := []string{}
+		for d := short; d <= day; d++ {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","), "BYSETPOS=-1")
+	case r.ByMonthDay > 0:
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrCommentNotFound is returned when a comment does not exist or the caller cannot see it.
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrNotCommentAuthor is returned when someone other than the author edits or deletes a comment.
+	ErrNotCommentAuthor = errors.New("only the author can change a comment")
+)
+
+// mentionPattern matches @handle or @email, not preceded by a word character
+// (so "a@b.com" in plain text is not a mention).
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9._%+-]+(?:@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)?)`)
+
+// ParseMentions returns the distinct, lower-cased @handles in a markdown body.
+// A handle is either a full email or the part of an email before the "@".
+func ParseMentions(body string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(m[1], "."))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		out = append(out, handle)
+	}
+	return out
+}
+
+// ProjectMemberIDs returns the owner and members of a project.
+func ProjectMemberIDs(db *gorm.DB, projectID string) ([]string, error) {
+	var proj model.Project
+	if err := db.Select("id", "user_id").Where("id = ?", projectID).First(&proj).Error; err != nil {
+		return nil, err
+	}
+	var members []string
+	if err := db.Model(&model.ProjectMember{}).Where("project_id = ?", projectID).
+		Pluck("user_id", &members).Error; err != nil {
+		return nil, err
+	}
+	ids := []string{proj.UserID}
+	for _, id := range members {
+		if id != proj.UserID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ResolveMentions maps @handles to the IDs of project members whose email
+// matches in full or by local part. Handles matching nobody are ignored.
+func ResolveMentions(db *gorm.DB, projectID string, handles []string) ([]string, error) {
+	if len(handles) == 0 || projectID == "" {
+		return nil, nil
+	}
+	memberIDs, err := ProjectMemberIDs(db, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var users []model.User
+	if err := db.Select("id", "email").Where("id IN ?", memberIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(handles))
+	for _, h := range handles {
+		wanted[h] = true
+	}
+	var ids []string
+	for _, u := range users {
+		email := strings.ToLower(u.Email)
+		local, _, _ := strings.Cut(email, "@")
+		if wanted[email] || wanted[local] {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
+
+// commentProjectID is the project whose members can be mentioned in c.
+func commentProjectID(db *gorm.DB, c *model.Comment) (string, error) {
+	if c.TaskID == nil {
+		if c.ProjectID == nil {
+			return "", nil
+		}
+		return *c.ProjectID, nil
+	}
+	var task model.Task
+	if err := db.Select("id", "project_id").Where("id = ?", *c.TaskID).First(&task).Error; err != nil {
+		return "", err
+	}
+	return task.ProjectID, nil
+}
+
+// SaveComment creates c, or updates its body when it already exists,
+// replaces its mentions with those parsed from the body, and notifies the
+// users it concerns.
+func SaveComment(db *gorm.DB, c *model.Comment, create bool) error {
+	projectID, err := commentProjectID(db, c)
+	if err != nil {
+		return err
+	}
+	mentioned, err := ResolveMentions(db, projectID, ParseMentions(c.Body))
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var previous []string
+		if create {
+			if err := tx.Create(c).Error; err != nil {
+				return err
+			}
+		} else {
+			now := time.Now()
+			c.EditedAt = &now
+			if err := tx.Model(c).Select("body", "edited_at").Updates(c).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.CommentMention{}).Where("comment_id = ?", c.ID).
+				Pluck("user_id", &previous).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("comment_id = ?", c.ID).Delete(&model.CommentMention{}).Error; err != nil {
+				return err
+			}
+		}
+		c.Mentions = nil
+		var added []string
+		for _, uid := range mentioned {
+			c.Mentions = append(c.Mentions, model.CommentMention{CommentID: c.ID, UserID: uid})
+			if !slices.Contains(previous, uid) {
+				added = append(added, uid)
+			}
+		}
+		if len(c.Mentions) > 0 {
+			if err := tx.Create(&c.Mentions).Error; err != nil {
+				return err
+			}
+		}
+		// An edit only notifies users it newly mentions.
+		return notifyComment(tx, c, added, create)
+	})
+}
+
+// AuthoredComment loads a comment for modification by userID.
+func AuthoredComment(db *gorm.DB, userID, commentID string) (*model.Comment, error) {
+	if _, err := uuid.Parse(commentID); err != nil {
+		return nil, ErrCommentNotFound
+	}
+	var c model.Comment
+	if err := db.Where("id = ?", commentID).First(&c).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	if c.UserID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+	return &c, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrTaskNotFound is returned when a referenced task does not exist or the caller cannot see it.
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrDependencyCycle is returned when adding a blocker would make a task (transitively) block itself.
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	// ErrTaskBlocked is returned when completing a task that still has open blockers.
+	ErrTaskBlocked = errors.New("task has open blockers")
+)
+
+// openBlockerCond selects blocker tasks that are neither completed nor in a
+// cancelled-category status, built-in or of their project.
+const openBlockerCond = "tasks.completed_at IS NULL AND tasks.status <> 'cancelled' AND NOT EXISTS (" +
+	"SELECT 1 FROM project_statuses WHERE project_statuses.project_id = tasks.project_id " +
+	"AND project_statuses.key = tasks.status AND project_statuses.category = 'cancelled')"
+
+// AddDependency records that taskID is blocked by blockerID. The blocker must
+// be owned by userID or live in a project userID can access.
+func AddDependency(db *gorm.DB, userID, taskID, blockerID string) error {
+	if _, err := uuid.Parse(blockerID); err != nil {
+		return ErrTaskNotFound
+	}
+	if taskID == blockerID {
+		return ErrDependencyCycle
+	}
+	var blocker model.Task
+	if err := db.Select("id", "user_id", "project_id").Where("id = ?", blockerID).First(&blocker).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if blocker.UserID != userID {
+		if err := CheckProjectAccess(db, userID, blocker.ProjectID); err != nil {
+			return ErrTaskNotFound
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Serialise inserts so two concurrent requests cannot each add half of a cycle.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('task_dependencies'))").Error; err != nil {
+			return err
+		}
+		var cycle int64
+		err := tx.Raw(`WITH RECURSIVE chain(id) AS (
+				SELECT blocker_id FROM task_dependencies WHERE task_id = ?
+				UNION
+				SELECT d.blocker_id FROM task_dependencies d JOIN chain ON d.task_id = chain.id
+			)
+			SELECT COUNT(*) FROM chain WHERE id = ?`, blockerID, taskID).Scan(&cycle).Error
+		if err != nil {
+			return err
+		}
+		if cycle > 0 {
+			return fmt.Errorf("%w: %s already depends on %s", ErrDependencyCycle, blockerID, taskID)
+		}
+		dep := model.TaskDependency{TaskID: taskID, BlockerID: blockerID}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep).Error
+	})
+}
+
+// RemoveDependency removes blockerID from taskID's blockers.
+func RemoveDependency(db *gorm.DB, taskID, blockerID string) error {
+	res := db.Where("task_id = ? AND blocker_id = ?", taskID, blockerID).Delete(&model.TaskDependency{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// LoadBlockers returns, for each of taskIDs, the tasks blocking it.
+func LoadBlockers(db *gorm.DB, taskIDs []string) (map[string][]model.Task, error) {
+	out := make(map[string][]model.Task)
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	var deps []model.TaskDependency
+	if err := db.Where("task_id IN ?", taskIDs).Order("created_at").Find(&deps).Error; err != nil {
+		return nil, err
+	}
+	if len(deps) == 0 {
+		return out, nil
+	}
+	blockerIDs := make([]string, 0, len(deps))
+	for _, d := range deps {
+		blockerIDs = append(blockerIDs, d.BlockerID)
+	}
+	var blockers []model.Task
+	if err := db.Where("id IN ?", blockerIDs).Find(&blockers).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Task, len(blockers))
+	for _, b := range blockers {
+		byID[b.ID] = b
+	}
+	for _, d := range deps {
+		if b, ok := byID[d.BlockerID]; ok {
+			out[d.TaskID] = append(out[d.TaskID], b)
+		}
+	}
+	return out, nil
+}
+
+// IsOpen reports whether t, whose status is of category, still blocks the
+// tasks depending on it.
+func IsOpen(t model.Task, category string) bool {
+	return t.CompletedAt == nil && category != model.TaskStatusCancelled
+}
+
+// checkBlockers fails with ErrTaskBlocked when task has open blockers.
+func checkBlockers(db *gorm.DB, task *model.Task) error {
+	var open int64
+	err := db.Model(&model.Task{}).
+		Joins("JOIN task_dependencies ON task_dependencies.blocker_id = tasks.id").
+		Where("task_dependencies.task_id = ?", task.ID).
+		Where(openBlockerCond).
+		Count(&open).Error
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return fmt.Errorf("%w: %d still open (use force=true to override)", ErrTaskBlocked, open)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/notify"
+)
+
+var (
+	// ErrDeviceNotFound is returned when the caller has no device with the given token.
+	ErrDeviceNotFound = errors.New("device not found")
+	// ErrInvalidPlatform is returned for a platform other than apns or fcm.
+	ErrInvalidPlatform = errors.New("platform must be apns or fcm")
+)
+
+// RegisterDevice records a push token for userID. Registering a known token
+// again refreshes its app version and moves it to userID, since a device
+// signed in to another account must stop receiving the old account's pushes.
+func RegisterDevice(db *gorm.DB, userID, platform, token, appVersion string) (*model.Device, error) {
+	if platform != notify.PlatformAPNs && platform != notify.PlatformFCM {
+		return nil, ErrInvalidPlatform
+	}
+	d := &model.Device{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Platform:   platform,
+		Token:      token,
+		AppVersion: appVersion,
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "app_version", "updated_at"}),
+	}).Create(d).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("token = ?", token).First(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnregisterDevice removes the push token of userID.
+func UnregisterDevice(db *gorm.DB, userID, token string) error {
+	res := db.Where("user_id = ? AND token = ?", userID, token).Delete(&model.Device{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// DeviceStore is the notify.DeviceSource of registered devices.
+type DeviceStore struct {
+	db *gorm.DB
+}
+
+// NewDeviceStore returns a notify.DeviceSource backed by db.
+func NewDeviceStore(db *gorm.DB) *DeviceStore {
+	return &DeviceStore{db: db}
+}
+
+// Devices returns the push devices of userID.
+func (s *DeviceStore) Devices(ctx context.Context, userID string) ([]notify.Device, error) {
+	var rows []model.Device
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]notify.Device, 0, len(rows))
+	for _, d := range rows {
+		out = append(out, notify.Device{Platform: d.Platform, Token: d.Token})
+	}
+	return out, nil
+}
+
+// RemoveTokens deletes devices whose tokens a push provider rejected.
+func (s *DeviceStore) RemoveTokens(ctx context.Context, tokens []string) error {
+	return s.db.WithContext(ctx).Where("token IN ?", tokens).Delete(&model.Device{}).Error
+}
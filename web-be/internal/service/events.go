@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// ErrEventLogExpired is returned when a client resumes from a position the
+// retained event log no longer covers; it has to refetch its data.
+var ErrEventLogExpired = errors.New("event log no longer covers the requested position")
+
+// liveEntityTypes are the activity entity types pushed to live clients.
+var liveEntityTypes = []string{"task", "project", "label", "comment"}
+
+const (
+	// eventPollBatch is how many activities the broker reads per query.
+	eventPollBatch = 500
+	// eventReplayLimit bounds the events replayed on resume; a client further
+	// behind has to refetch instead.
+	eventReplayLimit = 1000
+	// eventBuffer is how many events a subscriber may fall behind before it
+	// is dropped and has to resume.
+	eventBuffer = 256
+)
+
+// LiveEvent is one change pushed to live clients: an activity of a streamed
+// entity type, named like webhook events (task.updated, comment.deleted, ...).
+type LiveEvent struct {
+	Seq      int64
+	Type     string
+	Activity *model.Activity
+}
+
+func newLiveEvent(a *model.Activity) LiveEvent {
+	return LiveEvent{Seq: a.Seq, Type: a.EntityType + "." + webhookActions[a.Action], Activity: a}
+}
+
+// EventSubscription receives the live events visible to one user. C is
+// closed when the subscriber falls too far behind.
+type EventSubscription struct {
+	UserID string
+	C      <-chan LiveEvent
+	c      chan LiveEvent
+}
+
+// EventBroker tails the activity log and fans new events out to
+// subscribers. Every instance polls the database, so events recorded by any
+// instance reach clients connected to all of them.
+type EventBroker struct {
+	db     *gorm.DB
+	window time.Duration
+
+	mu   sync.Mutex
+	subs map[*EventSubscription]struct{}
+	// cursor is the seq up to which every activity has been published; seen
+	// holds the published seqs above it.
+	cursor int64
+	seen   map[int64]bool
+	head   int64
+}
+
+// NewEventBroker returns a broker that replays events up to window old.
+func NewEventBroker(db *gorm.DB, window time.Duration) *EventBroker {
+	return &EventBroker{
+		db:     db,
+		window: window,
+		subs:   make(map[*EventSubscription]struct{}),
+		seen:   make(map[int64]bool),
+	}
+}
+
+// Run polls for new activities every interval until ctx is done.
+func (b *EventBroker) Run(ctx context.Context, interval time.Duration) {
+	var head int64
+	if err := b.db.WithContext(ctx).Model(&model.Activity{}).Select("COALESCE(MAX(seq), 0)").Scan(&head).Error; err != nil {
+		log.Printf("events: read head: %v", err)
+	}
+	b.mu.Lock()
+	b.cursor, b.head = head, head
+	b.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := b.poll(ctx); err != nil {
+			log.Printf("events: poll: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Head returns the highest seq published so far.
+func (b *EventBroker) Head() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head
+}
+
+// Subscribe registers a subscriber for the events visible to userID.
+func (b *EventBroker) Subscribe(userID string) *EventSubscription {
+	c := make(chan LiveEvent, eventBuffer)
+	sub := &EventSubscription{UserID: userID, C: c, c: c}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes sub and closes its channel.
+func (b *EventBroker) Unsubscribe(sub *EventSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Replay returns the events visible to userID after seq, oldest first. It
+// returns ErrEventLogExpired when some of them are older than the replay
+// window or there are too many to replay.
+func (b *EventBroker) Replay(ctx context.Context, userID string, after int64) ([]LiveEvent, error) {
+	db := b.db.WithContext(ctx)
+	var stale []int64
+	if err := db.Model(&model.Activity{}).Where("seq > ? AND created_at < ?", after, time.Now().Add(-b.window)).
+		Limit(1).Pluck("seq", &stale).Error; err != nil {
+		return nil, err
+	}
+	if len(stale) > 0 {
+		return nil, ErrEventLogExpired
+	}
+	var rows []model.Activity
+	if err := db.Where("seq > ? AND entity_type IN ?", after, liveEntityTypes).
+		Where("actor_id = ? OR project_id IN (SELECT id FROM projects WHERE user_id = ?) "+
+			"OR project_id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID, userID, userID).
+		Order("seq").Limit(eventReplayLimit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > eventReplayLimit {
+		return nil, ErrEventLogExpired
+	}
+	events := make([]LiveEvent, 0, len(rows))
+	for i := range rows {
+		events = append(events, newLiveEvent(&rows[i]))
+	}
+	return events, nil
+}
+
+// poll publishes the activities recorded since the last poll. Seqs are
+// assigned at insert, not commit, so an activity can become visible after
+// one with a higher seq; the cursor only moves past settled seqs, and seen
+// keeps anything above it from being published twice.
+func (b *EventBroker) poll(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		cursor := b.cursor
+		b.mu.Unlock()
+
+		// Read before the rows, so every activity up to it is visible to them.
+		_, settled, err := settledSeqs(b.db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		var rows []model.Activity
+		if err := b.db.WithContext(ctx).Where("seq > ?", cursor).Order("seq").Limit(eventPollBatch).Find(&rows).Error; err != nil {
+			return err
+		}
+		var fresh []*model.Activity
+		b.mu.Lock()
+		advancing := true
+		for i := range rows {
+			a := &rows[i]
+			if !b.seen[a.Seq] {
+				b.seen[a.Seq] = true
+				b.head = max(b.head, a.Seq)
+				if slices.Contains(liveEntityTypes, a.EntityType) {
+					fresh = append(fresh, a)
+				}
+			}
+			if advancing && a.Seq <= settled {
+				b.cursor = a.Seq
+			} else {
+				advancing = false
+			}
+		}
+		for seq := range b.seen {
+			if seq <= b.cursor {
+				delete(b.seen, seq)
+			}
+		}
+		b.mu.Unlock()
+
+		if len(fresh) > 0 {
+			b.publish(fresh)
+		}
+		if len(rows) < eventPollBatch || b.cursor == cursor {
+			return nil
+		}
+	}
+}
+
+// publish sends each activity to the subscribers allowed to see it: the
+// actor and, for project content, the project's owner and members.
+func (b *EventBroker) publish(activities []*model.Activity) {
+	audiences := make(map[string][]string)
+	for _, a := range activities {
+		if a.ProjectID == nil {
+			continue
+		}
+		if _, ok := audiences[*a.ProjectID]; ok {
+			continue
+		}
+		ids, err := ProjectMemberIDs(b.db, *a.ProjectID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("events: audience of project %s: %v", *a.ProjectID, err)
+		}
+		audiences[*a.ProjectID] = ids
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, a := range activities {
+		ev := newLiveEvent(a)
+		var audience []string
+		if a.ProjectID != nil {
+			audience = audiences[*a.ProjectID]
+		}
+		for sub := range b.subs {
+			if sub.UserID != a.ActorID && !slices.Contains(audience, sub.UserID) {
+				continue
+			}
+			select {
+			case sub.c <- ev:
+			default:
+				// Too far behind: drop it; the client resumes with Last-Event-ID.
+				delete(b.subs, sub)
+				close(sub.c)
+			}
+		}
+	}
+}
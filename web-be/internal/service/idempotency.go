@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when an idempotency key is sent
+	// again with a different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+	// ErrIdempotencyKeyInFlight is returned for a retry that arrives while the
+	// first request with its key is still running.
+	ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is still in progress")
+)
+
+// idempotencyStale is how long a request may hold its key without
+// completing before a retry takes the key over, e.g. after a crash.
+const idempotencyStale = time.Minute
+
+// IdempotencyKeys stores the outcome of requests by user and key so retries
+// get the first response instead of repeating the request.
+type IdempotencyKeys struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyKeys returns a store that keeps responses for ttl.
+func NewIdempotencyKeys(db *gorm.DB, ttl time.Duration) *IdempotencyKeys {
+	return &IdempotencyKeys{db: db, ttl: ttl}
+}
+
+// Begin claims key for a request of userID identified by hash. It returns
+// nil when the caller is to run the request and then Complete or Release
+// the key, and the stored outcome when a previous request with the same key
+// and hash completed.
+func (k *IdempotencyKeys) Begin(ctx context.Context, userID, key, hash string) (*model.IdempotencyKey, error) {
+	db := k.db.WithContext(ctx)
+	now := time.Now()
+	if err := db.Where("user_id = ? AND key = ? AND (created_at < ? OR (status = 0 AND created_at < ?))",
+		userID, key, now.Add(-k.ttl), now.Add(-idempotencyStale)).
+		Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return nil, err
+	}
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.IdempotencyKey{UserID: userID, Key: key, RequestHash: hash})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return nil, nil
+	}
+	var rec model.IdempotencyKey
+	if err := db.Where("user_id = ? AND key = ?", userID, key).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released by the request that held it; the client may retry.
+			return nil, ErrIdempotencyKeyInFlight
+		}
+		return nil, err
+	}
+	if rec.RequestHash != hash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if rec.Status == 0 {
+		return nil, ErrIdempotencyKeyInFlight
+	}
+	return &rec, nil
+}
+
+// Complete stores the outcome of the request holding key.
+func (k *IdempotencyKeys) Complete(ctx context.Context, userID, key string, status int, headers map[string]string, body []byte) error {
+	raw, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	return k.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]any{"status": status, "headers": string(raw), "body": body}).Error
+}
+
+// Release frees key after its request failed in a way worth retrying.
+func (k *IdempotencyKeys) Release(ctx context.Context, userID, key string) error {
+	return k.db.WithContext(ctx).Where("user_id = ? AND key = ? AND status = 0", userID, key).
+		Delete(&model.IdempotencyKey{}).Error
+}
+
+// Purge deletes up to limit keys older than the TTL and returns how many it
+// deleted.
+func (k *IdempotencyKeys) Purge(ctx context.Context, limit int) (int, error) {
+	res := k.db.WithContext(ctx).Exec(`DELETE FROM idempotency_keys WHERE (user_id, key) IN
+		(SELECT user_id, key FROM idempotency_keys WHERE created_at < ? LIMIT ?)`, time.Now().Add(-k.ttl), limit)
+	return int(res.RowsAffected), res.Error
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// ErrMergeConflict is wrapped by *MergeConflictError.
+var ErrMergeConflict = errors.New("conflicting changes")
+
+// TaskEdit is a client's change to the mergeable fields of a task. Nil
+// fields are left alone; LabelIDs is the client's full label set.
+type TaskEdit struct {
+	Title       *string
+	Description *string
+	DueDate     *time.Time
+	Priority    *int
+	LabelIDs    []string
+}
+
+// FieldConflict is a field changed both by the client and, since the
+// client's base version, by someone else.
+type FieldConflict struct {
+	Field  string
+	Base   any
+	Server any
+	Client any
+}
+
+// MergeConflictError reports the fields of an update that conflict with
+// changes made since its base version. Version is the task's current version,
+// to base a resolved update on.
+type MergeConflictError struct {
+	Version   int
+	Conflicts []FieldConflict
+}
+
+func (e *MergeConflictError) Error() string {
+	fields := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		fields[i] = c.Field
+	}
+	return fmt.Sprintf("%s: %s changed since the base version", ErrMergeConflict, strings.Join(fields, ", "))
+}
+
+func (e *MergeConflictError) Unwrap() error { return ErrMergeConflict }
+
+// Merge three-way merges edit, made by a client against version base of
+// task, with what changed on the server since. It locks and reloads task and
+// returns the part of edit still to apply: fields the client changed and the
+// server did not. Labels merge as sets, the client's additions and removals
+// applied to the current labels. A field both sides changed differently
+// fails the merge with *MergeConflictError.
+func (r *TaskRevisions) Merge(tx *gorm.DB, task *model.Task, base int, edit TaskEdit) (TaskEdit, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", task.ID).First(task).Error; err != nil {
+		return TaskEdit{}, err
+	}
+	if base == task.Version {
+		return edit, nil
+	}
+	current, err := TaskLabelIDs(tx, task.ID)
+	if err != nil {
+		return TaskEdit{}, err
+	}
+	snap, err := r.base(tx, task, base)
+	if err != nil {
+		return TaskEdit{}, err
+	}
+	var baseLabels []string
+	if snap == nil {
+		// Nothing mergeable changed since base.
+		snap = &model.TaskRevision{Title: task.Title, Description: task.Description, DueDate: task.DueDate, Priority: task.Priority}
+		baseLabels = current
+	} else if err := json.Unmarshal([]byte(snap.LabelIDs), &baseLabels); err != nil {
+		return TaskEdit{}, err
+	}
+
+	var out TaskEdit
+	var conflicts []FieldConflict
+	if edit.Title != nil && mergeField(&conflicts, "title", snap.Title, task.Title, *edit.Title, equal) {
+		out.Title = edit.Title
+	}
+	if edit.Description != nil && mergeField(&conflicts, "description", snap.Description, task.Description, *edit.Description, equal) {
+		out.Description = edit.Description
+	}
+	if edit.DueDate != nil && mergeField(&conflicts, "due_date", snap.DueDate, task.DueDate, edit.DueDate, sameTime) {
+		out.DueDate = edit.DueDate
+	}
+	if edit.Priority != nil && mergeField(&conflicts, "priority", snap.Priority, task.Priority, *edit.Priority, equal) {
+		out.Priority = edit.Priority
+	}
+	if len(conflicts) > 0 {
+		return TaskEdit{}, &MergeConflictError{Version: task.Version, Conflicts: conflicts}
+	}
+	if edit.LabelIDs != nil {
+		out.LabelIDs = slices.Clone(current)
+		for _, id := range edit.LabelIDs {
+			if !slices.Contains(baseLabels, id) && !slices.Contains(out.LabelIDs, id) {
+				out.LabelIDs = append(out.LabelIDs, id)
+			}
+		}
+		out.LabelIDs = slices.DeleteFunc(out.LabelIDs, func(id string) bool {
+			return slices.Contains(baseLabels, id) && !slices.Contains(edit.LabelIDs, id)
+		})
+	}
+	return out, nil
+}
+
+// base returns the snapshot holding the mergeable fields of task as of
+// version, or nil when they have not changed since. Every update of those
+// fields records a revision, so the first snapshot taken at or after version
+// has them; an unrecorded write in between (a reorder, say) left them alone.
+// It fails with ErrVersionMismatch when that snapshot may have been pruned.
+func (r *TaskRevisions) base(tx *gorm.DB, task *model.Task, version int) (*model.TaskRevision, error) {
+	if version < 1 || version > task.Version {
+		return nil, fmt.Errorf("%w: unknown base version %d", ErrVersionMismatch, version)
+	}
+	var snap model.TaskRevision
+	err := tx.Where("task_id = ? AND version >= ?", task.ID, version).Order("version, revision").First(&snap).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Pruning keeps the newest revisions, so no recorded update since.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if snap.Version == version || snap.Revision == 1 {
+		return &snap, nil
+	}
+	// Unless the revision before it survives, earlier ones were pruned and
+	// one of them may be the snapshot we want.
+	var prev int64
+	if err := tx.Model(&model.TaskRevision{}).Where("task_id = ? AND revision = ?", task.ID, snap.Revision-1).
+		Count(&prev).Error; err != nil {
+		return nil, err
+	}
+	if prev == 0 {
+		return nil, fmt.Errorf("%w: base version %d is no longer available", ErrVersionMismatch, version)
+	}
+	return &snap, nil
+}
+
+// mergeField reports whether the client's value of a field is to be
+// applied: it changed the field and the server did not. When both changed
+// it to different values, the field is added to conflicts.
+func mergeField[T any](conflicts *[]FieldConflict, field string, base, server, client T, eq func(a, b T) bool) bool {
+	if eq(client, base) || eq(client, server) {
+		return false
+	}
+	if !eq(server, base) {
+		*conflicts = append(*conflicts, FieldConflict{Field: field, Base: base, Server: server, Client: client})
+		return false
+	}
+	return true
+}
+
+func equal[T comparable](a, b T) bool { return a == b }
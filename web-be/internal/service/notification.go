@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/notify"
+)
+
+var (
+	// ErrNotificationNotFound is returned when a notification does not exist in the caller's inbox.
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrInvalidNotificationType is returned for a type not in model.NotificationTypes.
+	ErrInvalidNotificationType = errors.New("invalid notification type")
+)
+
+// notificationSendTimeout bounds one email or push delivery.
+const notificationSendTimeout = 30 * time.Second
+
+// NotificationPreferences returns the effective preference of userID for
+// every notification type.
+func NotificationPreferences(db *gorm.DB, userID string) ([]model.NotificationPreference, error) {
+	var rows []model.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]model.NotificationPreference, 0, len(model.NotificationTypes))
+	for _, typ := range model.NotificationTypes {
+		pref := model.DefaultNotificationPreference(userID, typ)
+		for _, row := range rows {
+			if row.Type == typ {
+				pref = row
+			}
+		}
+		out = append(out, pref)
+	}
+	return out, nil
+}
+
+// NotificationPreference returns the effective preference of userID for typ.
+func NotificationPreference(db *gorm.DB, userID, typ string) (model.NotificationPreference, error) {
+	if !slices.Contains(model.NotificationTypes, typ) {
+		return model.NotificationPreference{}, ErrInvalidNotificationType
+	}
+	var pref model.NotificationPreference
+	err := db.Where("user_id = ? AND type = ?", userID, typ).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.DefaultNotificationPreference(userID, typ), nil
+	}
+	return pref, err
+}
+
+// SaveNotificationPreference stores pref, replacing any earlier choice for its type.
+func SaveNotificationPreference(db *gorm.DB, pref *model.NotificationPreference) error {
+	if !slices.Contains(model.NotificationTypes, pref.Type) {
+		return ErrInvalidNotificationType
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "push", "updated_at"}),
+	}).Create(pref).Error
+}
+
+// Notify stores n for its recipient on the channels their preference for
+// n.Type selects; nothing is stored when all are off. Email and push are
+// delivered afterwards by NotificationSender. Call it in the transaction of
+// the mutation that caused it.
+func Notify(tx *gorm.DB, n *model.Notification) error {
+	pref, err := NotificationPreference(tx, n.UserID, n.Type)
+	if err != nil {
+		return err
+	}
+	if !pref.InApp && !pref.Email && !pref.Push {
+		return nil
+	}
+	n.ID = uuid.New().String()
+	n.InApp = pref.InApp
+	n.EmailStatus, n.PushStatus = "", ""
+	if pref.Email {
+		n.EmailStatus = model.DeliveryPending
+	}
+	if pref.Push {
+		n.PushStatus = model.DeliveryPending
+	}
+	return tx.Create(n).Error
+}
+
+// notifyUsers sends a copy of n to each of userIDs other than its actor.
+func notifyUsers(tx *gorm.DB, userIDs []string, n model.Notification) error {
+	for _, uid := range userIDs {
+		if n.ActorID != nil && uid == *n.ActorID {
+			continue
+		}
+		nc := n
+		nc.UserID = uid
+		if err := Notify(tx, &nc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyComment tells mentioned users they were mentioned in c, and on
+// create the task's owner and assignees (or the project's owner) that c was
+// posted. Users mentioned get only the mention.
+func notifyComment(tx *gorm.DB, c *model.Comment, mentioned []string, created bool) error {
+	subject, projectID, followers, err := commentSubject(tx, c)
+	if err != nil {
+		return err
+	}
+	base := model.Notification{
+		ActorID:   &c.UserID,
+		Body:      truncate(c.Body, 1000),
+		TaskID:    c.TaskID,
+		ProjectID: optional(projectID),
+		CommentID: &c.ID,
+	}
+	mention := base
+	mention.Type = model.NotificationMention
+	mention.Title = truncate(fmt.Sprintf("You were mentioned on %q", subject), 500)
+	if err := notifyUsers(tx, mentioned, mention); err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+	var rest []string
+	for _, uid := range followers {
+		if !slices.Contains(mentioned, uid) && !slices.Contains(rest, uid) {
+			rest = append(rest, uid)
+		}
+	}
+	comment := base
+	comment.Type = model.NotificationComment
+	comment.Title = truncate(fmt.Sprintf("New comment on %q", subject), 500)
+	return notifyUsers(tx, rest, comment)
+}
+
+// commentSubject returns the title of what c is on, its project, and who
+// follows it: the task owner and assignees, or the project owner.
+func commentSubject(tx *gorm.DB, c *model.Comment) (subject, projectID string, followers []string, err error) {
+	if c.TaskID == nil {
+		if c.ProjectID == nil {
+			return "", "", nil, nil
+		}
+		var proj model.Project
+		if err := tx.Select("id", "name", "user_id").Where("id = ?", *c.ProjectID).First(&proj).Error; err != nil {
+			return "", "", nil, err
+		}
+		return proj.Name, proj.ID, []string{proj.UserID}, nil
+	}
+	var task model.Task
+	if err := tx.Select("id", "title", "project_id", "user_id").Where("id = ?", *c.TaskID).First(&task).Error; err != nil {
+		return "", "", nil, err
+	}
+	assignees, err := TaskAssigneeIDs(tx, task.ID)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return task.Title, task.ProjectID, append([]string{task.UserID}, assignees...), nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// FindNotification loads a notification from userID's inbox.
+func FindNotification(db *gorm.DB, userID, id string) (*model.Notification, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotificationNotFound
+	}
+	var n model.Notification
+	if err := db.Where("id = ? AND user_id = ? AND in_app", id, userID).First(&n).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+	return &n, nil
+}
+
+// NotificationSender delivers the email and push side of notifications.
+type NotificationSender struct {
+	email notify.Notifier
+	push  notify.Notifier
+}
+
+// NewNotificationSender picks the email and push notifiers out of notifiers;
+// a channel without one is marked skipped.
+func NewNotificationSender(notifiers ...notify.Notifier) *NotificationSender {
+	s := &NotificationSender{}
+	for _, n := range notifiers {
+		switch n.Channel() {
+		case notify.ChannelEmail:
+			s.email = n
+		case notify.ChannelPush:
+			s.push = n
+		}
+	}
+	return s
+}
+
+// DispatchPending delivers up to limit notifications with outstanding email
+// or push deliveries and returns how many it handled. The batch is claimed in
+// a short transaction, with FOR UPDATE SKIP LOCKED so instances can run it
+// concurrently, by marking its deliveries sending; they are sent with no
+// transaction open and their outcome recorded in another. Deliveries left
+// sending past their claim, by a sender that stopped before recording them,
+// are marked failed rather than risk sending them twice. Push messages of a
+// batch go out together when the push notifier supports batching.
+func (s *NotificationSender) DispatchPending(ctx context.Context, db *gorm.DB, limit int) (int, error) {
+	db = db.WithContext(ctx)
+	if err := expireNotificationClaims(db); err != nil {
+		return 0, err
+	}
+	batch, msgs, err := claimNotifications(db, limit)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	var pushIdx []int
+	for i := range batch {
+		if batch[i].EmailStatus == model.DeliverySending {
+			batch[i].EmailStatus = deliveryStatus(s.email, msgs[i], send(ctx, s.email, msgs[i]))
+		}
+		if batch[i].PushStatus == model.DeliverySending {
+			pushIdx = append(pushIdx, i)
+		}
+	}
+	for n, err := range s.sendPush(ctx, msgs, pushIdx) {
+		batch[pushIdx[n]].PushStatus = deliveryStatus(s.push, msgs[pushIdx[n]], err)
+	}
+
+	// Recorded even when ctx was cancelled meanwhile: the messages went out.
+	err = db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		for i := range batch {
+			batch[i].ClaimedUntil = nil
+			if err := tx.Model(&batch[i]).Select("email_status", "push_status", "claimed_until").Updates(&batch[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(batch), err
+}
+
+// claimNotifications marks the pending deliveries of up to limit
+// notifications sending, long enough for every one of them to time out, and
+// returns them with their messages.
+func claimNotifications(db *gorm.DB, limit int) ([]model.Notification, []notify.Message, error) {
+	var batch []model.Notification
+	var msgs []notify.Message
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("email_status = ? OR push_status = ?", model.DeliveryPending, model.DeliveryPending).
+			Order("created_at").Limit(limit).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		var err error
+		if msgs, err = notificationMessages(tx, batch); err != nil {
+			return err
+		}
+		// Emails go out one by one, then push in one go.
+		until := time.Now().Add(notificationSendTimeout * time.Duration(len(batch)+2))
+		for i := range batch {
+			if batch[i].EmailStatus == model.DeliveryPending {
+				batch[i].EmailStatus = model.DeliverySending
+			}
+			if batch[i].PushStatus == model.DeliveryPending {
+				batch[i].PushStatus = model.DeliverySending
+			}
+			batch[i].ClaimedUntil = &until
+			if err := tx.Model(&batch[i]).Select("email_status", "push_status", "claimed_until").Updates(&batch[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, msgs, nil
+}
+
+// expireNotificationClaims marks deliveries still sending past their claim
+// failed.
+func expireNotificationClaims(db *gorm.DB) error {
+	now := time.Now()
+	for _, channel := range []string{notify.ChannelEmail, notify.ChannelPush} {
+		col := channel + "_status"
+		res := db.Model(&model.Notification{}).
+			Where(col+" = ? AND claimed_until < ?", model.DeliverySending, now).
+			Update(col, model.DeliveryFailed)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			log.Printf("notifications: %d %s deliveries outlived their claim, marked failed", res.RowsAffected, channel)
+		}
+	}
+	return nil
+}
+
+// notificationMessages renders batch as messages, looking up recipients' emails.
+func notificationMessages(tx *gorm.DB, batch []model.Notification) ([]notify.Message, error) {
+	userIDs := make([]string, 0, len(batch))
+	for _, n := range batch {
+		userIDs = append(userIDs, n.UserID)
+	}
+	var users []model.User
+	if err := tx.Select("id", "email").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	emails := make(map[string]string, len(users))
+	for _, u := range users {
+		emails[u.ID] = u.Email
+	}
+	msgs := make([]notify.Message, len(batch))
+	for i, n := range batch {
+		msgs[i] = notify.Message{
+			UserID: n.UserID,
+			Email:  emails[n.UserID],
+			Title:  n.Title,
+			Body:   n.Body,
+			Data:   map[string]string{"type": n.Type, "notification_id": n.ID},
+		}
+		if n.TaskID != nil {
+			msgs[i].Data["task_id"] = *n.TaskID
+		}
+	}
+	return msgs, nil
+}
+
+// sendPush delivers msgs[idx...] over push and returns one error per index.
+func (s *NotificationSender) sendPush(ctx context.Context, msgs []notify.Message, idx []int) []error {
+	errs := make([]error, len(idx))
+	if s.push == nil || len(idx) == 0 {
+		return errs
+	}
+	ctx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+	if batcher, ok := s.push.(notify.BatchNotifier); ok {
+		picked := make([]notify.Message, len(idx))
+		for n, i := range idx {
+			picked[n] = msgs[i]
+		}
+		return batcher.NotifyBatch(ctx, picked)
+	}
+	for n, i := range idx {
+		errs[n] = s.push.Notify(ctx, msgs[i])
+	}
+	return errs
+}
+
+// send delivers msg through n, which may be nil when the channel is not configured.
+func send(ctx context.Context, n notify.Notifier, msg notify.Message) error {
+	if n == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+	return n.Notify(ctx, msg)
+}
+
+// deliveryStatus maps the outcome of sending msg through n to a delivery
+// status. An unconfigured channel counts as skipped.
+func deliveryStatus(n notify.Notifier, msg notify.Message, err error) string {
+	switch {
+	case n == nil, errors.Is(err, notify.ErrNoRecipient):
+		return model.DeliverySkipped
+	case err != nil:
+		log.Printf("notifications: %s to user %s: %v", n.Channel(), msg.UserID, err)
+		return model.DeliveryFailed
+	}
+	return model.DeliverySent
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPosition is returned when a before/after reference is not a sibling
+// of the row being placed, or the references are out of order.
+var ErrInvalidPosition = errors.New("invalid position")
+
+// sortKeyDigits is the alphabet for fractional sort keys, in byte order so
+// keys compare correctly under the "C" collation used by sort_key columns.
+const sortKeyDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// KeyBetween returns a sort key strictly between a and b. An empty a means
+// "before everything", an empty b means "after everything". Placing a row
+// only ever writes that row's key, so reordering never rewrites siblings.
+func KeyBetween(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", ErrInvalidPosition
+	}
+	if strings.HasSuffix(a, "0") || strings.HasSuffix(b, "0") {
+		return "", ErrInvalidPosition
+	}
+	return midpoint(a, b), nil
+}
+
+// midpoint implements the digit-wise midpoint from the fractional-indexing
+// scheme: keys never end in the zero digit, so there is always room between two keys.
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(sortKeyDigits, a[0])
+	}
+	digitB := len(sortKeyDigits)
+	if b != "" {
+		digitB = strings.IndexByte(sortKeyDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(sortKeyDigits[(digitA+digitB+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(sortKeyDigits[digitA]) + midpoint(rest, "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return sortKeyDigits[0]
+}
+
+// positionBetween computes a sort key for placing a row among its siblings.
+// siblings must return a fresh query over the sibling rows (excluding the row
+// being placed). afterID/beforeID name the neighbours; when only one is given
+// the other is looked up, and when neither is given the row goes last.
+func positionBetween(siblings func() *gorm.DB, afterID, beforeID string) (string, error) {
+	var lower, upper string
+	if afterID != "" {
+		key, err := siblingKey(siblings(), afterID)
+		if err != nil {
+			return "", err
+		}
+		lower = key
+	}
+	if beforeID != "" {
+		key, err := siblingKey(siblings(), beforeID)
+		if err != nil {
+			return "", err
+		}
+		upper = key
+	}
+
+	switch {
+	case afterID != "" && beforeID == "":
+		if err := siblings().Where("sort_key > ?", lower).
+			Select("COALESCE(MIN(sort_key), '')").Scan(&upper).Error; err != nil {
+			return "", err
+		}
+	case afterID == "" && beforeID != "":
+		if err := siblings().Where("sort_key < ?", upper).
+			Select("COALESCE(MAX(sort_key), '')").Scan(&lower).Error; err != nil {
+			return "", err
+		}
+	case afterID == "" && beforeID == "":
+		if err := siblings().Select("COALESCE(MAX(sort_key), '')").Scan(&lower).Error; err != nil {
+			return "", err
+		}
+	}
+	return KeyBetween(lower, upper)
+}
+
+func siblingKey(q *gorm.DB, id string) (string, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrInvalidPosition
+	}
+	var keys []string
+	if err := q.Where("id = ?", id).Limit(1).Pluck("sort_key", &keys).Error; err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", ErrInvalidPosition
+	}
+	return keys[0], nil
+}
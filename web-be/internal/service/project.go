@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// InboxProjectName is the name given to each user's default project.
+const InboxProjectName = "Inbox"
+
+var (
+	// ErrProjectNotFound is returned when a project does not exist.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectForbidden is returned when a project exists but the caller is neither its owner nor a member.
+	ErrProjectForbidden = errors.New("no access to project")
+)
+
+// EnsureInbox returns the user's Inbox project, creating it on first use.
+// Tasks the user created before the Inbox existed (project_id NULL) are moved into it.
+func EnsureInbox(db *gorm.DB, userID string) (*model.Project, error) {
+	var inbox model.Project
+	err := db.Where("user_id = ? AND is_inbox = ?", userID, true).First(&inbox).Error
+	if err == nil {
+		return &inbox, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		proj := model.Project{
+			ID:      uuid.New().String(),
+			Name:    InboxProjectName,
+			UserID:  userID,
+			IsInbox: true,
+		}
+		// A concurrent request may win the race; the partial unique index
+		// on (user_id) WHERE is_inbox turns our insert into a no-op.
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&proj)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&model.Task{}).
+			Where("user_id = ? AND project_id IS NULL", userID).
+			Update("project_id", proj.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Where("user_id = ? AND is_inbox = ?", userID, true).First(&inbox).Error; err != nil {
+		return nil, err
+	}
+	return &inbox, nil
+}
+
+// ResolveTaskProject returns the project ID a task should be filed under.
+// An empty projectID resolves to the user's Inbox; otherwise the caller must
+// pass CheckProjectAccess for it.
+func ResolveTaskProject(db *gorm.DB, userID, projectID string) (string, error) {
+	if projectID == "" {
+		inbox, err := EnsureInbox(db, userID)
+		if err != nil {
+			return "", err
+		}
+		return inbox.ID, nil
+	}
+	if err := CheckProjectAccess(db, userID, projectID); err != nil {
+		return "", err
+	}
+	return projectID, nil
+}
+
+// CheckProjectAccess reports whether userID owns or is a member of the project.
+// It returns ErrProjectNotFound for unknown IDs and ErrProjectForbidden when
+// the project exists but belongs to someone else.
+func CheckProjectAccess(db *gorm.DB, userID, projectID string) error {
+	if _, err := uuid.Parse(projectID); err != nil {
+		return ErrProjectNotFound
+	}
+	var proj model.Project
+	if err := db.Select("id", "user_id").Where("id = ?", projectID).First(&proj).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	if proj.UserID == userID {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&model.ProjectMember{}).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProjectForbidden
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrWIPLimit is wrapped by *WIPLimitError.
+	ErrWIPLimit = errors.New("wip limit reached")
+	// ErrInvalidStatusKey is returned for a malformed or clashing custom status key.
+	ErrInvalidStatusKey = errors.New("invalid status key")
+)
+
+// statusKeyPattern limits custom keys to what fits tasks.status (size 20).
+var statusKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,19}$`)
+
+// builtinStatusOrder is the column order of the built-in statuses on a status board.
+var builtinStatusOrder = []string{
+	model.TaskStatusPending,
+	model.TaskStatusInProgress,
+	model.TaskStatusCompleted,
+	model.TaskStatusCancelled,
+}
+
+// WIPLimitError reports a status column that is already at its WIP limit.
+type WIPLimitError struct {
+	Status  string
+	Limit   int
+	Current int64
+}
+
+func (e *WIPLimitError) Error() string {
+	return fmt.Sprintf("%s: %q allows %d tasks, %d already there", ErrWIPLimit, e.Status, e.Limit, e.Current)
+}
+
+func (e *WIPLimitError) Unwrap() error { return ErrWIPLimit }
+
+// StatusSet maps the status keys usable in a project to their built-in category.
+type StatusSet map[string]string
+
+// BuiltinStatuses returns the statuses every project has.
+func BuiltinStatuses() StatusSet {
+	set := make(StatusSet, len(builtinStatusOrder))
+	for _, s := range builtinStatusOrder {
+		set[s] = s
+	}
+	return set
+}
+
+// LoadStatusSet returns the built-in statuses plus the custom statuses of projectID.
+func LoadStatusSet(db *gorm.DB, projectID string) (StatusSet, error) {
+	set := BuiltinStatuses()
+	if projectID == "" {
+		return set, nil
+	}
+	var custom []model.ProjectStatus
+	if err := db.Where("project_id = ?", projectID).Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	for _, ps := range custom {
+		set[ps.Key] = ps.Category
+	}
+	return set, nil
+}
+
+// ValidateProjectStatus checks a custom status definition. A built-in key may
+// be redefined only to give its column a name or WIP limit, so its category
+// must be itself.
+func ValidateProjectStatus(ps *model.ProjectStatus) error {
+	if !statusKeyPattern.MatchString(ps.Key) {
+		return fmt.Errorf("%w: %q", ErrInvalidStatusKey, ps.Key)
+	}
+	if !isTaskStatus(ps.Category) {
+		return fmt.Errorf("%w: category %q", ErrInvalidStatus, ps.Category)
+	}
+	if isTaskStatus(ps.Key) && ps.Key != ps.Category {
+		return fmt.Errorf("%w: built-in %q cannot change category", ErrInvalidStatusKey, ps.Key)
+	}
+	if ps.WIPLimit < 0 {
+		return fmt.Errorf("%w: negative wip limit", ErrInvalidStatusKey)
+	}
+	return nil
+}
+
+// StatusColumns returns the columns of a project's status board: built-in
+// statuses in category order, each followed by the custom statuses of that
+// category. Project rows for built-in keys override their name and WIP limit.
+func StatusColumns(db *gorm.DB, projectID string) ([]model.ProjectStatus, error) {
+	var custom []model.ProjectStatus
+	if err := db.Where("project_id = ?", projectID).Order("created_at").Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	var columns []model.ProjectStatus
+	for _, cat := range builtinStatusOrder {
+		builtin := model.ProjectStatus{ProjectID: projectID, Key: cat, Name: cat, Category: cat}
+		for _, ps := range custom {
+			if ps.Key == cat {
+				builtin = ps
+			}
+		}
+		columns = append(columns, builtin)
+		for _, ps := range custom {
+			if ps.Category == cat && ps.Key != cat {
+				columns = append(columns, ps)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// checkWIPLimit fails with *WIPLimitError when moving task into status of
+// its project would exceed the column's WIP limit. The column's row stays
+// locked until db, a transaction, ends, so that concurrent moves into the
+// column count one another.
+func checkWIPLimit(db *gorm.DB, task *model.Task, status string) error {
+	var ps model.ProjectStatus
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ? AND key = ?", task.ProjectID, status).First(&ps).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && ps.WIPLimit == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := db.Model(&model.Task{}).
+		Where("project_id = ? AND status = ? AND id <> ?", task.ProjectID, status, task.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count >= int64(ps.WIPLimit) {
+		return &WIPLimitError{Status: status, Limit: ps.WIPLimit, Current: count}
+	}
+	return nil
+}
@@ -0,0 +1,669 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidQuickAdd is returned for quick-add text that leaves no title.
+var ErrInvalidQuickAdd = errors.New("invalid quick add")
+
+// QuickAdd is a task parsed from a line of quick-add text.
+type QuickAdd struct {
+	Title      string
+	DueDate    *time.Time
+	AllDay     bool   // no time of day was given; DueDate is the end of the day
+	Recurrence string // see ParseRecurrence
+	Project    string // one of the project names passed in, "" for none
+	Labels     []string
+	Priority   int // model.Task.Priority scale: p1 is 4, p4 is 1
+}
+
+// quickPunct is trimmed off the end of #project and @label names.
+const quickPunct = ",.;:!?，。；：！？、"
+
+// Number words and characters accepted wherever a count or day is.
+const quickNum = `[0-9０-９一二三四五六七八九十兩两]{1,3}`
+
+const (
+	enWeekday = `(mon(?:day)?|tue(?:s(?:day)?)?|wed(?:nesday)?|thu(?:r(?:s(?:day)?)?)?|fri(?:day)?|sat(?:urday)?|sun(?:day)?)`
+	enMonth   = `(jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|may|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)`
+	enOrdinal = `(\d{1,2})(?:st|nd|rd|th)`
+	enCount   = `(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve)`
+	enDue     = `(?:(?:on|by|due)\s+)?`
+	zhWeek    = `(?:週|周|星期|禮拜|礼拜)`
+	zhWeekday = `([一二三四五六日天])`
+	zhPeriod  = `(凌晨|早上|上午|中午|下午|傍晚|晚上)`
+	zhHour    = `(?:點鐘|點|点钟|点|時|时)`
+)
+
+var (
+	quickProject  = regexp.MustCompile(`(?:^|\s)#(\S+)`)
+	quickLabel    = regexp.MustCompile(`(?:^|\s)@(\S+)`)
+	quickPriority = regexp.MustCompile(`(?i)(?:^|\s)p([1-4])(?:\s|$)`)
+	quickDangling = regexp.MustCompile(`(?i)(?:^\s*(?:on|at|by|due)\s+|\s+(?:on|at|by|due)\s*$)`)
+	enWeekdayRe   = regexp.MustCompile(`(?i)` + enWeekday)
+)
+
+var enNumbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var zhDigits = map[rune]int{
+	'一': 1, '二': 2, '兩': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// quickRule matches a phrase and applies it, rejecting the match (e.g. an
+// impossible date) by returning false.
+type quickRule struct {
+	re    *regexp.Regexp
+	apply func(p *quickAddParser, m []string) bool
+}
+
+func rule(pattern string, apply func(p *quickAddParser, m []string) bool) quickRule {
+	return quickRule{regexp.MustCompile(`(?i)` + pattern), apply}
+}
+
+// Recurrence phrases, tried before dates so that "every monday" is not
+// read as a date.
+var recurrenceRules = []quickRule{
+	rule(`\bevery\s+(other\s+)?(day|week|month|year)(?:\s+on\s+the\s+`+enOrdinal+`)?\b`, func(p *quickAddParser, m []string) bool {
+		interval := 1
+		if m[1] != "" {
+			interval = 2
+		}
+		return p.repeat(enFreq(m[2]), interval, atoi(m[3]))
+	}),
+	rule(`\bevery\s+`+enCount+`\s+(days?|weeks?|months?|years?)\b`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(enFreq(m[2]), number(m[1]), 0)
+	}),
+	rule(`\bevery\s+(?:weekday|workday)s?\b`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(FreqWeekly, 1, 0, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	}),
+	rule(`\bevery\s+weekends?\b`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(FreqWeekly, 1, 0, time.Saturday, time.Sunday)
+	}),
+	rule(`\bevery\s+(`+enWeekday+`(?:\s*(?:,|and|&)\s*`+enWeekday+`)*)\b`, func(p *quickAddParser, m []string) bool {
+		var days []time.Weekday
+		for _, d := range enWeekdayRe.FindAllString(m[1], -1) {
+			days = append(days, enWeekdayOf(d))
+		}
+		return p.repeat(FreqWeekly, 1, 0, days...)
+	}),
+	rule(`\bevery\s+`+enOrdinal+`\b`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(FreqMonthly, 1, atoi(m[1]))
+	}),
+	rule(`(?:每個?|每一個)?(?:工作日|平日)`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(FreqWeekly, 1, 0, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	}),
+	rule(`每個?`+zhWeek+`([一二三四五六日天](?:[、,，和及與与]?[一二三四五六日天])*)?`, func(p *quickAddParser, m []string) bool {
+		var days []time.Weekday
+		for _, r := range m[1] {
+			if d, ok := zhWeekdayOf(r); ok {
+				days = append(days, d)
+			}
+		}
+		return p.repeat(FreqWeekly, 1, 0, days...)
+	}),
+	rule(`每個?月的?(?:(`+quickNum+`)\s*(?:號|号|日))?`, func(p *quickAddParser, m []string) bool {
+		return p.repeat(FreqMonthly, 1, number(m[1]))
+	}),
+	rule(`每(隔)?(`+quickNum+`)\s*個?(天|日|週|周|星期|禮拜|礼拜|月|年)`, func(p *quickAddParser, m []string) bool {
+		n := number(m[2])
+		if m[1] != "" {
+			n++ // 每隔一天 is every other day
+		}
+		return p.repeat(zhFreq(m[3]), n, 0)
+	}),
+	rule(`每(?:天|日)`, func(p *quickAddParser, m []string) bool { return p.repeat(FreqDaily, 1, 0) }),
+	rule(`每年`, func(p *quickAddParser, m []string) bool { return p.repeat(FreqYearly, 1, 0) }),
+}
+
+// Date phrases.
+var dateRules = []quickRule{
+	rule(`\b`+enDue+`(\d{4})-(\d{1,2})-(\d{1,2})\b`, func(p *quickAddParser, m []string) bool {
+		return p.on(atoi(m[1]), time.Month(atoi(m[2])), atoi(m[3]))
+	}),
+	rule(`\b`+enDue+`(?:the\s+)?day\s+after\s+tomorrow\b`, func(p *quickAddParser, m []string) bool {
+		return p.in(0, 0, 2)
+	}),
+	rule(`\b`+enDue+`(today|tonight|tomorrow|tmrw?)\b`, func(p *quickAddParser, m []string) bool {
+		switch strings.ToLower(m[1]) {
+		case "today":
+			return p.in(0, 0, 0)
+		case "tonight":
+			p.evening = true
+			return p.in(0, 0, 0)
+		}
+		return p.in(0, 0, 1)
+	}),
+	rule(`\b(?:(?:on|by|due)\s+)?(this|next)\s+`+enWeekday+`\b`, func(p *quickAddParser, m []string) bool {
+		return p.weekday(enWeekdayOf(m[2]), strings.EqualFold(m[1], "next"))
+	}),
+	// Abbreviations only after on/by/due, so that "sun" and "sat" in a title
+	// are left alone.
+	rule(`\b(?:(?:on|by|due)\s+`+enWeekday+`|(monday|tuesday|wednesday|thursday|friday|saturday|sunday))\b`, func(p *quickAddParser, m []string) bool {
+		return p.weekday(enWeekdayOf(m[1]+m[2]), false)
+	}),
+	rule(`\bnext\s+(week|month|year)\b`, func(p *quickAddParser, m []string) bool {
+		return p.nextPeriod(enFreq(m[1]))
+	}),
+	rule(`\bin\s+`+enCount+`\s+(days?|weeks?|months?|years?)\b`, func(p *quickAddParser, m []string) bool {
+		return p.after(number(m[1]), enFreq(m[2]))
+	}),
+	rule(`\b`+enDue+enMonth+`\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(atoi(m[3]), enMonthOf(m[1]), atoi(m[2]))
+	}),
+	rule(`\b`+enDue+`(?:the\s+)?(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?`+enMonth+`(?:,?\s+(\d{4}))?\b`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(atoi(m[3]), enMonthOf(m[2]), atoi(m[1]))
+	}),
+	rule(`\b`+enDue+`(\d{1,2})/(\d{1,2})(?:/(\d{4}))?\b`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(atoi(m[3]), time.Month(atoi(m[1])), atoi(m[2]))
+	}),
+	rule(`\b(?:on\s+)?the\s+`+enOrdinal+`\b`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(0, 0, atoi(m[1]))
+	}),
+	rule(`(大後天|大后天|後天|后天|明天|明日|今天|今日|今晚)`, func(p *quickAddParser, m []string) bool {
+		switch m[1] {
+		case "大後天", "大后天":
+			return p.in(0, 0, 3)
+		case "後天", "后天":
+			return p.in(0, 0, 2)
+		case "明天", "明日":
+			return p.in(0, 0, 1)
+		case "今晚":
+			p.evening = true
+		}
+		return p.in(0, 0, 0)
+	}),
+	rule(`(下下|下|這|这|本)?個?`+zhWeek+zhWeekday, func(p *quickAddParser, m []string) bool {
+		d, _ := zhWeekdayOf([]rune(m[2])[0])
+		switch m[1] {
+		case "下":
+			return p.weekday(d, true)
+		case "下下":
+			p.weekday(d, true)
+			t := p.date.AddDate(0, 0, 7)
+			p.date = &t
+			return true
+		case "這", "这", "本":
+			return p.thisWeek(d)
+		}
+		return p.weekday(d, false)
+	}),
+	rule(`下個?`+zhWeek, func(p *quickAddParser, m []string) bool { return p.nextPeriod(FreqWeekly) }),
+	rule(`下個?月`, func(p *quickAddParser, m []string) bool { return p.nextPeriod(FreqMonthly) }),
+	rule(`明年`, func(p *quickAddParser, m []string) bool { return p.nextPeriod(FreqYearly) }),
+	rule(`(`+quickNum+`)\s*個?(天|日|週|周|星期|禮拜|礼拜|月|年)(?:之)?(?:後|后)`, func(p *quickAddParser, m []string) bool {
+		return p.after(number(m[1]), zhFreq(m[2]))
+	}),
+	rule(`(?:(\d{4})\s*年\s*)?(`+quickNum+`)\s*月\s*(`+quickNum+`)\s*(?:日|號|号)?`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(atoi(m[1]), time.Month(number(m[2])), number(m[3]))
+	}),
+	rule(`(`+quickNum+`)\s*(?:號|号)`, func(p *quickAddParser, m []string) bool {
+		return p.onDay(0, 0, number(m[1]))
+	}),
+}
+
+// Time-of-day phrases.
+var timeRules = []quickRule{
+	rule(zhPeriod+`?\s*(`+quickNum+`)\s*`+zhHour+`(?:\s*(半|`+quickNum+`)\s*分?)?`, func(p *quickAddParser, m []string) bool {
+		minute := 0
+		if m[3] == "半" {
+			minute = 30
+		} else if m[3] != "" {
+			minute = number(m[3])
+		}
+		return p.zhAt(m[1], number(m[2]), minute)
+	}),
+	rule(zhPeriod+`\s*(\d{1,2}):(\d{2})`, func(p *quickAddParser, m []string) bool {
+		return p.zhAt(m[1], atoi(m[2]), atoi(m[3]))
+	}),
+	rule(`\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`, func(p *quickAddParser, m []string) bool {
+		h := atoi(m[1])
+		if h < 1 || h > 12 {
+			return false
+		}
+		h %= 12
+		if strings.EqualFold(m[3], "pm") {
+			h += 12
+		}
+		return p.at(h, atoi(m[2]))
+	}),
+	rule(`\b(?:at\s+)?(\d{1,2}):(\d{2})\b`, func(p *quickAddParser, m []string) bool {
+		return p.at(atoi(m[1]), atoi(m[2]))
+	}),
+	rule(`\b(?:at\s+)?(noon|midnight)\b`, func(p *quickAddParser, m []string) bool {
+		if strings.EqualFold(m[1], "noon") {
+			return p.at(12, 0)
+		}
+		return p.at(0, 0)
+	}),
+}
+
+// quickAddParser holds what has been read from the text so far.
+type quickAddParser struct {
+	text    string
+	now     time.Time
+	date    *time.Time // midnight of the due day, in now's location
+	hour    int
+	minute  int
+	timed   bool
+	evening bool // "tonight": 20:00 unless a time is given
+	rec     *Recurrence
+}
+
+// ParseQuickAdd reads a task from text such as "Pay rent every month on the
+// 1st 9am #Home @bills p1" or "明天下午3點開會 #工作": a due date and time,
+// recurrence, project, labels and priority in English or Chinese, the rest
+// being the title. Dates are relative to now, in now's location. A #name is
+// only taken as the project when it is one of projects, compared without
+// regard to case; otherwise it stays in the title. A time without a date is
+// today's, or tomorrow's once it has passed; a date without a time is due at
+// the end of that day.
+func ParseQuickAdd(text string, now time.Time, projects []string) (*QuickAdd, error) {
+	p := &quickAddParser{text: text, now: now}
+	out := &QuickAdd{}
+
+	p.text = quickProject.ReplaceAllStringFunc(p.text, func(s string) string {
+		name := strings.TrimRight(quickProject.FindStringSubmatch(s)[1], quickPunct)
+		for _, proj := range projects {
+			if out.Project == "" && strings.EqualFold(proj, name) {
+				out.Project = proj
+				return " "
+			}
+		}
+		return s
+	})
+	for _, m := range quickLabel.FindAllStringSubmatch(p.text, -1) {
+		if name := strings.TrimRight(m[1], quickPunct); name != "" && !containsFold(out.Labels, name) {
+			out.Labels = append(out.Labels, name)
+		}
+	}
+	p.text = quickLabel.ReplaceAllString(p.text, " ")
+	if m := quickPriority.FindStringSubmatch(p.text); m != nil {
+		out.Priority = 5 - atoi(m[1])
+		p.text = strings.Replace(p.text, m[0], " ", 1)
+	}
+
+	p.first(recurrenceRules)
+	p.first(dateRules)
+	p.first(timeRules)
+
+	for {
+		t := quickDangling.ReplaceAllString(p.text, "")
+		if t == p.text {
+			break
+		}
+		p.text = t
+	}
+	out.Title = strings.Join(strings.Fields(p.text), " ")
+	if out.Title == "" {
+		return nil, fmt.Errorf("%w: the text has no title", ErrInvalidQuickAdd)
+	}
+
+	due := p.due()
+	if due == nil {
+		return out, nil
+	}
+	out.AllDay = !p.timed && !p.evening
+	if p.rec != nil {
+		first := p.rec.First(*due)
+		if !first.After(now) {
+			first = p.rec.Next(first)
+		}
+		due = &first
+		if p.rec.Freq == FreqMonthly && p.rec.ByMonthDay == 0 {
+			p.rec.ByMonthDay = due.Day()
+		}
+		out.Recurrence = p.rec.String()
+	}
+	out.DueDate = due
+	return out, nil
+}
+
+// first applies the first rule of rules that matches, removing its phrase
+// from the text.
+func (p *quickAddParser) first(rules []quickRule) {
+	for _, r := range rules {
+		loc := r.re.FindStringSubmatchIndex(p.text)
+		if loc == nil {
+			continue
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = p.text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		if !r.apply(p, m) {
+			continue
+		}
+		p.text = joinAround(p.text[:loc[0]], p.text[loc[1]:])
+		return
+	}
+}
+
+// due returns the due time read, if any.
+func (p *quickAddParser) due() *time.Time {
+	if p.date == nil && !p.timed && !p.evening && p.rec == nil {
+		return nil
+	}
+	day := p.date
+	if day == nil {
+		today := p.day(p.now.Year(), p.now.Month(), p.now.Day())
+		day = &today
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, p.now.Location())
+	}
+	var due time.Time
+	switch {
+	case p.timed:
+		due = at(p.hour, p.minute)
+		if p.date == nil && p.rec == nil && !due.After(p.now) {
+			due = due.AddDate(0, 0, 1)
+		}
+	case p.evening:
+		due = at(20, 0)
+	default:
+		due = at(23, 59)
+	}
+	return &due
+}
+
+func (p *quickAddParser) repeat(freq string, interval, monthDay int, days ...time.Weekday) bool {
+	if freq == "" || interval < 1 || monthDay < 0 || monthDay > 31 {
+		return false
+	}
+	p.rec = &Recurrence{Freq: freq, Interval: interval, ByDay: days, ByMonthDay: monthDay, Location: p.now.Location()}
+	if len(days) > 0 {
+		// Normalise the order as ParseRecurrence does.
+		r, err := ParseRecurrence(p.rec.String())
+		if err != nil {
+			return false
+		}
+		p.rec = r
+	}
+	return true
+}
+
+func (p *quickAddParser) day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, p.now.Location())
+}
+
+// on sets an exact date.
+func (p *quickAddParser) on(y int, m time.Month, d int) bool {
+	if m < 1 || m > 12 || d < 1 || d > clampDay(y, m, d) {
+		return false
+	}
+	t := p.day(y, m, d)
+	p.date = &t
+	return true
+}
+
+// onDay sets a date given in part: without a year it is the next such date
+// from today, and without a month as well the next such day of a month.
+func (p *quickAddParser) onDay(y int, m time.Month, d int) bool {
+	today := p.day(p.now.Year(), p.now.Month(), p.now.Day())
+	switch {
+	case y > 0:
+		return p.on(y, m, d)
+	case m > 0:
+		for y := today.Year(); y <= today.Year()+4; y++ {
+			if t := p.day(y, m, d); t.Day() == d && !t.Before(today) {
+				return p.on(y, m, d)
+			}
+		}
+		return false
+	}
+	if d < 1 || d > 31 {
+		return false
+	}
+	for i := 0; i < 12; i++ {
+		first := today.AddDate(0, i, 1-today.Day())
+		if t := p.day(first.Year(), first.Month(), d); t.Day() == d && !t.Before(today) {
+			return p.on(first.Year(), first.Month(), d)
+		}
+	}
+	return false
+}
+
+// in sets the date years, months and days from today, on the last day of
+// the month when the month reached is shorter than today's day.
+func (p *quickAddParser) in(years, months, days int) bool {
+	month := p.day(p.now.Year()+years, p.now.Month()+time.Month(months), 1)
+	t := p.day(month.Year(), month.Month(), clampDay(month.Year(), month.Month(), p.now.Day())+days)
+	p.date = &t
+	return true
+}
+
+// after sets the date n days, weeks, months or years from today.
+func (p *quickAddParser) after(n int, freq string) bool {
+	if n < 1 {
+		return false
+	}
+	switch freq {
+	case FreqDaily:
+		return p.in(0, 0, n)
+	case FreqWeekly:
+		return p.in(0, 0, 7*n)
+	case FreqMonthly:
+		return p.in(0, n, 0)
+	case FreqYearly:
+		return p.in(n, 0, 0)
+	}
+	return false
+}
+
+// weekday sets the next d from today, today included; with next, d of next
+// week (weeks starting on Monday).
+func (p *quickAddParser) weekday(d time.Weekday, next bool) bool {
+	if next {
+		monday := 7 - (int(p.now.Weekday())+6)%7
+		return p.in(0, 0, monday+(int(d)+6)%7)
+	}
+	return p.in(0, 0, (int(d)-int(p.now.Weekday())+7)%7)
+}
+
+// thisWeek sets d of the current week, or of next week once it has passed.
+func (p *quickAddParser) thisWeek(d time.Weekday) bool {
+	off := (int(d)+6)%7 - (int(p.now.Weekday())+6)%7
+	if off < 0 {
+		off += 7
+	}
+	return p.in(0, 0, off)
+}
+
+// nextPeriod sets the start of next week (Monday), month or year.
+func (p *quickAddParser) nextPeriod(freq string) bool {
+	switch freq {
+	case FreqWeekly:
+		return p.weekday(time.Monday, true)
+	case FreqMonthly:
+		return p.on(p.now.Year(), p.now.Month()+1, 1) || p.on(p.now.Year()+1, 1, 1)
+	case FreqYearly:
+		return p.on(p.now.Year()+1, 1, 1)
+	}
+	return false
+}
+
+// at sets the time of day.
+func (p *quickAddParser) at(hour, minute int) bool {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return false
+	}
+	p.hour, p.minute, p.timed = hour, minute, true
+	return true
+}
+
+// zhAt sets a time given with an optional part of the day such as 下午.
+func (p *quickAddParser) zhAt(period string, hour, minute int) bool {
+	switch period {
+	case "下午", "傍晚", "晚上":
+		if hour < 12 {
+			hour += 12
+		}
+	case "中午":
+		if hour < 6 {
+			hour += 12
+		}
+	case "凌晨":
+		if hour == 12 {
+			hour = 0
+		}
+	case "":
+		if p.evening && hour < 12 {
+			hour += 12
+		}
+	}
+	return p.at(hour, minute)
+}
+
+// joinAround joins the text before and after a removed phrase, with a space
+// unless either side is CJK, which is written without spaces.
+func joinAround(before, after string) string {
+	b, a := strings.TrimRightFunc(before, unicode.IsSpace), strings.TrimLeftFunc(after, unicode.IsSpace)
+	if b == "" || a == "" {
+		return b + a
+	}
+	last, _ := utf8.DecodeLastRuneInString(b)
+	first, _ := utf8.DecodeRuneInString(a)
+	if hasCJK(string(last)) || hasCJK(string(first)) {
+		return b + a
+	}
+	return b + " " + a
+}
+
+func enFreq(unit string) string {
+	switch strings.TrimSuffix(strings.ToLower(unit), "s") {
+	case "day":
+		return FreqDaily
+	case "week":
+		return FreqWeekly
+	case "month":
+		return FreqMonthly
+	case "year":
+		return FreqYearly
+	}
+	return ""
+}
+
+func zhFreq(unit string) string {
+	switch unit {
+	case "天", "日":
+		return FreqDaily
+	case "週", "周", "星期", "禮拜", "礼拜":
+		return FreqWeekly
+	case "月":
+		return FreqMonthly
+	case "年":
+		return FreqYearly
+	}
+	return ""
+}
+
+func enWeekdayOf(s string) time.Weekday {
+	switch strings.ToLower(s)[:3] {
+	case "mon":
+		return time.Monday
+	case "tue":
+		return time.Tuesday
+	case "wed":
+		return time.Wednesday
+	case "thu":
+		return time.Thursday
+	case "fri":
+		return time.Friday
+	case "sat":
+		return time.Saturday
+	}
+	return time.Sunday
+}
+
+func zhWeekdayOf(r rune) (time.Weekday, bool) {
+	switch r {
+	case '日', '天':
+		return time.Sunday, true
+	}
+	if n, ok := zhDigits[r]; ok && n <= 6 && r != '兩' && r != '两' {
+		return time.Weekday(n), true
+	}
+	return 0, false
+}
+
+func enMonthOf(s string) time.Month {
+	months := []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	for i, m := range months {
+		if strings.HasPrefix(strings.ToLower(s), m) {
+			return time.Month(i + 1)
+		}
+	}
+	return 0
+}
+
+// number reads a count written in ASCII or full-width digits, as an English
+// word, or in Chinese numerals up to 99. It returns 0 for anything else.
+func number(s string) int {
+	if n, ok := enNumbers[strings.ToLower(s)]; ok {
+		return n
+	}
+	digits := []rune(s)
+	ascii := make([]rune, 0, len(digits))
+	for _, r := range digits {
+		switch {
+		case r >= '0' && r <= '9':
+			ascii = append(ascii, r)
+		case r >= '０' && r <= '９':
+			ascii = append(ascii, '0'+r-'０')
+		}
+	}
+	if len(ascii) == len(digits) {
+		return atoi(string(ascii))
+	}
+	// 十 is 10, 十一 11, 二十 20, 二十一 21.
+	n, tens := 0, false
+	for _, r := range digits {
+		if r == '十' {
+			if tens {
+				return 0
+			}
+			if n == 0 {
+				n = 1
+			}
+			n *= 10
+			tens = true
+			continue
+		}
+		d, ok := zhDigits[r]
+		if !ok {
+			return 0
+		}
+		if !tens && n > 0 {
+			return 0
+		}
+		n += d
+	}
+	return n
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
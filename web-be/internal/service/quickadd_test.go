@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A Wednesday morning.
+	wednesday := time.Date(2026, 1, 14, 10, 0, 0, 0, taipei)
+	projects := []string{"Home", "工作"}
+
+	tests := []struct {
+		text       string
+		now        time.Time // wednesday when zero
+		title      string
+		due        string // "" for none
+		allDay     bool
+		recurrence string
+		project    string
+		labels     []string
+		priority   int
+	}{
+		{
+			text: "Pay rent every month on the 1st 9am #Home @bills p1", title: "Pay rent",
+			due: "2026-02-01 09:00", recurrence: "FREQ=MONTHLY;BYMONTHDAY=1;TZID=Asia/Taipei",
+			project: "Home", labels: []string{"bills"}, priority: 4,
+		},
+		{text: "Submit report tomorrow 3pm", title: "Submit report", due: "2026-01-15 15:00"},
+		{text: "Call mom next friday", title: "Call mom", due: "2026-01-23 23:59", allDay: true},
+		{text: "Dentist on jan 31", title: "Dentist", due: "2026-01-31 23:59", allDay: true},
+		{text: "Renew passport in 1 month", title: "Renew passport", due: "2026-02-14 23:59", allDay: true},
+		{
+			text: "Standup every weekday 9:30 @work", title: "Standup", due: "2026-01-15 09:30",
+			recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;TZID=Asia/Taipei", labels: []string{"work"},
+		},
+		{
+			text: "p4 Water plants every other day", title: "Water plants", due: "2026-01-14 23:59", allDay: true,
+			recurrence: "FREQ=DAILY;INTERVAL=2;TZID=Asia/Taipei", priority: 1,
+		},
+		{text: "Team lunch at noon", title: "Team lunch", due: "2026-01-14 12:00"},
+		{text: "Gym 7am", title: "Gym", due: "2026-01-15 07:00"},
+		{text: "Read book #home", title: "Read book", project: "Home"},
+		{text: "Read book #Unknown", title: "Read book #Unknown"},
+		{text: "明天下午3點開會 #工作", title: "開會", due: "2026-01-15 15:00", project: "工作"},
+		{
+			text: "每週一早上9點 週會", title: "週會", due: "2026-01-19 09:00",
+			recurrence: "FREQ=WEEKLY;BYDAY=MO;TZID=Asia/Taipei",
+		},
+		{
+			text: "每週二、四 晨跑 早上6點半", title: "晨跑", due: "2026-01-15 06:30",
+			recurrence: "FREQ=WEEKLY;BYDAY=TU,TH;TZID=Asia/Taipei",
+		},
+		{
+			text: "每個月15號繳卡費", title: "繳卡費", due: "2026-01-15 23:59", allDay: true,
+			recurrence: "FREQ=MONTHLY;BYMONTHDAY=15;TZID=Asia/Taipei",
+		},
+		{text: "下週三交報告 p2", title: "交報告", due: "2026-01-21 23:59", allDay: true, priority: 3},
+		{text: "3天後 回覆客戶", title: "回覆客戶", due: "2026-01-17 23:59", allDay: true},
+		{text: "今晚 看電影", title: "看電影", due: "2026-01-14 20:00"},
+		{text: "2月28日 報稅 @財務", title: "報稅", due: "2026-02-28 23:59", allDay: true, labels: []string{"財務"}},
+		// A month on from the 31st is the end of the shorter month.
+		{
+			text: "Invoice in 1 month", now: time.Date(2026, 1, 31, 10, 0, 0, 0, taipei),
+			title: "Invoice", due: "2026-02-28 23:59", allDay: true,
+		},
+		// The time of day holds across the change to daylight saving time.
+		{
+			text: "Brunch tomorrow 9am", now: time.Date(2026, 3, 7, 10, 0, 0, 0, newYork),
+			title: "Brunch", due: "2026-03-08 09:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			now := tt.now
+			if now.IsZero() {
+				now = wednesday
+			}
+			got, err := ParseQuickAdd(tt.text, now, projects)
+			if err != nil {
+				t.Fatalf("ParseQuickAdd: %v", err)
+			}
+			due := ""
+			if got.DueDate != nil {
+				if got.DueDate.Location().String() != now.Location().String() {
+					t.Errorf("due date in %s, want %s", got.DueDate.Location(), now.Location())
+				}
+				due = got.DueDate.Format("2006-01-02 15:04")
+			}
+			if got.Title != tt.title {
+				t.Errorf("title = %q, want %q", got.Title, tt.title)
+			}
+			if due != tt.due || got.AllDay != tt.allDay {
+				t.Errorf("due = %q (all day %v), want %q (all day %v)", due, got.AllDay, tt.due, tt.allDay)
+			}
+			if got.Recurrence != tt.recurrence {
+				t.Errorf("recurrence = %q, want %q", got.Recurrence, tt.recurrence)
+			}
+			if got.Project != tt.project {
+				t.Errorf("project = %q, want %q", got.Project, tt.project)
+			}
+			if !slices.Equal(got.Labels, tt.labels) {
+				t.Errorf("labels = %q, want %q", got.Labels, tt.labels)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("priority = %d, want %d", got.Priority, tt.priority)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddNoTitle(t *testing.T) {
+	for _, text := range []string{"", "tomorrow 9am", "明天 #Home"} {
+		if _, err := ParseQuickAdd(text, time.Now(), []string{"Home"}); !errors.Is(err, ErrInvalidQuickAdd) {
+			t.Errorf("ParseQuickAdd(%q) error = %v, want ErrInvalidQuickAdd", text, err)
+		}
+	}
+}
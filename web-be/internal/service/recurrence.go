@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// ErrInvalidRecurrence is returned for a recurrence rule ParseRecurrence
+// does not accept.
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// Recurrence frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxRecurrenceSteps bounds the occurrences skipped to get past now.
+const maxRecurrenceSteps = 1000
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Recurrence is how a task repeats: a subset of the iCalendar RRULE, e.g.
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", plus TZID naming the time zone its
+// occurrences keep their time of day in.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday // weekly only; empty repeats on the due date's weekday
+	ByMonthDay int            // monthly or yearly (in the due date's month), 1-31, clamped to short months; 0 keeps the due date's day
+	Location   *time.Location
+}
+
+// ParseRecurrence parses a rule as written by Recurrence.String. Unknown
+// parts are rejected rather than ignored.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1, Location: time.UTC}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRecurrence, part)
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			switch v := strings.ToUpper(value); v {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = v
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 366 {
+				return nil, fmt.Errorf("%w: INTERVAL %q", ErrInvalidRecurrence, value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, d := range strings.Split(strings.ToUpper(value), ",") {
+				i := slices.Index(rruleDays, d)
+				if i < 0 {
+					return nil, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrence, d)
+				}
+				if !slices.Contains(r.ByDay, time.Weekday(i)) {
+					r.ByDay = append(r.ByDay, time.Weekday(i))
+				}
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 31 {
+				return nil, fmt.Errorf("%w: BYMONTHDAY %q", ErrInvalidRecurrence, value)
+			}
+			r.ByMonthDay = n
+		case "TZID":
+			loc, err := time.LoadLocation(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: TZID %q", ErrInvalidRecurrence, value)
+			}
+			r.Location = loc
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrence, name)
+		}
+	}
+	switch {
+	case r.Freq == "":
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	case len(r.ByDay) > 0 && r.Freq != FreqWeekly:
+		return nil, fmt.Errorf("%w: BYDAY needs FREQ=WEEKLY", ErrInvalidRecurrence)
+	case r.ByMonthDay > 0 && r.Freq != FreqMonthly && r.Freq != FreqYearly:
+		return nil, fmt.Errorf("%w: BYMONTHDAY needs FREQ=MONTHLY or FREQ=YEARLY", ErrInvalidRecurrence)
+	}
+	// Monday-first, the order Next walks a week in.
+	slices.SortFunc(r.ByDay, func(a, b time.Weekday) int { return (int(a)+6)%7 - (int(b)+6)%7 })
+	return r, nil
+}
+
+// NormalizeRecurrence validates rule and returns it in canonical form; ""
+// (no recurrence) stays "".
+func NormalizeRecurrence(rule string) (string, error) {
+	if rule == "" {
+		return "", nil
+	}
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// String returns the rule in the form ParseRecurrence reads.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = rruleDays[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay > 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Location != nil && r.Location != time.UTC {
+		parts = append(parts, "TZID="+r.Location.String())
+	}
+	return strings.Join(parts, ";")
+}
+
+// First returns the first occurrence at or after t, at t's time of day.
+func (r *Recurrence) First(t time.Time) time.Time {
+	t = t.In(r.Location)
+	switch {
+	case r.Freq == FreqWeekly && len(r.ByDay) > 0 && !slices.Contains(r.ByDay, t.Weekday()):
+		return r.Next(t)
+	case r.ByMonthDay > 0 && t.Day() != clampDay(t.Year(), t.Month(), r.ByMonthDay):
+		return r.Next(t)
+	}
+	return t
+}
+
+// Next returns the occurrence after t, at t's time of day in the rule's
+// time zone.
+func (r *Recurrence) Next(t time.Time) time.Time {
+	t = t.In(r.Location)
+	y, m, d := t.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, r.Location)
+	}
+	switch r.Freq {
+	case FreqDaily:
+		return at(y, m, d+r.Interval)
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return at(y, m, d+7*r.Interval)
+		}
+		// Later days of this week, else the first day of the week Interval
+		// weeks on. Weeks start on Monday.
+		monday := d - (int(t.Weekday())+6)%7
+		for _, wd := range r.ByDay {
+			if off := (int(wd) + 6) % 7; monday+off > d {
+				return at(y, m, monday+off)
+			}
+		}
+		return at(y, m, monday+7*r.Interval+(int(r.ByDay[0])+6)%7)
+	case FreqMonthly:
+		day := r.ByMonthDay
+		if day == 0 {
+			day = d
+		} else if c := clampDay(y, m, day); c > d {
+			return at(y, m, c)
+		}
+		next := time.Date(y, m+time.Month(r.Interval), 1, 0, 0, 0, 0, r.Location)
+		return at(next.Year(), next.Month(), clampDay(next.Year(), next.Month(), day))
+	default:
+		day := r.ByMonthDay
+		if day == 0 {
+			day = d
+		} else if c := clampDay(y, m, day); c > d {
+			return at(y, m, c)
+		}
+		return at(y+r.Interval, m, clampDay(y+r.Interval, m, day))
+	}
+}
+
+// clampDay returns day, or the last day of the month when it is shorter.
+func clampDay(year int, month time.Month, day int) int {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return min(day, last)
+}
+
+// rollRecurring moves a recurring task that was just completed on to its
+// next occurrence after now, shifting the reminder along. It reports
+// whether it did; the task is then to stay open. A monthly or yearly rule
+// without BYMONTHDAY first gets the due date's day pinned, as the day of an
+// occurrence clamped to a short month must not carry over to the next.
+func rollRecurring(task *model.Task, now time.Time) (bool, error) {
+	if task.Recurrence == "" || task.DueDate == nil {
+		return false, nil
+	}
+	r, err := ParseRecurrence(task.Recurrence)
+	if err != nil {
+		return false, err
+	}
+	if (r.Freq == FreqMonthly || r.Freq == FreqYearly) && r.ByMonthDay == 0 {
+		r.ByMonthDay = task.DueDate.In(r.Location).Day()
+		task.Recurrence = r.String()
+	}
+	next := r.Next(*task.DueDate)
+	for i := 0; i < maxRecurrenceSteps && !next.After(now); i++ {
+		next = r.Next(next)
+	}
+	if task.ReminderAt != nil {
+		reminder := task.ReminderAt.Add(next.Sub(*task.DueDate))
+		task.ReminderAt = &reminder
+	}
+	task.DueDate = &next
+	return true, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	tests := []struct {
+		rule string
+		from string
+		want []string
+	}{
+		{"FREQ=DAILY", "2026-01-30 09:00", []string{"2026-01-31 09:00", "2026-02-01 09:00"}},
+		{"FREQ=DAILY;INTERVAL=3", "2026-02-27 09:00", []string{"2026-03-02 09:00", "2026-03-05 09:00"}},
+		{"FREQ=WEEKLY", "2026-01-14 09:00", []string{"2026-01-21 09:00", "2026-01-28 09:00"}},
+		// Later days of the week first, then on from Monday of the next.
+		{"FREQ=WEEKLY;BYDAY=MO,WE,FR", "2026-01-14 09:00", []string{"2026-01-16 09:00", "2026-01-19 09:00", "2026-01-21 09:00"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU", "2026-01-13 09:00", []string{"2026-01-18 09:00", "2026-01-27 09:00"}},
+		{"FREQ=MONTHLY", "2026-01-15 09:00", []string{"2026-02-15 09:00", "2026-03-15 09:00"}},
+		// A day clamped to a short month comes back once the month is long enough.
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "2026-01-31 09:00", []string{"2026-02-28 09:00", "2026-03-31 09:00", "2026-04-30 09:00", "2026-05-31 09:00"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=30", "2024-01-30 09:00", []string{"2024-02-29 09:00", "2024-03-30 09:00"}},
+		{"FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=31", "2026-01-31 09:00", []string{"2026-04-30 09:00", "2026-07-31 09:00"}},
+		// Earlier in the month than the day: later the same month.
+		{"FREQ=MONTHLY;BYMONTHDAY=20", "2026-01-14 09:00", []string{"2026-01-20 09:00", "2026-02-20 09:00"}},
+		{"FREQ=YEARLY", "2026-03-10 09:00", []string{"2027-03-10 09:00", "2028-03-10 09:00"}},
+		{"FREQ=YEARLY;BYMONTHDAY=29", "2024-02-29 09:00", []string{"2025-02-28 09:00", "2026-02-28 09:00", "2027-02-28 09:00", "2028-02-29 09:00"}},
+		// The time of day is kept across daylight saving time changes.
+		{"FREQ=DAILY;TZID=America/New_York", "2026-03-07 09:00", []string{"2026-03-08 09:00", "2026-03-09 09:00"}},
+		{"FREQ=WEEKLY;TZID=Europe/Berlin", "2026-10-22 18:30", []string{"2026-10-29 18:30"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule+" from "+tt.from, func(t *testing.T) {
+			r, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence: %v", err)
+			}
+			at, err := time.ParseInLocation("2006-01-02 15:04", tt.from, r.Location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				at = r.Next(at)
+				if got := at.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("Next = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceFirst(t *testing.T) {
+	tests := []struct {
+		rule string
+		from string
+		want string
+	}{
+		{"FREQ=WEEKLY;BYDAY=MO", "2026-01-14 09:00", "2026-01-19 09:00"},
+		{"FREQ=WEEKLY;BYDAY=WE", "2026-01-14 09:00", "2026-01-14 09:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1", "2026-01-14 09:00", "2026-02-01 09:00"},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "2026-02-28 09:00", "2026-02-28 09:00"},
+		{"FREQ=YEARLY;BYMONTHDAY=29", "2025-02-10 09:00", "2025-02-28 09:00"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", tt.rule, err)
+		}
+		from, _ := time.ParseInLocation("2006-01-02 15:04", tt.from, r.Location)
+		if got := r.First(from).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("%s: First(%s) = %s, want %s", tt.rule, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestRollRecurringKeepsMonthEnd(t *testing.T) {
+	tests := []struct {
+		rule string
+		due  time.Time
+		want []string
+	}{
+		{"FREQ=MONTHLY", time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC), []string{"2026-02-28", "2026-03-31", "2026-04-30"}},
+		{"FREQ=YEARLY", time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC), []string{"2025-02-28", "2026-02-28", "2027-02-28", "2028-02-29"}},
+	}
+	for _, tt := range tests {
+		due := tt.due
+		task := &model.Task{Recurrence: tt.rule, DueDate: &due}
+		for _, want := range tt.want {
+			rolled, err := rollRecurring(task, *task.DueDate)
+			if err != nil || !rolled {
+				t.Fatalf("%s: rollRecurring = %v, %v", tt.rule, rolled, err)
+			}
+			if got := task.DueDate.Format("2006-01-02"); got != want {
+				t.Fatalf("%s: rolled to %s, want %s", tt.rule, got, want)
+			}
+		}
+	}
+}
+
+func TestParseRecurrenceRejects(t *testing.T) {
+	for _, rule := range []string{
+		"", "FREQ=HOURLY", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=3", "FREQ=MONTHLY;BYMONTHDAY=32", "FREQ=DAILY;COUNT=3", "FREQ=DAILY;TZID=Mars/Base",
+	} {
+		if _, err := ParseRecurrence(rule); err == nil {
+			t.Errorf("ParseRecurrence(%q) accepted", rule)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/notify"
+)
+
+var (
+	// ErrReminderNotFound is returned when a reminder does not exist on the task.
+	ErrReminderNotFound = errors.New("reminder not found")
+	// ErrReminderFromTask is returned when deleting the reminder that mirrors
+	// the task's reminder_at; it is removed by clearing reminder_at instead.
+	ErrReminderFromTask = errors.New("the task's own reminder is removed by clearing its reminder_at")
+)
+
+// Reminder retry policy: a failed delivery is retried after 1, 2, 4, ...
+// minutes until maxReminderAttempts is reached.
+const (
+	maxReminderAttempts = 5
+	reminderRetryBase   = time.Minute
+	reminderSendTimeout = 30 * time.Second
+)
+
+// SyncTaskReminder keeps the reminder mirroring Task.ReminderAt in step with
+// after. before is the task prior to the change, nil on create; nothing happens
+// unless reminder_at changed. It must run in the transaction saving the task.
+func SyncTaskReminder(tx *gorm.DB, before, after *model.Task) error {
+	if before != nil && sameTime(before.ReminderAt, after.ReminderAt) {
+		return nil
+	}
+	var r model.Reminder
+	err := tx.Where("task_id = ? AND from_task", after.ID).First(&r).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	found := err == nil
+	if after.ReminderAt == nil {
+		if !found || r.Status != model.ReminderPending {
+			return nil
+		}
+		return tx.Model(&r).Update("status", model.ReminderCancelled).Error
+	}
+	if !found {
+		r = model.Reminder{ID: uuid.New().String(), TaskID: after.ID, UserID: after.UserID, FromTask: true}
+	}
+	rearm(&r, *after.ReminderAt)
+	r.SnoozeCount = 0
+	return tx.Save(&r).Error
+}
+
+// AddReminder schedules an extra reminder on task for userID at remindAt.
+func AddReminder(db *gorm.DB, userID string, task *model.Task, remindAt time.Time) (*model.Reminder, error) {
+	r := &model.Reminder{
+		ID:       uuid.New().String(),
+		TaskID:   task.ID,
+		UserID:   userID,
+		RemindAt: remindAt,
+		Status:   model.ReminderPending,
+	}
+	if err := db.Create(r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SnoozeReminder re-arms r to fire at until, whatever its current status.
+func SnoozeReminder(db *gorm.DB, r *model.Reminder, until time.Time) error {
+	rearm(r, until)
+	r.SnoozeCount++
+	return db.Omit(clause.Associations).Save(r).Error
+}
+
+// DeleteReminder removes r. The reminder mirroring the task's reminder_at
+// cannot be deleted on its own.
+func DeleteReminder(db *gorm.DB, r *model.Reminder) error {
+	if r.FromTask {
+		return ErrReminderFromTask
+	}
+	return db.Delete(r).Error
+}
+
+// FindReminder loads a reminder of userID on taskID.
+func FindReminder(db *gorm.DB, taskID, userID, id string) (*model.Reminder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrReminderNotFound
+	}
+	var r model.Reminder
+	if err := db.Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at, id")
+	}).Where("id = ? AND task_id = ? AND user_id = ?", id, taskID, userID).First(&r).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReminderNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+func rearm(r *model.Reminder, at time.Time) {
+	r.RemindAt = at
+	r.Status = model.ReminderPending
+	r.Attempts = 0
+	r.NextAttemptAt = nil
+	r.LastError = ""
+	r.SentAt = nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// ReminderDispatcher delivers due reminders through a set of notifiers.
+type ReminderDispatcher struct {
+	notifiers []notify.Notifier
+}
+
+// NewReminderDispatcher returns a dispatcher sending over notifiers.
+func NewReminderDispatcher(notifiers ...notify.Notifier) *ReminderDispatcher {
+	return &ReminderDispatcher{notifiers: notifiers}
+}
+
+// DispatchDue delivers up to limit due reminders and returns how many it
+// handled. Each is claimed with FOR UPDATE SKIP LOCKED in a short
+// transaction that pushes its next attempt back past the time its sends can
+// take, so several instances can dispatch concurrently; it is then sent with
+// no transaction open and the outcome recorded in another.
+func (d *ReminderDispatcher) DispatchDue(ctx context.Context, db *gorm.DB, limit int) (int, error) {
+	handled := 0
+	for handled < limit {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+		r, send, err := d.claim(db.WithContext(ctx))
+		if err != nil {
+			return handled, err
+		}
+		if r == nil {
+			break
+		}
+		if send != nil {
+			if err := d.deliver(ctx, db, r, send); err != nil {
+				return handled, err
+			}
+		}
+		handled++
+	}
+	return handled, nil
+}
+
+// reminderSend is what a claimed reminder is to be sent with.
+type reminderSend struct {
+	msg  notify.Message
+	pref model.NotificationPreference
+	// done are the channels that already delivered it.
+	done []string
+}
+
+// claim claims the next due reminder, counting the attempt, adds it to the
+// user's inbox on its first attempt and returns it with what to send. A
+// reminder whose task is gone, or in a completed or cancelled category
+// status, is cancelled instead, and one
+// whose last attempt was claimed but never recorded is marked failed; both
+// are returned without a send.
+func (d *ReminderDispatcher) claim(db *gorm.DB) (*model.Reminder, *reminderSend, error) {
+	var r model.Reminder
+	var send *reminderSend
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND remind_at <= ?", model.ReminderPending, now).
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			Order("remind_at").Limit(1).Find(&r).Error
+		if err != nil || r.ID == "" {
+			return err
+		}
+		var task model.Task
+		err = tx.Unscoped().Where("id = ?", r.TaskID).First(&task).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		var category string
+		if err == nil {
+			set, err := LoadStatusSet(tx, task.ProjectID)
+			if err != nil {
+				return err
+			}
+			category = set[task.Status]
+		}
+		if err != nil || task.DeletedAt.Valid || task.CompletedAt != nil ||
+			category == model.TaskStatusCompleted || category == model.TaskStatusCancelled {
+			r.Status = model.ReminderCancelled
+			return tx.Model(&r).Select("status").Updates(&r).Error
+		}
+		if r.Attempts >= maxReminderAttempts {
+			r.Status = model.ReminderFailed
+			r.LastError = "the outcome of the last attempt was not recorded"
+			log.Printf("reminders: reminder %s failed after %d attempts: %s", r.ID, r.Attempts, r.LastError)
+			return tx.Model(&r).Select("status", "last_error").Updates(&r).Error
+		}
+
+		var user model.User
+		if err := tx.Select("id", "email").Where("id = ?", r.UserID).First(&user).Error; err != nil &&
+			!errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		send = &reminderSend{msg: reminderMessage(&r, &task, user.Email)}
+		if send.pref, err = NotificationPreference(tx, r.UserID, model.NotificationReminder); err != nil {
+			return err
+		}
+		if r.Attempts == 0 && send.pref.InApp {
+			if err := tx.Create(&model.Notification{
+				ID:        uuid.New().String(),
+				UserID:    r.UserID,
+				Type:      model.NotificationReminder,
+				Title:     truncate(send.msg.Title, 500),
+				Body:      send.msg.Body,
+				TaskID:    &task.ID,
+				ProjectID: optional(task.ProjectID),
+				InApp:     true,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&model.ReminderDelivery{
+				ID: uuid.New().String(), ReminderID: r.ID, Channel: notify.ChannelInApp, Status: "sent",
+			}).Error; err != nil {
+				return err
+			}
+		}
+		// On a retry, skip channels that already delivered, including the inbox.
+		if err := tx.Model(&model.ReminderDelivery{}).Where("reminder_id = ? AND status = ?", r.ID, "sent").
+			Pluck("channel", &send.done).Error; err != nil {
+			return err
+		}
+
+		// Sends run one after another, each for up to reminderSendTimeout.
+		lease := now.Add(time.Duration(len(d.notifiers)+1) * reminderSendTimeout)
+		r.Attempts++
+		r.NextAttemptAt = &lease
+		return tx.Model(&r).Select("attempts", "next_attempt_at").Updates(&r).Error
+	})
+	if err != nil || r.ID == "" {
+		return nil, nil, err
+	}
+	return &r, send, nil
+}
+
+// deliver sends r, claimed, on every channel it has not yet reached and the
+// user's reminder preference allows, and records the outcome. The outcome
+// is dropped when the reminder was re-armed or cancelled meanwhile.
+func (d *ReminderDispatcher) deliver(ctx context.Context, db *gorm.DB, r *model.Reminder, send *reminderSend) error {
+	delivered := len(send.done) > 0
+	var deliveries []model.ReminderDelivery
+	var errs []error
+	for _, n := range d.notifiers {
+		if slices.Contains(send.done, n.Channel()) {
+			continue
+		}
+		if (n.Channel() == notify.ChannelEmail && !send.pref.Email) || (n.Channel() == notify.ChannelPush && !send.pref.Push) {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, reminderSendTimeout)
+		err := n.Notify(sendCtx, send.msg)
+		cancel()
+		delivery := model.ReminderDelivery{ID: uuid.New().String(), ReminderID: r.ID, Channel: n.Channel(), Status: "sent"}
+		switch {
+		case errors.Is(err, notify.ErrNoRecipient):
+			delivery.Status = "skipped"
+		case err != nil:
+			delivery.Status = "failed"
+			delivery.Error = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", n.Channel(), err))
+		default:
+			delivered = true
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	claimed := *r
+	now := time.Now()
+	switch {
+	case len(errs) == 0 && (delivered || len(d.notifiers) == 0):
+		r.Status = model.ReminderSent
+		r.SentAt = &now
+		r.NextAttemptAt = nil
+		r.LastError = ""
+	case len(errs) == 0:
+		r.Status = model.ReminderFailed
+		r.NextAttemptAt = nil
+		r.LastError = "no channel could reach the user"
+	case r.Attempts >= maxReminderAttempts:
+		r.Status = model.ReminderFailed
+		r.NextAttemptAt = nil
+		r.LastError = errors.Join(errs...).Error()
+	default:
+		next := now.Add(reminderRetryBase << (r.Attempts - 1))
+		r.NextAttemptAt = &next
+		r.LastError = errors.Join(errs...).Error()
+	}
+
+	// Recorded even when ctx was cancelled meanwhile: the sends went out.
+	return db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		if len(deliveries) > 0 {
+			if err := tx.Create(&deliveries).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Model(r).Where("status = ? AND attempts = ? AND remind_at = ?", model.ReminderPending, claimed.Attempts, claimed.RemindAt).
+			Select("status", "sent_at", "next_attempt_at", "last_error").Updates(r)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 && r.Status == model.ReminderFailed {
+			log.Printf("reminders: reminder %s failed after %d attempts: %s", r.ID, r.Attempts, r.LastError)
+		}
+		return nil
+	})
+}
+
+func reminderMessage(r *model.Reminder, task *model.Task, email string) notify.Message {
+	body := task.Title
+	if task.DueDate != nil {
+		body += "\nDue " + task.DueDate.UTC().Format("2006-01-02 15:04 MST")
+	}
+	if task.Description != "" {
+		body += "\n\n" + task.Description
+	}
+	return notify.Message{
+		UserID: r.UserID,
+		Email:  email,
+		Title:  "Reminder: " + task.Title,
+		Body:   body,
+		Data:   map[string]string{"type": "reminder", "task_id": task.ID, "reminder_id": r.ID},
+	}
+}
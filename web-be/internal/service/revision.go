@@ -0,0 +1,132 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// ErrRevisionNotFound is returned when a task has no revision with the given number.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// TaskRevisions snapshots tasks before updates and prunes old snapshots.
+type TaskRevisions struct {
+	freeKeep    int
+	premiumKeep int
+}
+
+// NewTaskRevisions keeps the latest freeKeep revisions of tasks owned by free
+// users and premiumKeep for premium users.
+func NewTaskRevisions(freeKeep, premiumKeep int) *TaskRevisions {
+	return &TaskRevisions{freeKeep: freeKeep, premiumKeep: premiumKeep}
+}
+
+// Record stores before, the task as it was prior to an update by actorID, as
+// the task's next revision and drops revisions beyond the owner's retention.
+// It must run in the transaction that applies the update.
+func (r *TaskRevisions) Record(tx *gorm.DB, actorID string, before *model.Task) error {
+	// Lock the task row so concurrent updates get consecutive revision numbers.
+	var locked model.Task
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		Where("id = ?", before.ID).First(&locked).Error; err != nil {
+		return err
+	}
+	var last int
+	if err := tx.Model(&model.TaskRevision{}).Where("task_id = ?", before.ID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&last).Error; err != nil {
+		return err
+	}
+	// The update has not touched the labels yet.
+	labelIDs, err := TaskLabelIDs(tx, before.ID)
+	if err != nil {
+		return err
+	}
+	labels, _ := json.Marshal(labelIDs)
+	rev := model.TaskRevision{
+		ID:          uuid.New().String(),
+		TaskID:      before.ID,
+		Revision:    last + 1,
+		ActorID:     actorID,
+		Title:       before.Title,
+		Description: before.Description,
+		ProjectID:   before.ProjectID,
+		SectionID:   before.SectionID,
+		Priority:    before.Priority,
+		Status:      before.Status,
+		Progress:    before.Progress,
+		DueDate:     before.DueDate,
+		ReminderAt:  before.ReminderAt,
+		Version:     before.Version,
+		LabelIDs:    string(labels),
+	}
+	if err := tx.Create(&rev).Error; err != nil {
+		return err
+	}
+	keep, err := r.retention(tx, before.UserID)
+	if err != nil {
+		return err
+	}
+	return tx.Where("task_id = ? AND revision <= ?", before.ID, rev.Revision-keep).
+		Delete(&model.TaskRevision{}).Error
+}
+
+// retention is how many revisions the tasks of ownerID keep.
+func (r *TaskRevisions) retention(db *gorm.DB, ownerID string) (int, error) {
+	var owner model.User
+	err := db.Select("id", "is_premium", "premium_expires_at").Where("id = ?", ownerID).First(&owner).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	if owner.HasPremium(time.Now()) {
+		return r.premiumKeep, nil
+	}
+	return r.freeKeep, nil
+}
+
+// FindRevision loads revision rev of taskID.
+func FindRevision(db *gorm.DB, taskID, rev string) (*model.TaskRevision, error) {
+	n, err := strconv.Atoi(rev)
+	if err != nil {
+		return nil, ErrRevisionNotFound
+	}
+	var r model.TaskRevision
+	if err := db.Where("task_id = ? AND revision = ?", taskID, n).First(&r).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Restore rolls the content of task back to rev: title, description,
+// priority, progress, due date and reminder. Status and placement are left
+// alone since they are governed by the status machine and project rules. The
+// current version is recorded as a new revision first, so a restore can
+// itself be undone.
+func (r *TaskRevisions) Restore(tx *gorm.DB, actorID string, task *model.Task, rev *model.TaskRevision) error {
+	before := *task
+	if err := r.Record(tx, actorID, &before); err != nil {
+		return err
+	}
+	task.Title = rev.Title
+	task.Description = rev.Description
+	task.Priority = rev.Priority
+	task.Progress = rev.Progress
+	task.DueDate = rev.DueDate
+	task.ReminderAt = rev.ReminderAt
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+	if err := SyncTaskReminder(tx, &before, task); err != nil {
+		return err
+	}
+	return RecordUpdate(tx, actorID, &before, task)
+}
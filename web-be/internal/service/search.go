@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of search hits.
+const (
+	SearchTask    = "task"
+	SearchSubtask = "subtask"
+	SearchComment = "comment"
+)
+
+// ErrInvalidSearch is returned for a search without any text to look for or
+// with malformed filters.
+var ErrInvalidSearch = errors.New("invalid search")
+
+const (
+	// maxSearchWords caps the words of a query turned into a tsquery.
+	maxSearchWords = 10
+	// snippetRadius is how many characters of context a trigram snippet
+	// keeps on each side of the match.
+	snippetRadius = 40
+	// Highlight markers around matched text in snippets.
+	snippetStart = "<mark>"
+	snippetStop  = "</mark>"
+	// Placeholders ts_headline marks matches with, swapped for the markers
+	// once the snippet is escaped. They are private-use characters, stripped
+	// from the text beforehand.
+	headlineStart = "\uE000"
+	headlineStop  = "\uE001"
+)
+
+// SearchQuery is a search of a user's tasks, subtasks and comments. Kinds
+// restricts the kinds of hits (all when empty); ProjectID, LabelID and Status
+// restrict them to tasks, and subtasks and comments of tasks, matching all
+// that are set.
+type SearchQuery struct {
+	Text      string
+	Kinds     []string
+	ProjectID string
+	LabelID   string
+	Status    string
+	Limit     int
+	Offset    int
+}
+
+// SearchHit is a task, subtask or comment matching a search. Title is the
+// task's or subtask's title, or for a comment that of its task. Snippet is
+// the matching text, HTML-escaped, with matches between <mark> and </mark>.
+type SearchHit struct {
+	Kind      string
+	ID        string
+	TaskID    *string
+	ProjectID string
+	Title     string
+	Snippet   string
+	Rank      float64
+	UpdatedAt time.Time
+}
+
+// searchRow is a row of the search query.
+type searchRow struct {
+	SearchHit
+	Body  string
+	Total int64
+}
+
+// Search finds what userID can see matching q.Text, best matches first, and
+// returns a page of them with the total count. Words are matched by prefix
+// against the full-text index, stemmed English or as written. Queries with
+// CJK text, which the full-text parser cannot split into words, and queries
+// finding nothing that way are matched as substrings, or by trigram
+// similarity to catch typos, instead.
+func Search(db *gorm.DB, userID string, q SearchQuery) ([]SearchHit, int64, error) {
+	text := strings.TrimSpace(q.Text)
+	if text == "" {
+		return nil, 0, fmt.Errorf("%w: q is required", ErrInvalidSearch)
+	}
+	for _, k := range q.Kinds {
+		if k != SearchTask && k != SearchSubtask && k != SearchComment {
+			return nil, 0, fmt.Errorf("%w: unknown type %q", ErrInvalidSearch, k)
+		}
+	}
+	for name, id := range map[string]string{"project_id": q.ProjectID, "label_id": q.LabelID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return nil, 0, fmt.Errorf("%w: %s must be a UUID", ErrInvalidSearch, name)
+		}
+	}
+	args := map[string]any{
+		"user":    userID,
+		"q":       text,
+		"pattern": "%" + escapeLike(text) + "%",
+		"project": q.ProjectID,
+		"label":   q.LabelID,
+		"status":  q.Status,
+		"limit":   q.Limit,
+		"offset":  q.Offset,
+	}
+	if tsq := prefixTSQuery(text); tsq != "" && !hasCJK(text) {
+		args["tsq"] = tsq
+		hits, total, err := runSearch(db, q, args, false)
+		if err != nil || total > 0 || q.Offset > 0 {
+			return hits, total, err
+		}
+	}
+	return runSearch(db, q, args, true)
+}
+
+func runSearch(db *gorm.DB, q SearchQuery, args map[string]any, trigram bool) ([]SearchHit, int64, error) {
+	var rows []searchRow
+	if err := db.Raw(searchSQL(q, trigram), args).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	hits := make([]SearchHit, len(rows))
+	var total int64
+	for i, r := range rows {
+		hits[i] = r.SearchHit
+		if trigram {
+			hits[i].Snippet = highlight(r.Body, q.Text)
+		} else {
+			hits[i].Snippet = markHeadline(r.Snippet)
+		}
+		total = r.Total
+	}
+	return hits, total, nil
+}
+
+// searchSQL builds the search query: one branch per kind of hit, ranked
+// together and paged, with snippets made for the page only.
+func searchSQL(q SearchQuery, trigram bool) string {
+	tsquery := "(to_tsquery('simple', @tsq) || to_tsquery('english', @tsq))"
+	match := func(vector string, texts ...string) string {
+		if !trigram {
+			return vector + " @@ " + tsquery
+		}
+		conds := make([]string, 0, len(texts)+1)
+		for _, t := range texts {
+			conds = append(conds, "coalesce("+t+", '') ILIKE @pattern")
+		}
+		conds = append(conds, texts[0]+" % @q")
+		return "(" + strings.Join(conds, " OR ") + ")"
+	}
+	rank := func(vector string, texts ...string) string {
+		if !trigram {
+			return "ts_rank_cd(" + vector + ", " + tsquery + ")"
+		}
+		sims := make([]string, len(texts))
+		for i, t := range texts {
+			sims[i] = "word_similarity(@q, coalesce(" + t + ", ''))"
+		}
+		return "GREATEST(" + strings.Join(sims, ", ") + ")"
+	}
+	visibleProject := func(col string) string {
+		return col + " IN (SELECT id FROM projects WHERE user_id = @user AND deleted_at IS NULL " +
+			"UNION SELECT project_id FROM project_members WHERE user_id = @user)"
+	}
+	visibleTask := "(t.user_id = @user OR " + visibleProject("t.project_id") + ")"
+	var filters []string
+	if q.ProjectID != "" {
+		filters = append(filters, "t.project_id = @project")
+	}
+	if q.LabelID != "" {
+		filters = append(filters, "t.id IN (SELECT task_id FROM task_labels WHERE label_id = @label)")
+	}
+	if q.Status != "" {
+		filters = append(filters, "t.status = @status")
+	}
+	taskFilters := ""
+	for _, f := range filters {
+		taskFilters += " AND " + f
+	}
+
+	var branches []string
+	if wantKind(q.Kinds, SearchTask) {
+		branches = append(branches, `SELECT 'task' AS kind, t.id, t.id AS task_id, t.project_id, t.title,
+			concat_ws(' ', t.title, t.description) AS body, `+rank("t.search_vector", "t.title", "t.description")+` AS rank, t.updated_at
+			FROM tasks t
+			WHERE t.deleted_at IS NULL AND `+visibleTask+taskFilters+` AND `+match("t.search_vector", "t.title", "t.description"))
+	}
+	if wantKind(q.Kinds, SearchSubtask) {
+		branches = append(branches, `SELECT 'subtask' AS kind, s.id, t.id AS task_id, t.project_id, s.title,
+			s.title AS body, `+rank("s.search_vector", "s.title")+` AS rank, s.updated_at
+			FROM subtasks s JOIN tasks t ON t.id = s.task_id AND t.deleted_at IS NULL
+			WHERE s.deleted_at IS NULL AND `+visibleTask+taskFilters+` AND `+match("s.search_vector", "s.title"))
+	}
+	if wantKind(q.Kinds, SearchComment) {
+		// Comments on a project rather than a task have no t; they only pass
+		// the project filter.
+		commentFilters := taskFilters
+		if q.ProjectID != "" && q.LabelID == "" && q.Status == "" {
+			commentFilters = " AND coalesce(t.project_id, c.project_id) = @project"
+		}
+		branches = append(branches, `SELECT 'comment' AS kind, c.id, c.task_id, coalesce(t.project_id, c.project_id) AS project_id,
+			coalesce(t.title, '') AS title, c.body, `+rank("c.search_vector", "c.body")+` AS rank, c.updated_at
+			FROM comments c LEFT JOIN tasks t ON t.id = c.task_id AND t.deleted_at IS NULL
+			WHERE c.deleted_at IS NULL
+			AND (CASE WHEN c.task_id IS NULL THEN `+visibleProject("c.project_id")+` ELSE t.id IS NOT NULL AND `+visibleTask+` END)`+
+			commentFilters+` AND `+match("c.search_vector", "c.body"))
+	}
+
+	snippet := "''"
+	if !trigram {
+		snippet = "ts_headline('simple', translate(page.body, chr(57344) || chr(57345), ''), " + tsquery +
+			", 'StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxFragments=2, MaxWords=20, MinWords=5')"
+	}
+	return `SELECT page.*, ` + snippet + ` AS snippet FROM (
+		SELECT hits.*, COUNT(*) OVER () AS total FROM (` + strings.Join(branches, "\nUNION ALL\n") + `) hits
+		ORDER BY rank DESC, updated_at DESC, id
+		LIMIT @limit OFFSET @offset
+	) page
+	ORDER BY rank DESC, updated_at DESC, id`
+}
+
+// wantKind reports whether kind is among kinds, or kinds is empty.
+func wantKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixTSQuery turns the words of text into a tsquery matching all of them
+// by prefix, e.g. "Buy milk" into "buy:* & milk:*". It returns "" when text
+// has no words.
+func prefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchWords {
+		words = words[:maxSearchWords]
+	}
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// hasCJK reports whether text has Chinese, Japanese or Korean characters.
+func hasCJK(text string) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// markHeadline HTML-escapes a ts_headline snippet and turns its placeholders
+// into highlight markers.
+func markHeadline(snippet string) string {
+	return strings.NewReplacer(headlineStart, snippetStart, headlineStop, snippetStop).Replace(html.EscapeString(snippet))
+}
+
+// highlight returns the part of body around the first case-insensitive
+// occurrence of q, HTML-escaped with the occurrence marked, or the start of
+// body when it has none (a trigram match).
+func highlight(body, q string) string {
+	text, needle := []rune(body), []rune(strings.TrimSpace(q))
+	at := -1
+	for i := 0; len(needle) > 0 && i+len(needle) <= len(text); i++ {
+		if foldEqual(text[i:i+len(needle)], needle) {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		if len(text) > 2*snippetRadius {
+			return html.EscapeString(string(text[:2*snippetRadius])) + "…"
+		}
+		return html.EscapeString(body)
+	}
+	from, to := max(at-snippetRadius, 0), min(at+len(needle)+snippetRadius, len(text))
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(html.EscapeString(string(text[from:at])))
+	b.WriteString(snippetStart)
+	b.WriteString(html.EscapeString(string(text[at : at+len(needle)])))
+	b.WriteString(snippetStop)
+	b.WriteString(html.EscapeString(string(text[at+len(needle) : to])))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func foldEqual(a, b []rune) bool {
+	for i := range a {
+		if unicode.ToLower(a[i]) != unicode.ToLower(b[i]) {
+			return false
+		}
+	}
+	return true
+}
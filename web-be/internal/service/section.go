@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+// ErrSectionNotFound is returned when a section does not exist in the given project.
+var ErrSectionNotFound = errors.New("section not found")
+
+// ResolveSection validates that sectionID belongs to projectID and returns
+// the value to store in Task.SectionID (nil for an empty sectionID).
+func ResolveSection(db *gorm.DB, projectID, sectionID string) (*string, error) {
+	if sectionID == "" {
+		return nil, nil
+	}
+	if _, err := uuid.Parse(sectionID); err != nil {
+		return nil, ErrSectionNotFound
+	}
+	var count int64
+	if err := db.Model(&model.Section{}).
+		Where("id = ? AND project_id = ?", sectionID, projectID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSectionNotFound
+	}
+	return &sectionID, nil
+}
+
+// TaskSortKey returns a sort key placing a task among the other tasks of
+// (projectID, sectionID). taskID, when set, is excluded from the siblings.
+func TaskSortKey(db *gorm.DB, taskID, projectID string, sectionID *string, afterID, beforeID string) (string, error) {
+	return positionBetween(func() *gorm.DB {
+		q := db.Model(&model.Task{}).Where("project_id = ?", projectID)
+		if sectionID == nil {
+			q = q.Where("section_id IS NULL")
+		} else {
+			q = q.Where("section_id = ?", *sectionID)
+		}
+		if taskID != "" {
+			q = q.Where("id <> ?", taskID)
+		}
+		return q
+	}, afterID, beforeID)
+}
+
+// SectionSortKey returns a sort key placing a section among the other
+// sections of projectID. sectionID, when set, is excluded from the siblings.
+func SectionSortKey(db *gorm.DB, sectionID, projectID, afterID, beforeID string) (string, error) {
+	return positionBetween(func() *gorm.DB {
+		q := db.Model(&model.Section{}).Where("project_id = ?", projectID)
+		if sectionID != "" {
+			q = q.Where("id <> ?", sectionID)
+		}
+		return q
+	}, afterID, beforeID)
+}
+
+// ReorderTask places task in sectionID (nil = no section) of its current
+// project, between the tasks afterID and beforeID. Only the task row is written.
+func ReorderTask(db *gorm.DB, task *model.Task, sectionID *string, afterID, beforeID string) error {
+	key, err := TaskSortKey(db, task.ID, task.ProjectID, sectionID, afterID, beforeID)
+	if err != nil {
+		return err
+	}
+	task.SectionID = sectionID
+	task.SortKey = key
+	return db.Model(task).Select("section_id", "sort_key").Updates(task).Error
+}
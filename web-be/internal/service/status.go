@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrInvalidStatus is returned for a status that is neither a model.TaskStatus*
+	// constant nor a custom status of the task's project.
+	ErrInvalidStatus = errors.New("invalid task status")
+	// ErrStatusTransition is returned when the configured transitions do not allow a status change.
+	ErrStatusTransition = errors.New("status transition not allowed")
+)
+
+// DefaultStatusTransitions lists, for each status, the statuses a task may move to.
+var DefaultStatusTransitions = map[string][]string{
+	model.TaskStatusPending:    {model.TaskStatusInProgress, model.TaskStatusCompleted, model.TaskStatusCancelled},
+	model.TaskStatusInProgress: {model.TaskStatusPending, model.TaskStatusCompleted, model.TaskStatusCancelled},
+	model.TaskStatusCompleted:  {model.TaskStatusPending, model.TaskStatusInProgress},
+	model.TaskStatusCancelled:  {model.TaskStatusPending},
+}
+
+// TaskStatusMachine validates task status changes and maintains the
+// started_at/completed_at timestamps that go with them. Transitions are
+// defined between the built-in statuses, which double as the categories of
+// per-project custom statuses.
+type TaskStatusMachine struct {
+	transitions map[string]map[string]bool
+}
+
+// NewTaskStatusMachine builds a machine from a transition table. A nil or
+// empty table selects DefaultStatusTransitions.
+func NewTaskStatusMachine(transitions map[string][]string) (*TaskStatusMachine, error) {
+	if len(transitions) == 0 {
+		transitions = DefaultStatusTransitions
+	}
+	m := &TaskStatusMachine{transitions: make(map[string]map[string]bool)}
+	for from, tos := range transitions {
+		if !isTaskStatus(from) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, from)
+		}
+		m.transitions[from] = make(map[string]bool, len(tos))
+		for _, to := range tos {
+			if !isTaskStatus(to) {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+			}
+			m.transitions[from][to] = true
+		}
+	}
+	return m, nil
+}
+
+// Transition moves task to status `to`, applying timestamp and progress side
+// effects. statuses maps the keys valid in the task's project to their
+// built-in category (nil means built-ins only); transitions are checked
+// between categories, so moving between two custom columns of the same
+// category is always allowed. Setting the current status again only
+// re-applies the invariant that completed tasks are at 100% progress.
+func (m *TaskStatusMachine) Transition(task *model.Task, to string, statuses StatusSet, now time.Time) error {
+	if statuses == nil {
+		statuses = BuiltinStatuses()
+	}
+	toCat, ok := statuses[to]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	fromCat, ok := statuses[task.Status]
+	if !ok {
+		// Rows written before statuses were validated, or whose custom
+		// status was removed, are treated as pending.
+		fromCat = model.TaskStatusPending
+	}
+	if task.Status == to || fromCat == toCat {
+		task.Status = to
+		if toCat == model.TaskStatusCompleted {
+			task.Progress = 100
+		}
+		return nil
+	}
+	if !m.transitions[fromCat][toCat] {
+		return fmt.Errorf("%w: %s -> %s", ErrStatusTransition, task.Status, to)
+	}
+
+	task.Status = to
+	switch toCat {
+	case model.TaskStatusInProgress:
+		if task.StartedAt == nil {
+			task.StartedAt = &now
+		}
+		task.CompletedAt = nil
+	case model.TaskStatusCompleted:
+		task.CompletedAt = &now
+		task.Progress = 100
+	default:
+		task.CompletedAt = nil
+	}
+	return nil
+}
+
+// Apply loads the statuses of the task's project and runs Transition. When
+// the task enters an in_progress-category column its WIP limit is enforced;
+// when it enters a completed-category column it must have no open blockers
+// unless force is set. Completing a recurring task instead moves its due
+// date to the next occurrence and leaves it open.
+func (m *TaskStatusMachine) Apply(db *gorm.DB, task *model.Task, to string, force bool, now time.Time) error {
+	statuses, err := LoadStatusSet(db, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	before := *task
+	if err := m.Transition(task, to, statuses, now); err != nil {
+		return err
+	}
+	if before.Status == to {
+		return nil
+	}
+	switch statuses[to] {
+	case model.TaskStatusInProgress:
+		return checkWIPLimit(db, task, to)
+	case model.TaskStatusCompleted:
+		if !force {
+			if err := checkBlockers(db, task); err != nil {
+				return err
+			}
+		}
+		if statuses[before.Status] == model.TaskStatusCompleted {
+			return nil
+		}
+		rolled, err := rollRecurring(task, now)
+		if err != nil {
+			return err
+		}
+		if rolled {
+			task.Status, task.Progress, task.StartedAt, task.CompletedAt =
+				before.Status, before.Progress, before.StartedAt, before.CompletedAt
+		}
+	}
+	return nil
+}
+
+func isTaskStatus(s string) bool {
+	switch s {
+	case model.TaskStatusPending, model.TaskStatusInProgress, model.TaskStatusCompleted, model.TaskStatusCancelled:
+		return true
+	}
+	return false
+}
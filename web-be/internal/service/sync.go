@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrInvalidSyncToken is returned for a sync token the server did not issue.
+	ErrInvalidSyncToken = errors.New("invalid sync token")
+	// ErrInvalidMutation is returned for a sync mutation with an unknown
+	// operation or entity, a malformed ID or invalid data.
+	ErrInvalidMutation = errors.New("invalid mutation")
+	// ErrIDConflict is returned when a client-generated ID is already taken
+	// by a row the caller does not own.
+	ErrIDConflict = errors.New("id already in use")
+	// ErrSubtaskNotFound is returned when a subtask does not exist or belongs to someone else.
+	ErrSubtaskNotFound = errors.New("subtask not found")
+)
+
+// SyncChanges is one page of rows changed since a sync token, soft-deleted
+// rows included as tombstones.
+type SyncChanges struct {
+	Tasks    []model.Task
+	Projects []model.Project
+	Labels   []model.Label
+	Subtasks []model.Subtask
+	// Token is the position to pass as since next time.
+	Token int64
+	// HasMore reports that the page was full; fetch again with Token.
+	HasMore bool
+}
+
+// ParseSyncToken parses a token issued by FormatSyncToken.
+func ParseSyncToken(s string) (int64, error) {
+	seq, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, ErrInvalidSyncToken
+	}
+	return seq, nil
+}
+
+// FormatSyncToken returns the opaque token for a sync position.
+func FormatSyncToken(seq int64) string {
+	return strconv.FormatInt(seq, 10)
+}
+
+// ChangesSince returns up to limit tasks, projects, labels and subtasks of
+// userID written after since, in write order. A full sync starts from
+// scratch and leaves out deleted rows; an incremental one includes them as
+// tombstones.
+func ChangesSince(db *gorm.DB, userID string, since int64, full bool, limit int) (*SyncChanges, error) {
+	// Read before the rows, so every row up to it is visible to them.
+	settled, _, err := settledSeqs(db)
+	if err != nil {
+		return nil, err
+	}
+	page := func() *gorm.DB {
+		q := db.Unscoped().Where("sync_seq > ?", since).Order("sync_seq").Limit(limit + 1)
+		if full {
+			q = q.Where("deleted_at IS NULL")
+		}
+		return q
+	}
+	out := &SyncChanges{Token: since}
+	if err := page().Where("user_id = ?", userID).
+		Preload("Labels", "deleted_at IS NULL").Find(&out.Tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := page().Where("user_id = ? OR id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID, userID).
+		Find(&out.Projects).Error; err != nil {
+		return nil, err
+	}
+	if err := page().Where("user_id = ?", userID).Find(&out.Labels).Error; err != nil {
+		return nil, err
+	}
+	if err := page().Where("task_id IN (SELECT id FROM tasks WHERE user_id = ?)", userID).
+		Find(&out.Subtasks).Error; err != nil {
+		return nil, err
+	}
+
+	var seqs []int64
+	for _, t := range out.Tasks {
+		seqs = append(seqs, t.SyncSeq)
+	}
+	for _, p := range out.Projects {
+		seqs = append(seqs, p.SyncSeq)
+	}
+	for _, l := range out.Labels {
+		seqs = append(seqs, l.SyncSeq)
+	}
+	for _, s := range out.Subtasks {
+		seqs = append(seqs, s.SyncSeq)
+	}
+	slices.Sort(seqs)
+	if len(seqs) > limit {
+		// Keep the first limit rows over all four kinds.
+		last := seqs[limit-1]
+		seqs = seqs[:limit]
+		out.Tasks = slices.DeleteFunc(out.Tasks, func(t model.Task) bool { return t.SyncSeq > last })
+		out.Projects = slices.DeleteFunc(out.Projects, func(p model.Project) bool { return p.SyncSeq > last })
+		out.Labels = slices.DeleteFunc(out.Labels, func(l model.Label) bool { return l.SyncSeq > last })
+		out.Subtasks = slices.DeleteFunc(out.Subtasks, func(s model.Subtask) bool { return s.SyncSeq > last })
+		out.HasMore = true
+	}
+
+	for _, seq := range seqs {
+		if seq > settled {
+			// The rest is re-sent next time; don't ask for more until it settles.
+			out.HasMore = false
+			break
+		}
+		out.Token = seq
+	}
+	return out, nil
+}
+
+// RecordSyncTick records where the sync and activity sequences stand, for
+// settledSeqs to tell once every transaction holding a seq up to there has
+// ended, and prunes the ticks a settled newer one supersedes. Writers take
+// their transaction ID before a seq, so reading the sequences before the
+// snapshot, in a statement of their own, puts every holder below its xmax.
+func RecordSyncTick(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	var tick syncTick
+	if err := db.Raw(`SELECT
+		(SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM sync_seq) AS sync_seq,
+		(SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM activities_seq_seq) AS activity_seq`).
+		Scan(&tick).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(`INSERT INTO sync_ticks (sync_seq, activity_seq, xmax)
+		VALUES (?, ?, pg_snapshot_xmax(pg_current_snapshot()))`, tick.SyncSeq, tick.ActivitySeq).Error; err != nil {
+		return err
+	}
+	return db.Exec(`DELETE FROM sync_ticks WHERE id < (SELECT MAX(id) FROM sync_ticks
+		WHERE xmax <= pg_snapshot_xmin(pg_current_snapshot()))`).Error
+}
+
+// syncTick is a recorded position of the sync and activity sequences.
+type syncTick struct {
+	SyncSeq     int64
+	ActivitySeq int64
+}
+
+// settledSeqs returns the sync and activity seqs up to which every
+// transaction has committed or rolled back: those of the newest tick whose
+// xmax the oldest running transaction is past, or 0 before there is one.
+// Seqs are assigned at write, not commit, so a token moving past a higher
+// seq before that could skip a lower one committed later.
+func settledSeqs(db *gorm.DB) (syncSeq, activitySeq int64, err error) {
+	var tick syncTick
+	err = db.Raw(`SELECT COALESCE(MAX(sync_seq), 0) AS sync_seq, COALESCE(MAX(activity_seq), 0) AS activity_seq
+		FROM sync_ticks WHERE xmax <= pg_snapshot_xmin(pg_current_snapshot())`).Scan(&tick).Error
+	return tick.SyncSeq, tick.ActivitySeq, err
+}
+
+// FindSubtask loads a subtask of a task owned by userID.
+func FindSubtask(db *gorm.DB, userID, id string) (*model.Subtask, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrSubtaskNotFound
+	}
+	var sub model.Subtask
+	if err := db.Where("id = ? AND task_id IN (SELECT id FROM tasks WHERE user_id = ?)", id, userID).
+		First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubtaskNotFound
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ClaimID checks a client-generated ID for a new row of model m. owner is
+// the condition matching the caller's rows, such as "user_id = ?". It
+// reports exists when the caller already created the row (a retried
+// mutation) and returns ErrIDConflict when someone else holds the ID.
+func ClaimID(tx *gorm.DB, m any, id string, owner string, args ...any) (exists bool, err error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return false, fmt.Errorf("%w: id must be a UUID", ErrInvalidMutation)
+	}
+	var total, mine int64
+	if err := tx.Unscoped().Model(m).Where("id = ?", id).Count(&total).Error; err != nil {
+		return false, err
+	}
+	if total == 0 {
+		return false, nil
+	}
+	if err := tx.Unscoped().Model(m).Where("id = ?", id).Where(owner, args...).Count(&mine).Error; err != nil {
+		return false, err
+	}
+	if mine == 0 {
+		return false, ErrIDConflict
+	}
+	return true, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+)
+
+var (
+	// ErrLabelNotFound is returned when a label ID does not belong to the task owner.
+	ErrLabelNotFound = errors.New("label not found")
+	// ErrTaskForbidden is returned when a task exists but the caller neither owns it nor can access its project.
+	ErrTaskForbidden = errors.New("no access to task")
+)
+
+// CheckTaskAccess loads a task visible to userID: one they own, or one in a
+// project they own or are a member of.
+func CheckTaskAccess(db *gorm.DB, userID, taskID string) (*model.Task, error) {
+	if _, err := uuid.Parse(taskID); err != nil {
+		return nil, ErrTaskNotFound
+	}
+	var task model.Task
+	if err := db.Where("id = ?", taskID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	if task.UserID == userID {
+		return &task, nil
+	}
+	if task.ProjectID == "" {
+		return nil, ErrTaskForbidden
+	}
+	if err := CheckProjectAccess(db, userID, task.ProjectID); err != nil {
+		if errors.Is(err, ErrProjectForbidden) || errors.Is(err, ErrProjectNotFound) {
+			return nil, ErrTaskForbidden
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ResolveLabels loads the labels with the given IDs, all of which must belong to userID.
+func ResolveLabels(db *gorm.DB, userID string, labelIDs []string) ([]model.Label, error) {
+	if len(labelIDs) == 0 {
+		return nil, nil
+	}
+	var labels []model.Label
+	if err := db.Where("id IN ? AND user_id = ?", labelIDs, userID).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(labelIDs))
+	for _, id := range labelIDs {
+		seen[id] = true
+	}
+	if len(labels) != len(seen) {
+		return nil, ErrLabelNotFound
+	}
+	return labels, nil
+}
+
+// TaskLabelIDs returns the IDs of the live labels attached to taskID, sorted.
+func TaskLabelIDs(db *gorm.DB, taskID string) ([]string, error) {
+	ids := []string{}
+	err := db.Table("task_labels").
+		Joins("JOIN labels ON labels.id = task_labels.label_id AND labels.deleted_at IS NULL").
+		Where("task_labels.task_id = ?", taskID).Order("task_labels.label_id").
+		Pluck("task_labels.label_id", &ids).Error
+	return ids, err
+}
+
+// SetTaskLabels replaces the labels attached to taskID with labels.
+func SetTaskLabels(tx *gorm.DB, taskID string, labels []model.Label) error {
+	if len(labels) == 0 {
+		return tx.Exec(`DELETE FROM task_labels WHERE task_id = ?`, taskID).Error
+	}
+	ids := make([]string, len(labels))
+	rows := make([]map[string]any, len(labels))
+	for i, l := range labels {
+		ids[i] = l.ID
+		rows[i] = map[string]any{"task_id": taskID, "label_id": l.ID}
+	}
+	if err := tx.Exec(`DELETE FROM task_labels WHERE task_id = ? AND label_id NOT IN ?`, taskID, ids).Error; err != nil {
+		return err
+	}
+	return tx.Table("task_labels").Clauses(clause.OnConflict{DoNothing: true}).Create(rows).Error
+}
+
+// MoveTask moves task (owned by userID) into projectID after checking access.
+// The task leaves its section and goes to the end of the target project; a
+// custom status the target project lacks falls back to its category.
+// An in_progress-category task must fit the target column's WIP limit.
+// Subtasks follow their parent via task_id and are touched so that clients
+// see them as changed. Labels stay: they belong to the task owner, who a move
+// does not change. An empty projectID moves the task to the user's Inbox.
+func MoveTask(db *gorm.DB, task *model.Task, userID, projectID string) error {
+	projectID, err := ResolveTaskProject(db, userID, projectID)
+	if err != nil {
+		return err
+	}
+	key, err := TaskSortKey(db, task.ID, projectID, nil, "", "")
+	if err != nil {
+		return err
+	}
+	status, err := statusInProject(db, task, projectID)
+	if err != nil {
+		return err
+	}
+	target, err := LoadStatusSet(db, projectID)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if projectID != task.ProjectID && target[status] == model.TaskStatusInProgress {
+			moved := *task
+			moved.ProjectID = projectID
+			if err := checkWIPLimit(tx, &moved, status); err != nil {
+				return err
+			}
+		}
+		task.ProjectID, task.SectionID, task.SortKey, task.Status = projectID, nil, key, status
+		if err := tx.Model(task).Select("project_id", "section_id", "sort_key", "status").Updates(task).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Subtask{}).Where("task_id = ?", task.ID).
+			Update("updated_at", gorm.Expr("NOW()")).Error
+	})
+}
+
+// statusInProject maps the task's status onto projectID's statuses: kept when
+// the target knows it, otherwise replaced by its built-in category.
+func statusInProject(db *gorm.DB, task *model.Task, projectID string) (string, error) {
+	if isTaskStatus(task.Status) {
+		return task.Status, nil
+	}
+	target, err := LoadStatusSet(db, projectID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := target[task.Status]; ok {
+		return task.Status, nil
+	}
+	current, err := LoadStatusSet(db, task.ProjectID)
+	if err != nil {
+		return "", err
+	}
+	if cat, ok := current[task.Status]; ok {
+		return cat, nil
+	}
+	return model.TaskStatusPending, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionMismatch is returned when a write is conditioned on a version
+// the row no longer has.
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// CheckVersion guards a read-modify-write of m, a task, project, label or
+// subtask loaded by ID, against concurrent writers. It locks the row for the
+// rest of tx, reloads m from it and returns ErrVersionMismatch unless its
+// version is one of expected. A nil expected skips the check.
+func CheckVersion(tx *gorm.DB, m any, expected []int) error {
+	if expected == nil {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(m))
+	id := v.FieldByName("ID").String()
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Deleted since it was loaded.
+		return ErrVersionMismatch
+	}
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(expected, int(v.FieldByName("Version").Int())) {
+		return ErrVersionMismatch
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/todo-tracking-app/web-be/internal/model"
+	"github.com/todo-tracking-app/web-be/internal/webhook"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a webhook does not exist or belongs to someone else.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a delivery does not exist on the webhook.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidWebhook is returned for a bad URL or unknown event type.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrWebhookDisabled is returned when replaying a delivery of a disabled webhook.
+	ErrWebhookDisabled = errors.New("webhook is disabled")
+)
+
+// Webhook retry policy: a failed delivery is retried after 30s, 1m, 2m, ...
+// (at most webhookMaxBackoff apart) until webhookMaxAttempts is reached.
+const (
+	webhookMaxAttempts = 10
+	webhookRetryBase   = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	// webhookClaimLease is how far a claimed delivery's next attempt is pushed
+	// back while it is being sent, well past the client's timeout. A
+	// dispatcher that stops before recording the outcome leaves it to be
+	// retried after that.
+	webhookClaimLease = 2 * time.Minute
+)
+
+// webhookEntityTypes are the activity entity types that produce events.
+var webhookEntityTypes = []string{
+	"task", "project", "section", "project_status", "label",
+	"subtask", "comment", "attachment", "task_dependency", "task_assignment",
+}
+
+// webhookActions maps activity actions to event type suffixes.
+var webhookActions = map[string]string{
+	model.ActivityCreate: "created",
+	model.ActivityUpdate: "updated",
+	model.ActivityDelete: "deleted",
+}
+
+// WebhookEventTypes lists every event type a webhook can subscribe to, such
+// as task.created or comment.deleted.
+func WebhookEventTypes() []string {
+	var out []string
+	for _, entity := range webhookEntityTypes {
+		for _, action := range []string{model.ActivityCreate, model.ActivityUpdate, model.ActivityDelete} {
+			out = append(out, entity+"."+webhookActions[action])
+		}
+	}
+	return out
+}
+
+// WebhookEvents returns the event types w subscribes to; empty means all.
+func WebhookEvents(w *model.Webhook) []string {
+	var events []string
+	_ = json.Unmarshal([]byte(w.Events), &events)
+	return events
+}
+
+// encodeWebhookEvents validates events and encodes them for storage.
+func encodeWebhookEvents(events []string) (string, error) {
+	known := WebhookEventTypes()
+	out := []string{}
+	for _, e := range events {
+		if !slices.Contains(known, e) {
+			return "", fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, e)
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	raw, err := json.Marshal(out)
+	return string(raw), err
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// CreateWebhook subscribes url to events for userID, or for a project when
+// projectID is set, which requires owning the project. An empty secret is
+// generated.
+func CreateWebhook(db *gorm.DB, userID string, projectID *string, url string, events []string, secret string) (*model.Webhook, error) {
+	if !webhook.ValidURL(url) {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	encoded, err := encodeWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	if projectID != nil {
+		if err := CheckProjectAccess(db, userID, *projectID); err != nil {
+			return nil, err
+		}
+		var proj model.Project
+		if err := db.Select("id", "user_id").Where("id = ?", *projectID).First(&proj).Error; err != nil {
+			return nil, err
+		}
+		if proj.UserID != userID {
+			return nil, ErrProjectForbidden
+		}
+	}
+	if secret == "" {
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	w := &model.Webhook{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		ProjectID: projectID,
+		URL:       url,
+		Events:    encoded,
+		Secret:    secret,
+	}
+	if err := db.Create(w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WebhookUpdate holds the fields of a webhook to change; nil leaves a field as is.
+type WebhookUpdate struct {
+	URL     *string
+	Events  *[]string
+	Secret  *string
+	Enabled *bool
+}
+
+// UpdateWebhook applies u to w. Re-enabling a disabled webhook clears its
+// failure count; deliveries queued while it was disabled resume.
+func UpdateWebhook(db *gorm.DB, w *model.Webhook, u WebhookUpdate) error {
+	if u.URL != nil {
+		if !webhook.ValidURL(*u.URL) {
+			return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+		}
+		w.URL = *u.URL
+	}
+	if u.Events != nil {
+		encoded, err := encodeWebhookEvents(*u.Events)
+		if err != nil {
+			return err
+		}
+		w.Events = encoded
+	}
+	if u.Secret != nil {
+		secret := *u.Secret
+		if secret == "" {
+			var err error
+			if secret, err = newWebhookSecret(); err != nil {
+				return err
+			}
+		}
+		w.Secret = secret
+	}
+	if u.Enabled != nil {
+		switch {
+		case *u.Enabled && w.DisabledAt != nil:
+			w.DisabledAt = nil
+			w.DisabledReason = ""
+			w.ConsecutiveFailures = 0
+		case !*u.Enabled && w.DisabledAt == nil:
+			now := time.Now()
+			w.DisabledAt = &now
+			w.DisabledReason = "disabled by owner"
+		}
+	}
+	return db.Save(w).Error
+}
+
+// FindWebhook loads a webhook owned by userID.
+func FindWebhook(db *gorm.DB, userID, id string) (*model.Webhook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	var w model.Webhook
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&w).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// FindWebhookDelivery loads a delivery of webhookID.
+func FindWebhookDelivery(db *gorm.DB, webhookID, id string) (*model.WebhookDelivery, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	var d model.WebhookDelivery
+	if err := db.Where("id = ? AND webhook_id = ?", id, webhookID).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ReplayWebhookDelivery queues the event of d for delivery again.
+func ReplayWebhookDelivery(db *gorm.DB, w *model.Webhook, d *model.WebhookDelivery) (*model.WebhookDelivery, error) {
+	if w.DisabledAt != nil {
+		return nil, ErrWebhookDisabled
+	}
+	replay := &model.WebhookDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		ReplayOf:      &d.ID,
+	}
+	if err := db.Create(replay).Error; err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// enqueueWebhooks queues a delivery of a to every enabled webhook that
+// subscribes to its event: the project's webhooks, user-level webhooks of the
+// project's owner and members, and user-level webhooks of the actor. It runs
+// in the transaction recording a, so events are queued only if the change commits.
+func enqueueWebhooks(tx *gorm.DB, a *model.Activity) error {
+	event := a.EntityType + "." + webhookActions[a.Action]
+	scope := tx.Session(&gorm.Session{NewDB: true}).Where("project_id IS NULL AND user_id = ?", a.ActorID)
+	if a.ProjectID != nil {
+		scope = scope.Or("project_id = ?", *a.ProjectID).
+			Or("project_id IS NULL AND (user_id IN (SELECT user_id FROM projects WHERE id = ?) "+
+				"OR user_id IN (SELECT user_id FROM project_members WHERE project_id = ?))", *a.ProjectID, *a.ProjectID)
+	}
+	var hooks []model.Webhook
+	if err := tx.Where("disabled_at IS NULL").Where(scope).Find(&hooks).Error; err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(map[string]any{
+		"id":          a.ID,
+		"type":        event,
+		"created_at":  a.CreatedAt.UTC(),
+		"actor_id":    a.ActorID,
+		"entity_type": a.EntityType,
+		"entity_id":   a.EntityID,
+		"task_id":     a.TaskID,
+		"project_id":  a.ProjectID,
+		"changes":     json.RawMessage(a.Changes),
+	})
+	if err != nil {
+		return err
+	}
+	var deliveries []model.WebhookDelivery
+	for _, w := range hooks {
+		if events := WebhookEvents(&w); len(events) > 0 && !slices.Contains(events, event) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			ID:            uuid.New().String(),
+			WebhookID:     w.ID,
+			EventID:       a.ID,
+			EventType:     event,
+			Payload:       string(payload),
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: a.CreatedAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// WebhookDispatcher delivers queued webhook events.
+type WebhookDispatcher struct {
+	client       *webhook.Client
+	disableAfter int
+}
+
+// NewWebhookDispatcher returns a dispatcher sending through client that
+// disables a webhook after disableAfter consecutive failed attempts.
+func NewWebhookDispatcher(client *webhook.Client, disableAfter int) *WebhookDispatcher {
+	return &WebhookDispatcher{client: client, disableAfter: disableAfter}
+}
+
+// DispatchDue attempts up to limit due deliveries and returns how many it
+// handled. Each is claimed with FOR UPDATE SKIP LOCKED in a short
+// transaction that pushes its next attempt back by webhookClaimLease, so
+// several instances can dispatch concurrently; it is then sent with no
+// transaction open and the outcome recorded in another.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context, db *gorm.DB, limit int) (int, error) {
+	handled := 0
+	for handled < limit {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+		del, w, err := claimWebhookDelivery(db.WithContext(ctx))
+		if err != nil {
+			return handled, err
+		}
+		if del == nil {
+			break
+		}
+		if w != nil {
+			if err := d.attempt(ctx, db, del, w); err != nil {
+				return handled, err
+			}
+		}
+		handled++
+	}
+	return handled, nil
+}
+
+// claimWebhookDelivery claims the next due delivery, counting the attempt,
+// and returns it with its webhook. A delivery whose last attempt was claimed
+// but never recorded is marked failed instead, and returned without webhook.
+func claimWebhookDelivery(db *gorm.DB) (*model.WebhookDelivery, *model.Webhook, error) {
+	var del model.WebhookDelivery
+	var w model.Webhook
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, time.Now()).
+			Where("webhook_id IN (SELECT id FROM webhooks WHERE disabled_at IS NULL)").
+			Order("next_attempt_at").Limit(1).Find(&del).Error
+		if err != nil || del.ID == "" {
+			return err
+		}
+		if del.Attempts >= webhookMaxAttempts {
+			del.Status = model.WebhookDeliveryFailed
+			del.Error = "the outcome of the last attempt was not recorded"
+			return tx.Model(&del).Select("status", "error").Updates(&del).Error
+		}
+		if err := tx.Where("id = ?", del.WebhookID).First(&w).Error; err != nil {
+			return err
+		}
+		del.Attempts++
+		del.NextAttemptAt = time.Now().Add(webhookClaimLease)
+		return tx.Model(&del).Select("attempts", "next_attempt_at").Updates(&del).Error
+	})
+	switch {
+	case err != nil || del.ID == "":
+		return nil, nil, err
+	case w.ID == "":
+		return &del, nil, nil
+	}
+	return &del, &w, nil
+}
+
+// attempt sends del, claimed, once to w and records the outcome on both.
+func (d *WebhookDispatcher) attempt(ctx context.Context, db *gorm.DB, del *model.WebhookDelivery, w *model.Webhook) error {
+	resp, sendErr := d.client.Send(ctx, webhook.Request{
+		URL:        w.URL,
+		Secret:     w.Secret,
+		WebhookID:  w.ID,
+		DeliveryID: del.ID,
+		Event:      del.EventType,
+		Body:       []byte(del.Payload),
+	})
+	now := time.Now()
+	del.ResponseStatus = resp.Status
+	del.ResponseBody = resp.Body
+	if sendErr == nil {
+		del.Status = model.WebhookDeliverySucceeded
+		del.DeliveredAt = &now
+		del.Error = ""
+	} else {
+		del.Error = sendErr.Error()
+		if del.Attempts >= webhookMaxAttempts {
+			del.Status = model.WebhookDeliveryFailed
+		} else {
+			del.NextAttemptAt = now.Add(min(webhookRetryBase<<(del.Attempts-1), webhookMaxBackoff))
+		}
+	}
+
+	// Recorded even when ctx was cancelled meanwhile: the request went out.
+	return db.WithContext(context.WithoutCancel(ctx)).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(del).Select("status", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+			Updates(del).Error; err != nil {
+			return err
+		}
+		// The failure count is read under lock, as other dispatchers record
+		// outcomes of the same webhook.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", w.ID).First(w).Error; err != nil {
+			return err
+		}
+		if sendErr == nil {
+			w.ConsecutiveFailures = 0
+		} else {
+			w.ConsecutiveFailures++
+			if d.disableAfter > 0 && w.ConsecutiveFailures >= d.disableAfter && w.DisabledAt == nil {
+				w.DisabledAt = &now
+				w.DisabledReason = fmt.Sprintf("disabled after %d consecutive failed deliveries; last error: %s",
+					w.ConsecutiveFailures, sendErr)
+				log.Printf("webhooks: %s %s", w.ID, w.DisabledReason)
+			}
+		}
+		return tx.Model(w).Select("consecutive_failures", "disabled_at", "disabled_reason").Updates(w).Error
+	})
+}
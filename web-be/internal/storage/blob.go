@@ -0,0 +1,21 @@
+// Package storage provides blob stores for user-uploaded files.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned by Get when no blob exists under the key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque blobs under slash-separated keys.
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	stubAccessKey = "AKIDEXAMPLE"
+	stubSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	stubRegion    = "eu-west-1"
+	stubBucket    = "attachments"
+)
+
+// s3Stub is a minimal S3-compatible server, MinIO-style with path-style
+// buckets, that checks every request's SigV4 signature.
+type s3Stub struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	paths   []string
+}
+
+func newS3Stub(t *testing.T) (*s3Stub, *httptest.Server) {
+	stub := &s3Stub{objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if msg := s.checkSignature(r); msg != "" {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>"+msg+"</Message></Error>", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+stubBucket+"/")
+	if !ok {
+		http.Error(w, "<Error><Code>NoSuchBucket</Code></Error>", http.StatusNotFound)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths = append(s.paths, r.URL.EscapedPath())
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(body)) != r.ContentLength {
+			http.Error(w, "<Error><Code>IncompleteBody</Code></Error>", http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = body
+		s.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", s.types[key])
+		w.Write(body)
+	case http.MethodDelete:
+		if _, ok := s.objects[key]; !ok {
+			// S3 answers 204 for a missing key; MinIO may answer 404.
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// checkSignature recomputes the request's SigV4 signature with the stub's
+// credentials and returns what is wrong with it, or "".
+func (s *s3Stub) checkSignature(r *http.Request) string {
+	amzDate := r.Header.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil {
+		return "bad X-Amz-Date"
+	}
+	payload := r.Header.Get("X-Amz-Content-Sha256")
+	scope := amzDate[:8] + "/" + stubRegion + "/s3/aws4_request"
+	canonical := r.Method + "\n" +
+		r.URL.EscapedPath() + "\n" +
+		r.URL.Query().Encode() + "\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + payload + "\n" +
+		"x-amz-date:" + amzDate + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" +
+		payload
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+	key := []byte("AWS4" + stubSecretKey)
+	for _, part := range []string{amzDate[:8], stubRegion, "s3", "aws4_request", toSign} {
+		key = hmacSHA256(key, part)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=" + stubAccessKey + "/" + scope +
+		", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=" + hex.EncodeToString(key)
+	if got := r.Header.Get("Authorization"); got != want {
+		return "signature mismatch"
+	}
+	return ""
+}
+
+func newStubStore(t *testing.T, endpoint, secret string) *S3Store {
+	s, err := NewS3Store(S3Config{
+		Endpoint:        endpoint,
+		Region:          stubRegion,
+		Bucket:          stubBucket,
+		AccessKeyID:     stubAccessKey,
+		SecretAccessKey: secret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestS3StorePutGetDelete(t *testing.T) {
+	stub, srv := newS3Stub(t)
+	s := newStubStore(t, srv.URL, stubSecretKey)
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"tasks/1/report.pdf",
+		"tasks/2/screen shot (1).png",
+		"tasks/3/報告+final&v=2.txt",
+	} {
+		data := []byte("contents of " + key)
+		if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/pdf"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		rc, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", key, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("Get(%q) = %q, %v; want %q", key, got, err, data)
+		}
+		if ct := stub.types[key]; ct != "application/pdf" {
+			t.Errorf("Put(%q) stored content type %q", key, ct)
+		}
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%q): %v", key, err)
+		}
+		if _, err := s.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+			t.Fatalf("Get(%q) after Delete: %v, want ErrBlobNotFound", key, err)
+		}
+	}
+	// Keys keep their slashes and have everything else percent-encoded.
+	if want := "/attachments/tasks/2/screen%20shot%20%281%29.png"; !slices.Contains(stub.paths, want) {
+		t.Errorf("request paths = %q, want one of %s", stub.paths, want)
+	}
+}
+
+func TestS3StoreEmptyBlob(t *testing.T) {
+	stub, srv := newS3Stub(t)
+	s := newStubStore(t, srv.URL, stubSecretKey)
+	if err := s.Put(context.Background(), "empty", strings.NewReader(""), 0, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if data, ok := stub.objects["empty"]; !ok || len(data) != 0 {
+		t.Fatalf("stored %q, %v", data, ok)
+	}
+}
+
+func TestS3StoreMissing(t *testing.T) {
+	_, srv := newS3Stub(t)
+	s := newStubStore(t, srv.URL, stubSecretKey)
+	ctx := context.Background()
+	if _, err := s.Get(ctx, "nope"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get missing: %v, want ErrBlobNotFound", err)
+	}
+	if err := s.Delete(ctx, "nope"); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+}
+
+func TestS3StoreBadSignature(t *testing.T) {
+	_, srv := newS3Stub(t)
+	s := newStubStore(t, srv.URL, "not-the-secret")
+	err := s.Put(context.Background(), "k", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("Put with a wrong secret: %v, want a 403 SignatureDoesNotMatch error", err)
+	}
+}
+
+func TestS3StoreSignsWithClock(t *testing.T) {
+	var date string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date = r.Header.Get("X-Amz-Date")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	s := newStubStore(t, srv.URL, stubSecretKey)
+	s.now = func() time.Time { return time.Date(2026, 1, 14, 10, 30, 0, 0, time.FixedZone("CST", 8*3600)) }
+	if err := s.Delete(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+	if date != "20260114T023000Z" {
+		t.Errorf("X-Amz-Date = %s, want 20260114T023000Z", date)
+	}
+}
+
+func TestS3StoreRejectsBadKeys(t *testing.T) {
+	s := newStubStore(t, "http://localhost:9000", stubSecretKey)
+	for _, key := range []string{"", "/abs"} {
+		if _, err := s.Get(context.Background(), key); err == nil {
+			t.Errorf("Get(%q) accepted", key)
+		}
+	}
+}
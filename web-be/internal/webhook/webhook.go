@@ -0,0 +1,134 @@
+// Package webhook signs and sends outbound webhook requests.
+//
+// Each request carries the event in JSON and these headers:
+//
+//	X-Webhook-Id         subscription ID
+//	X-Webhook-Delivery   delivery ID, stable across retries
+//	X-Webhook-Event      event type, e.g. task.updated
+//	X-Webhook-Timestamp  Unix seconds when the request was signed
+//	X-Webhook-Signature  sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// Receivers should recompute the signature with their secret and reject
+// requests whose timestamp is more than a few minutes old.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// maxResponseBody is how much of a receiver's response is kept for the delivery log.
+const maxResponseBody = 2048
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to a loopback,
+// private or link-local address and private networks are not allowed.
+var ErrForbiddenAddress = errors.New("webhook: destination address not allowed")
+
+// Request is one delivery attempt.
+type Request struct {
+	URL        string
+	Secret     string
+	WebhookID  string
+	DeliveryID string
+	Event      string
+	Body       []byte
+}
+
+// Response is the receiver's answer. Status is 0 when no response arrived.
+type Response struct {
+	Status int
+	Body   string
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Client sends webhook requests.
+type Client struct {
+	http *http.Client
+}
+
+// NewClient returns a Client. Unless allowPrivate is set, connections to
+// loopback, private and link-local addresses are refused, so subscriptions
+// cannot be used to probe the internal network.
+func NewClient(allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Client{http: &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		// Redirects are not followed; a 3xx counts as a failed delivery.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast())
+}
+
+// Send signs and POSTs req. It returns an error for transport failures and
+// non-2xx responses; the response, if any, is returned either way.
+func (c *Client) Send(ctx context.Context, req Request) (Response, error) {
+	ts := time.Now().Unix()
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Response{}, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("User-Agent", "todo-tracking-webhooks/1")
+	r.Header.Set("X-Webhook-Id", req.WebhookID)
+	r.Header.Set("X-Webhook-Delivery", req.DeliveryID)
+	r.Header.Set("X-Webhook-Event", req.Event)
+	r.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(ts, 10))
+	r.Header.Set("X-Webhook-Signature", Sign(req.Secret, ts, req.Body))
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	out := Response{Status: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode/100 != 2 {
+		return out, fmt.Errorf("webhook: receiver answered %s", resp.Status)
+	}
+	return out, nil
+}
+
+// ValidURL reports whether raw is an absolute http or https URL.
+func ValidURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" && u.User == nil
+}